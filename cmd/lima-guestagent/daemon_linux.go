@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/lima-vm/lima/v2/pkg/guestagent"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/api/server"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/gui"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/serialport"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/ticker"
 	"github.com/lima-vm/lima/v2/pkg/portfwdserver"
@@ -32,9 +34,29 @@ func newDaemonCommand() *cobra.Command {
 	daemonCommand.Flags().Duration("tick", 3*time.Second, "Tick for polling events")
 	daemonCommand.Flags().Int("vsock-port", 0, "Use vsock server instead a UNIX socket")
 	daemonCommand.Flags().String("virtio-port", "", "Use virtio server instead a UNIX socket")
+	daemonCommand.Flags().Duration("spice-agent-retry-interval", gui.DefaultSpiceAgentRetry.Interval,
+		"Minimum delay before retrying to enable the SPICE agent after a failure")
+	daemonCommand.Flags().Int("spice-agent-max-failures", gui.DefaultSpiceAgentRetry.MaxFailures,
+		"Stop trying to enable the SPICE agent after this many consecutive failures (0 for no limit)")
+	daemonCommand.Flags().Bool("spice-agent-auto-enable", spiceAgentAutoEnableDefault(),
+		"Install and start the SPICE agent when the clipboard is not ready (default from $"+gui.SpiceAgentAutoEnableEnv+")")
 	return daemonCommand
 }
 
+// spiceAgentAutoEnableDefault returns the value of $LIMA_AUTO_ENABLE_SPICE, true when it is unset
+func spiceAgentAutoEnableDefault() bool {
+	v := os.Getenv(gui.SpiceAgentAutoEnableEnv)
+	if v == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		logrus.Warnf("Ignoring invalid $%s value %q, expected a boolean", gui.SpiceAgentAutoEnableEnv, v)
+		return true
+	}
+	return enabled
+}
+
 func daemonAction(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 	runtimeDir, err := cmd.Flags().GetString("runtime-dir")
@@ -57,6 +79,18 @@ func daemonAction(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	spiceAgentRetryInterval, err := cmd.Flags().GetDuration("spice-agent-retry-interval")
+	if err != nil {
+		return err
+	}
+	spiceAgentMaxFailures, err := cmd.Flags().GetInt("spice-agent-max-failures")
+	if err != nil {
+		return err
+	}
+	spiceAgentAutoEnable, err := cmd.Flags().GetBool("spice-agent-auto-enable")
+	if err != nil {
+		return err
+	}
 	if tick == 0 {
 		return errors.New("tick must be specified")
 	}
@@ -64,6 +98,12 @@ func daemonAction(cmd *cobra.Command, _ []string) error {
 		return errors.New("must run as the root user")
 	}
 
+	gui.SetSpiceAgentRetry(gui.SpiceAgentRetry{Interval: spiceAgentRetryInterval, MaxFailures: spiceAgentMaxFailures})
+	gui.SetSpiceAgentAutoEnable(spiceAgentAutoEnable)
+	if !spiceAgentAutoEnable {
+		logrus.Info("SPICE agent auto-enable is disabled")
+	}
+
 	logrus.Infof("event tick: %v", tick)
 	simpleTicker := ticker.NewSimpleTicker(time.NewTicker(tick))
 	tickerInst := simpleTicker
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/httpclientutil"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newClipboardCommand() *cobra.Command {
+	clipboardCmd := &cobra.Command{
+		Use:   "clipboard",
+		Short: "Access the clipboard of the guest GUI session",
+		Long: `Access the clipboard of the guest GUI session through the guest agent.
+
+This works without SPICE, e.g., for VNC displays, and requires xclip (X11) or wl-clipboard (Wayland) in the guest.
+Only text is supported.`,
+		GroupID: advancedCommand,
+	}
+	clipboardCmd.AddCommand(newClipboardCopyCommand())
+	clipboardCmd.AddCommand(newClipboardSetCommand())
+	clipboardCmd.AddCommand(newClipboardPasteCommand())
+	clipboardCmd.AddCommand(newClipboardEnableCommand())
+
+	return clipboardCmd
+}
+
+func newClipboardCopyCommand() *cobra.Command {
+	copyCmd := &cobra.Command{
+		Use:   "copy INSTANCE",
+		Short: "Copy the standard input to the guest clipboard",
+		Example: `  $ echo hello | limactl clipboard copy default
+  $ pbpaste | limactl clipboard copy default`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              clipboardCopyAction,
+		ValidArgsFunction: clipboardBashComplete,
+	}
+	return copyCmd
+}
+
+func clipboardCopyAction(cmd *cobra.Command, args []string) error {
+	haClient, err := clipboardHostAgentClient(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+	if err := haClient.SetClipboard(ctx, data); err != nil {
+		return fmt.Errorf("failed to set the guest clipboard: %w", err)
+	}
+	return nil
+}
+
+func newClipboardSetCommand() *cobra.Command {
+	setCmd := &cobra.Command{
+		Use:   "set INSTANCE TEXT",
+		Short: "Set the guest clipboard to the given text",
+		Long: `Set the guest clipboard to the given text, e.g., for scripted pastes into the guest.
+
+With a SPICE display, spice-vdagent shares the new content of the guest clipboard with the host too.`,
+		Example:           `  $ limactl clipboard set default "hello world"`,
+		Args:              WrapArgsError(cobra.ExactArgs(2)),
+		RunE:              clipboardSetAction,
+		ValidArgsFunction: clipboardSetBashComplete,
+	}
+	return setCmd
+}
+
+func clipboardSetAction(cmd *cobra.Command, args []string) error {
+	haClient, err := clipboardHostAgentClient(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+	if err := haClient.SetClipboard(ctx, []byte(args[1])); err != nil {
+		return fmt.Errorf("failed to set the guest clipboard: %w", err)
+	}
+	return nil
+}
+
+func newClipboardPasteCommand() *cobra.Command {
+	pasteCmd := &cobra.Command{
+		Use:   "paste INSTANCE",
+		Short: "Write the guest clipboard to the standard output",
+		Example: `  $ limactl clipboard paste default
+  $ limactl clipboard paste default | pbcopy`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              clipboardPasteAction,
+		ValidArgsFunction: clipboardBashComplete,
+	}
+	return pasteCmd
+}
+
+func clipboardPasteAction(cmd *cobra.Command, args []string) error {
+	haClient, err := clipboardHostAgentClient(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+	data, err := haClient.Clipboard(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the guest clipboard: %w", err)
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+func newClipboardEnableCommand() *cobra.Command {
+	enableCmd := &cobra.Command{
+		Use:   "enable INSTANCE",
+		Short: "Share the clipboard of a running instance with the host",
+		Long: `Share the clipboard of a running instance with the host, without restarting it when the driver allows.
+
+The VZ driver attaches the SPICE agent port when the VM is created, so the clipboard can only be shared
+if it was enabled at start time; otherwise the instance has to be restarted.`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              clipboardEnableAction,
+		ValidArgsFunction: clipboardBashComplete,
+	}
+	return enableCmd
+}
+
+func clipboardEnableAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	instName := args[0]
+	inst, err := store.Inspect(ctx, instName)
+	if err != nil {
+		return err
+	}
+	haClient, err := clipboardHostAgentClient(ctx, instName)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err = haClient.EnableClipboard(ctx)
+	var statusErr *httpclientutil.HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+		if inst.Config != nil && inst.Config.Video.Clipboard != nil && !*inst.Config.Video.Clipboard {
+			return fmt.Errorf("%w; clipboard sharing is disabled in the configuration, stop the instance, "+
+				"run `limactl edit --set '.video.clipboard = true' %s`, and start it again", err, instName)
+		}
+		return fmt.Errorf("%w; run `limactl restart %s` to share the clipboard", err, instName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enable clipboard sharing: %w", err)
+	}
+	logrus.Infof("Clipboard sharing is enabled for instance %q", instName)
+	return nil
+}
+
+// clipboardHostAgentClient returns a client for the host agent of a running instance
+func clipboardHostAgentClient(ctx context.Context, instName string) (hostagentclient.HostAgentClient, error) {
+	inst, err := store.Inspect(ctx, instName)
+	if err != nil {
+		return nil, err
+	}
+	if inst.Status != limatype.StatusRunning {
+		return nil, fmt.Errorf("instance %q is not running (status: %s)", instName, inst.Status)
+	}
+	return hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+}
+
+func clipboardBashComplete(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return bashCompleteInstanceNames(cmd)
+}
+
+func clipboardSetBashComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		// The text is free-form
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return clipboardBashComplete(cmd, args, toComplete)
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newCloseGUICommand() *cobra.Command {
+	closeGUICmd := &cobra.Command{
+		Use:   "close-gui INSTANCE",
+		Short: "Close the SPICE viewers opened for the instance.",
+		Long: `Close the SPICE viewers opened for the instance by "limactl show-gui",
+including every viewer opened with --per-monitor. The VM keeps running.
+
+The VZ display window cannot be closed this way, as closing it stops the VM.`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              closeGUIAction,
+		ValidArgsFunction: showGUIBashComplete,
+		SilenceErrors:     true,
+		GroupID:           advancedCommand,
+	}
+	return closeGUICmd
+}
+
+func closeGUIAction(cmd *cobra.Command, args []string) error {
+	instName := args[0]
+	inst, err := store.Inspect(cmd.Context(), instName)
+	if err != nil {
+		return err
+	}
+	stopped, err := spiceclient.StopViewers(filepath.Join(inst.Dir, filenames.SPICEViewerPID))
+	if stopped == 0 && err == nil {
+		logrus.Infof("No SPICE viewer is open for instance %q", instName)
+		return nil
+	}
+	logrus.Infof("Closed %d SPICE viewer(s) for instance %q", stopped, instName)
+	return err
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lima-vm/lima/v2/pkg/driver"
+	"github.com/lima-vm/lima/v2/pkg/driverutil"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newDriverInfoCommand() *cobra.Command {
+	driverInfoCmd := &cobra.Command{
+		Use:   "driver-info INSTANCE",
+		Short: "Show the features of the driver of an instance.",
+		Long: `Show the features of the driver of an instance, such as whether it can run a GUI or share the clipboard,
+along with the host monitors the display window can be placed on.
+
+The driver is created from the configuration of the instance, so that the instance does not need to be running.`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              driverInfoAction,
+		ValidArgsFunction: driverInfoBashComplete,
+		GroupID:           advancedCommand,
+	}
+	driverInfoCmd.Flags().Bool("json", false, "JSONify output")
+	return driverInfoCmd
+}
+
+// driverInfo is the output of driver-info
+type driverInfo struct {
+	Instance     string               `json:"instance"`
+	VMType       string               `json:"vmType"`
+	Info         driver.Info          `json:"info"`
+	HostMonitors []driver.MonitorInfo `json:"hostMonitors"`
+}
+
+func driverInfoAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	jsonFormat, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	configuredDriver, err := driverutil.CreateConfiguredDriver(inst, inst.SSHLocalPort)
+	if err != nil {
+		return fmt.Errorf("failed to create driver for instance %q: %w", inst.Name, err)
+	}
+	info := driverInfo{
+		Instance:     inst.Name,
+		VMType:       string(inst.VMType),
+		Info:         configuredDriver.Info(),
+		HostMonitors: configuredDriver.HostMonitors(),
+	}
+
+	if jsonFormat {
+		j, err := json.MarshalIndent(info, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(j))
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+	fmt.Fprintf(w, "Instance:\t%s\n", info.Instance)
+	fmt.Fprintf(w, "Driver:\t%s\n", orDash(info.Info.Name))
+	fmt.Fprintf(w, "VM type:\t%s\n", info.VMType)
+	fmt.Fprintf(w, "Host monitors:\t%d\n", len(info.HostMonitors))
+	fmt.Fprintln(w, "Features:")
+	// Every feature is listed, including the false ones that the JSON output omits
+	features := reflect.ValueOf(info.Info.Features)
+	for i := range features.NumField() {
+		name, _, _ := strings.Cut(features.Type().Field(i).Tag.Get("json"), ",")
+		fmt.Fprintf(w, "  %s:\t%v\n", name, features.Field(i).Interface())
+	}
+	return w.Flush()
+}
+
+func driverInfoBashComplete(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return bashCompleteInstanceNames(cmd)
+}
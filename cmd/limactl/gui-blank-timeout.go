@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newGUIBlankTimeoutCommand() *cobra.Command {
+	guiBlankTimeoutCmd := &cobra.Command{
+		Use:   "gui-blank-timeout INSTANCE [SECONDS]",
+		Short: "Show or set the idle time after which the guest blanks the screen.",
+		Long: `Show or set the idle time after which the guest GUI session blanks the screen, in seconds.
+A timeout of 0 never blanks the screen, e.g., for kiosks.
+
+The timeout is the idle-delay setting of GNOME, on X11 and Wayland, or the screensaver and DPMS timeouts
+of the X server (xset) in other X11 sessions. Other Wayland sessions are not supported.
+The X server forgets the xset timeouts when it exits, e.g., on logout.`,
+		Example: `  Show the timeout:
+  $ limactl gui-blank-timeout default
+
+  Never blank the screen:
+  $ limactl gui-blank-timeout default 0`,
+		Args:              WrapArgsError(cobra.RangeArgs(1, 2)),
+		RunE:              guiBlankTimeoutAction,
+		ValidArgsFunction: guiBlankTimeoutBashComplete,
+		GroupID:           advancedCommand,
+	}
+	return guiBlankTimeoutCmd
+}
+
+func guiBlankTimeoutAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := checkGUIStatusInstance(inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst.Name, err)
+	}
+
+	if len(args) == 1 {
+		guiInfo, err := guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to get GUI information from the guest: %w", err)
+		}
+		timeout, ok := guiInfo.ScreenBlankTimeout()
+		if !ok {
+			return fmt.Errorf("the guest agent of instance %q cannot read the blank timeout of the %s session",
+				inst.Name, orDash(guiInfo.DisplayServer))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), int64(timeout.Seconds()))
+		return nil
+	}
+
+	seconds, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil || seconds < 0 {
+		return fmt.Errorf("invalid timeout %q, expected a number of seconds, 0 to never blank the screen", args[1])
+	}
+	haClient, err := hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := haClient.SetBlankTimeout(ctx, int32(seconds)); err != nil {
+		return fmt.Errorf("failed to set the blank timeout of the guest: %w", err)
+	}
+	if seconds == 0 {
+		logrus.Infof("The guest of instance %q no longer blanks the screen", inst.Name)
+	} else {
+		logrus.Infof("The guest of instance %q blanks the screen after %v of inactivity", inst.Name, time.Duration(seconds)*time.Second)
+	}
+	return nil
+}
+
+func guiBlankTimeoutBashComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return []string{"0", "300", "600"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return showGUIBashComplete(cmd, args, toComplete)
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newGUIScreenshotCommand() *cobra.Command {
+	guiScreenshotCmd := &cobra.Command{
+		Use:   "gui-screenshot INSTANCE",
+		Short: "Take a screenshot of the guest GUI session.",
+		Long: `Take a PNG screenshot of the guest GUI session.
+
+The screenshot is taken inside the guest by the guest agent, with grim on Wayland, and with scrot or ImageMagick import on X11,
+so one of them must be installed in the guest. grim only supports wlroots compositors, such as sway.`,
+		Example: `  Save the screenshot to default.png:
+  $ limactl gui-screenshot default
+
+  Pipe the screenshot to another command:
+  $ limactl gui-screenshot default -o - | feh -`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              guiScreenshotAction,
+		ValidArgsFunction: showGUIBashComplete,
+		GroupID:           advancedCommand,
+	}
+	guiScreenshotCmd.Flags().StringP("output", "o", "", `File to write the screenshot to, "-" for the standard output (default "INSTANCE.png")`)
+	return guiScreenshotCmd
+}
+
+func guiScreenshotAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := checkGUIStatusInstance(inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst.Name, err)
+	}
+	if output == "" {
+		output = inst.Name + ".png"
+	}
+	haClient, err := hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	data, err := haClient.Screenshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to take a screenshot of the guest: %w", err)
+	}
+
+	if output == "-" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return err
+	}
+	logrus.Infof("Saved the screenshot of instance %q to %s", inst.Name, output)
+	return nil
+}
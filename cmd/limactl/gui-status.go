@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/store"
+	"github.com/lima-vm/lima/v2/pkg/uiutil"
+	"github.com/lima-vm/lima/v2/pkg/yqutil"
+)
+
+// guiStatusJobs is the maximum number of instances queried at the same time
+const guiStatusJobs = 8
+
+func newGUIStatusCommand() *cobra.Command {
+	guiStatusCmd := &cobra.Command{
+		Use:   "gui-status [INSTANCE]...",
+		Short: "Show the GUI and clipboard status of instances.",
+		Long: `Show the GUI and clipboard status of instances, as reported by their guest agents.
+
+With --all, every running instance with a display enabled is queried concurrently.
+An instance that cannot be queried is reported with its error, without stopping the other ones.
+
+With --enable-accessibility, GNOME toolkit accessibility is turned on in the guest before querying,
+so that applications started afterwards can be driven over AT-SPI.
+
+The output can be presented in one of several formats, using the --format <format> flag.
+
+  --format table - Output in table format
+  --format json  - Output in JSON format, one object per instance
+  --format yaml  - Output in YAML format, one document per instance
+
+The JSON and YAML objects hold the name of the instance, the display status reported by the host ("display"),
+the GUI information reported by the guest agent ("guest"), and the error when the instance could not be queried.`,
+		Args:              WrapArgsError(cobra.ArbitraryArgs),
+		RunE:              guiStatusAction,
+		ValidArgsFunction: showGUIBashComplete,
+		SilenceErrors:     true,
+		GroupID:           advancedCommand,
+	}
+	guiStatusCmd.Flags().Bool("all", false, "Show all running instances with a display enabled")
+	guiStatusCmd.Flags().Bool("enable-accessibility", false, "Turn on toolkit accessibility (AT-SPI) in the guest")
+	guiStatusCmd.Flags().StringP("format", "f", "table", "Output format, one of: table, json, yaml")
+	return guiStatusCmd
+}
+
+type guiStatusRow struct {
+	name string
+	skip bool
+	inst *limatype.Instance
+	info *guestagentapi.GUIInfo
+	err  error
+}
+
+func guiStatusAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	enableAccessibility, err := cmd.Flags().GetBool("enable-accessibility")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "table" && format != "json" && format != "yaml" {
+		return fmt.Errorf("invalid format %q, expected one of: table, json, yaml", format)
+	}
+	switch {
+	case all && len(args) > 0:
+		return errors.New("cannot specify instances together with --all")
+	case !all && len(args) == 0:
+		return errors.New("specify instances, or --all")
+	}
+
+	instNames := args
+	if all {
+		if instNames, err = store.Instances(); err != nil {
+			return err
+		}
+	}
+
+	req := &guestagentapi.GUIInfoRequest{EnableAccessibility: enableAccessibility}
+	rows := make([]guiStatusRow, len(instNames))
+	var eg errgroup.Group
+	eg.SetLimit(guiStatusJobs)
+	for i, instName := range instNames {
+		eg.Go(func() error {
+			rows[i].name = instName
+			inst, err := store.Inspect(ctx, instName)
+			if err != nil {
+				rows[i].err = err
+				return nil
+			}
+			skip, err := checkGUIStatusInstance(inst)
+			if skip && all {
+				// --all only lists the instances that can report a GUI status
+				rows[i].skip = true
+				return nil
+			}
+			if err != nil {
+				rows[i].err = err
+				return nil
+			}
+			rows[i].inst = inst
+			store.AddSpiceServerInfo(ctx, inst)
+			rows[i].info, rows[i].err = guestGUIInfo(ctx, inst, req)
+			store.AddGuestGUIInfo(inst, rows[i].info)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	if format != "table" {
+		return printGUIStatusRecords(cmd.OutOrStdout(), rows, format)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSERVER\tSESSION\tRESOLUTION\tCLIPBOARD\tA11Y\tAWAKE\tIDLE\tTHEME\tCLIENTS\tINPUT\tERROR")
+	for _, row := range rows {
+		if row.skip {
+			continue
+		}
+		if row.err != nil {
+			logrus.Debugf("failed to get the GUI status of instance %q: %v", row.name, row.err)
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t%v\n", row.name, row.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t-\n", row.name,
+			orDash(row.info.DisplayServer), guiSessionState(row.info), orDash(row.info.Resolution),
+			guiClipboardState(row.info), guiAccessibilityState(row.info), guiAwakeState(row.info), guiIdleState(row.info),
+			guiColorSchemeState(row.info),
+			guiClientsState(row.inst),
+			orDash(strings.Join(row.inst.GUI.InputDevices, ",")))
+	}
+	return w.Flush()
+}
+
+// guiStatusRecord is an instance in the json and yaml output of gui-status
+type guiStatusRecord struct {
+	Name    string            `json:"name"`
+	Display *limatype.GUIInfo `json:"display,omitempty"`
+	Guest   json.RawMessage   `json:"guest,omitempty"` // GUIInfo of the guest agent, in its protobuf JSON mapping
+	Error   string            `json:"error,omitempty"`
+}
+
+// printGUIStatusRecords prints a JSON object per line, or a YAML document, for each row
+func printGUIStatusRecords(w io.Writer, rows []guiStatusRow, format string) error {
+	isTTY := uiutil.OutputIsTTY(w)
+	for _, row := range rows {
+		if row.skip {
+			continue
+		}
+		rec := guiStatusRecord{Name: row.name}
+		if row.err != nil {
+			rec.Error = row.err.Error()
+		} else {
+			rec.Display = row.inst.GUI
+			guest, err := protojson.Marshal(row.info)
+			if err != nil {
+				return err
+			}
+			rec.Guest = guest
+		}
+		j, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		out := string(j) + "\n"
+		if format == "yaml" {
+			// JSON is YAML, yq only reformats it
+			if out, err = yqutil.EvaluateExpressionPlain(".", out, isTTY); err != nil {
+				return err
+			}
+			out = "---\n" + out
+		}
+		if _, err := fmt.Fprint(w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkGUIStatusInstance checks that the GUI status can be queried for the instance.
+// skip is true when the instance is not running or has no display.
+func checkGUIStatusInstance(inst *limatype.Instance) (skip bool, err error) {
+	if inst.Status != limatype.StatusRunning {
+		return true, fmt.Errorf("instance is not running (status: %s)", inst.Status)
+	}
+	if inst.GUI == nil || !inst.GUI.Enabled {
+		return true, errors.New("display is not enabled")
+	}
+	return false, nil
+}
+
+func guiSessionState(info *guestagentapi.GUIInfo) string {
+	switch {
+	case info.SessionActive && info.SessionUser != "":
+		return "active (" + info.SessionUser + ")"
+	case info.SessionActive:
+		return "active"
+	default:
+		return "inactive"
+	}
+}
+
+func guiClipboardState(info *guestagentapi.GUIInfo) string {
+	switch {
+	case info.Spice == nil:
+		return "-"
+	case info.Spice.ClipboardReady:
+		return "ready"
+	case info.Spice.ClipboardMechanism == "guestagent":
+		// Only reachable with `limactl clipboard`
+		return "guestagent"
+	default:
+		return "not ready"
+	}
+}
+
+func guiAccessibilityState(info *guestagentapi.GUIInfo) string {
+	switch {
+	case !info.SessionActive:
+		return "-"
+	case info.AccessibilityBusActive:
+		return "active"
+	default:
+		return "inactive"
+	}
+}
+
+// guiAwakeState reports what keeps the guest session from idling or blanking the screen
+func guiAwakeState(info *guestagentapi.GUIInfo) string {
+	switch {
+	case !info.HasSchema(guestagentapi.GUISchemaAwake):
+		// Older guest agents do not probe it, false would be misleading
+		return "-"
+	case info.IdleInhibited:
+		return "inhibited"
+	case info.ScreenBlankingDisabled:
+		return "no-blanking"
+	case !info.SessionActive:
+		return "-"
+	default:
+		return "no"
+	}
+}
+
+// guiIdleState returns the time since the last user activity in the guest session, e.g., "3m12s",
+// or "unknown" when the guest agent cannot measure it, e.g., on Wayland
+func guiIdleState(info *guestagentapi.GUIInfo) string {
+	if !info.SessionActive {
+		return "-"
+	}
+	return info.FormatIdleTime()
+}
+
+// guiColorSchemeState returns the color scheme of the guest session, "light", "dark", or "unknown"
+func guiColorSchemeState(info *guestagentapi.GUIInfo) string {
+	if !info.HasSchema(guestagentapi.GUISchemaColorScheme) || !info.SessionActive {
+		return "-"
+	}
+	return orDash(info.ColorScheme)
+}
+
+// guiClientsState returns the number of connected SPICE clients; other displays do not report it
+func guiClientsState(inst *limatype.Instance) string {
+	if !isSPICEDisplay(inst) {
+		return "-"
+	}
+	return strconv.Itoa(inst.GUI.ConnectedClients)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/store"
+	"github.com/lima-vm/lima/v2/pkg/uiutil"
+)
+
+// guiWatchHistory is the number of recent changes shown under the panel
+const guiWatchHistory = 8
+
+func newGUIWatchCommand() *cobra.Command {
+	guiWatchCmd := &cobra.Command{
+		Use:   "gui-watch INSTANCE",
+		Short: "Watch the GUI and clipboard status of an instance.",
+		Long: `Watch the GUI and clipboard status of an instance, as a live-updating counterpart of gui-status.
+
+The panel shows the display server, the session, the resolution, the idle state, the clipboard status,
+and the connected SPICE clients, followed by the most recent changes. It is refreshed as the guest agent
+reports changes; the guest agent probes the session every --interval.
+
+When the output is not a terminal, the panel is printed once, followed by a line per change.
+Press Ctrl-C to stop watching.`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              guiWatchAction,
+		ValidArgsFunction: showGUIBashComplete,
+		GroupID:           advancedCommand,
+	}
+	guiWatchCmd.Flags().Duration("interval", 0, "Interval between two probes of the guest agent (default 5s)")
+	guiWatchCmd.Flags().Duration("idle-threshold", 0, "Idle time after which the session is reported as idle (default 1m)")
+	return guiWatchCmd
+}
+
+// guiWatchPanel is the state rendered by gui-watch
+type guiWatchPanel struct {
+	inst    *limatype.Instance
+	info    *guestagentapi.GUIInfo
+	idle    string // "idle" or "active" once the guest agent reported a crossing of the threshold
+	history []string
+}
+
+func guiWatchAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return err
+	}
+	idleThreshold, err := cmd.Flags().GetDuration("idle-threshold")
+	if err != nil {
+		return err
+	}
+	if interval < 0 || idleThreshold < 0 {
+		return errors.New("--interval and --idle-threshold must not be negative")
+	}
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := checkGUIStatusInstance(inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst.Name, err)
+	}
+	haClient, err := hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	changes := make(chan *guestagentapi.GUIInfoChange)
+	errCh := make(chan error, 1)
+	go func() {
+		req := &guestagentapi.GUIInfoWatchRequest{IntervalMs: interval.Milliseconds(), IdleThresholdMs: idleThreshold.Milliseconds()}
+		errCh <- haClient.WatchGUIInfo(ctx, req, func(change *guestagentapi.GUIInfoChange) {
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	w := cmd.OutOrStdout()
+	isTTY := uiutil.OutputIsTTY(w)
+	panel := &guiWatchPanel{inst: inst}
+	// The connected clients are known to the host only, and are not part of the stream
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to watch the GUI status of instance %q: %w", inst.Name, err)
+		case change := <-changes:
+			lines := panel.apply(change)
+			switch {
+			case isTTY:
+				panel.render(w, true)
+			case change.Info != nil:
+				panel.render(w, false)
+			default:
+				for _, line := range lines {
+					fmt.Fprintln(w, line)
+				}
+			}
+		case <-ticker.C:
+			if panel.info == nil {
+				continue
+			}
+			latest, err := store.Inspect(ctx, inst.Name)
+			if err != nil || latest.GUI == nil {
+				continue
+			}
+			prev, cur := guiClientsState(panel.inst), guiClientsState(latest)
+			panel.inst = latest
+			if cur != prev {
+				line := panel.record(time.Now(), "clients", prev, cur)
+				if isTTY {
+					panel.render(w, true)
+				} else {
+					fmt.Fprintln(w, line)
+				}
+			}
+		}
+	}
+}
+
+// apply updates the panel with a message of the guest agent, and returns the lines recorded in the history
+func (p *guiWatchPanel) apply(change *guestagentapi.GUIInfoChange) []string {
+	if change.Info != nil {
+		p.info = change.Info
+	}
+	if p.info == nil {
+		return nil
+	}
+	var lines []string
+	for _, c := range change.Changes {
+		switch c.Field {
+		case "display_server":
+			p.info.DisplayServer = c.NewValue
+		case "session_active":
+			p.info.SessionActive = c.NewValue == "true"
+		case "resolution":
+			p.info.Resolution = c.NewValue
+		case "clipboard_ready":
+			if p.info.Spice == nil {
+				p.info.Spice = &guestagentapi.SpiceAgentInfo{}
+			}
+			p.info.Spice.ClipboardReady = c.NewValue == "true"
+		case "idle":
+			p.idle = c.NewValue
+		}
+		lines = append(lines, p.record(change.Time.AsTime(), c.Field, c.OldValue, c.NewValue))
+	}
+	return lines
+}
+
+// record adds a change to the history, and returns its line
+func (p *guiWatchPanel) record(t time.Time, field, oldValue, newValue string) string {
+	line := fmt.Sprintf("%s  %s: %s -> %s", t.Local().Format(time.TimeOnly), field, orDash(oldValue), orDash(newValue))
+	p.history = append(p.history, line)
+	if len(p.history) > guiWatchHistory {
+		p.history = p.history[len(p.history)-guiWatchHistory:]
+	}
+	return line
+}
+
+// render prints the panel, clearing the terminal first when redraw is set
+func (p *guiWatchPanel) render(w io.Writer, redraw bool) {
+	if p.info == nil {
+		return
+	}
+	if redraw {
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+	}
+	idle := guiIdleState(p.info)
+	if p.idle != "" && p.info.SessionActive {
+		idle = p.idle
+	}
+	tw := tabwriter.NewWriter(w, 4, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Instance:\t%s\n", p.inst.Name)
+	fmt.Fprintf(tw, "Display server:\t%s\n", orDash(p.info.DisplayServer))
+	fmt.Fprintf(tw, "Session:\t%s\n", guiSessionState(p.info))
+	fmt.Fprintf(tw, "Resolution:\t%s\n", orDash(p.info.Resolution))
+	fmt.Fprintf(tw, "Idle:\t%s\n", idle)
+	fmt.Fprintf(tw, "Clipboard:\t%s\n", guiClipboardState(p.info))
+	fmt.Fprintf(tw, "Clients:\t%s\n", guiClientsState(p.inst))
+	_ = tw.Flush()
+	if redraw && len(p.history) > 0 {
+		fmt.Fprintln(w, "\nRecent changes:")
+		for _, line := range p.history {
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+}
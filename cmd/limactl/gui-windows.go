@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newGUIWindowsCommand() *cobra.Command {
+	guiWindowsCmd := &cobra.Command{
+		Use:   "gui-windows INSTANCE",
+		Short: "List the windows of the guest GUI session.",
+		Long: `List the top-level windows of the guest GUI session, as reported by the guest agent.
+
+X11 windows are listed with wmctrl, and Wayland windows with swaymsg, so either must be installed in the guest.
+Other Wayland compositors do not list the windows of their clients.`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              guiWindowsAction,
+		ValidArgsFunction: showGUIBashComplete,
+		GroupID:           advancedCommand,
+	}
+	return guiWindowsCmd
+}
+
+func guiWindowsAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := checkGUIStatusInstance(inst); err != nil {
+		return fmt.Errorf("instance %q: %w", inst.Name, err)
+	}
+	haClient, err := hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	windows, err := haClient.ListWindows(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the guest windows: %w", err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "APP ID\tPID\tWORKSPACE\tGEOMETRY\tTITLE")
+	for _, win := range windows {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%dx%d+%d+%d\t%s\n", orDash(win.AppId), win.Pid, orDash(win.Workspace),
+			win.Width, win.Height, win.X, win.Y, orDash(win.Title))
+	}
+	return w.Flush()
+}
@@ -189,8 +189,17 @@ func newApp() *cobra.Command {
 		newHostagentCommand(),
 		newGuestInstallCommand(),
 		newInfoCommand(),
+		newDriverInfoCommand(),
 		newShowSSHCommand(),
 		newShowGUICommand(),
+		newCloseGUICommand(),
+		newSPICEPasswordCommand(),
+		newGUIStatusCommand(),
+		newGUIWatchCommand(),
+		newGUIWindowsCommand(),
+		newGUIScreenshotCommand(),
+		newGUIBlankTimeoutCommand(),
+		newClipboardCommand(),
 		newDebugCommand(),
 		newEditCommand(),
 		newFactoryResetCommand(),
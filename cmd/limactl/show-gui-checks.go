@@ -0,0 +1,300 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/lima-vm/lima/v2/pkg/driver"
+	"github.com/lima-vm/lima/v2/pkg/driverutil"
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+// guiTarget is what the show-gui preconditions learn about the instance
+type guiTarget struct {
+	instName    string
+	reconnect   bool // reuse the SPICE connection saved by the last show-gui
+	inst        *limatype.Instance
+	driver      *driver.ConfiguredDriver
+	spice       *spiceclient.Connection // SPICE displays only
+	vncHostPort string                  // VNC displays only
+}
+
+// guiCheck is a precondition of show-gui
+type guiCheck struct {
+	name string
+	run  func(ctx context.Context, t *guiTarget) error
+}
+
+// errGUICheckNotApplicable is returned by checks that do not apply to the display type of the instance
+var errGUICheckNotApplicable = errors.New("not applicable")
+
+// guiCheckDialTimeout bounds the reachability checks of the display server
+const guiCheckDialTimeout = 3 * time.Second
+
+// guiChecks are the preconditions of show-gui, in order. Each check relies on the previous ones.
+var guiChecks = []guiCheck{
+	{"instance exists", func(ctx context.Context, t *guiTarget) error {
+		inst, err := store.Inspect(ctx, t.instName)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("instance %q does not exist, run `limactl create %s` to create a new instance", t.instName, t.instName)
+			}
+			return err
+		}
+		t.inst = inst
+		return nil
+	}},
+	{"host supports the display", func(_ context.Context, t *guiTarget) error {
+		return checkGUIHostPlatform(t.inst, runtime.GOOS)
+	}},
+	{"instance is running", func(_ context.Context, t *guiTarget) error {
+		if t.inst.Status != limatype.StatusRunning {
+			return fmt.Errorf("instance %q is not running (status: %s), run `limactl start %s` to start it", t.instName, t.inst.Status, t.instName)
+		}
+		return nil
+	}},
+	{"GUI is enabled", func(_ context.Context, t *guiTarget) error {
+		if t.inst.GUI == nil || !t.inst.GUI.Enabled {
+			displayType := "none"
+			if t.inst.GUI != nil {
+				displayType = t.inst.GUI.Display
+			}
+			return fmt.Errorf("GUI is not enabled for instance %q (display: %s)", t.instName, displayType)
+		}
+		return nil
+	}},
+	{"graphics device is attached", func(_ context.Context, t *guiTarget) error {
+		// The driver may have failed to attach a graphics device, e.g., on an unsupported macOS version
+		if t.inst.GUI.GraphicsDeviceActive != nil && !*t.inst.GUI.GraphicsDeviceActive {
+			reason := t.inst.GUI.GraphicsDeviceError
+			if reason == "" {
+				reason = "unknown error"
+			}
+			return fmt.Errorf("instance %q is running without a graphics device (display: %s): %s", t.instName, t.inst.GUI.Display, reason)
+		}
+		return nil
+	}},
+	{"driver is available", func(_ context.Context, t *guiTarget) error {
+		configuredDriver, err := driverutil.CreateConfiguredDriver(t.inst, t.inst.SSHLocalPort)
+		if err != nil {
+			return fmt.Errorf("failed to create driver for instance %q: %w", t.instName, err)
+		}
+		t.driver = configuredDriver
+		return nil
+	}},
+	{"SPICE server is reachable", func(ctx context.Context, t *guiTarget) error {
+		if !isSPICEDisplay(t.inst) {
+			return errGUICheckNotApplicable
+		}
+		connect := spiceConnection
+		if t.reconnect {
+			connect = savedSPICEConnection
+		}
+		conn, err := connect(ctx, t.inst)
+		if err != nil {
+			return err
+		}
+		network, address := "tcp", net.JoinHostPort(conn.Host, conn.Port)
+		switch {
+		case conn.UnixPath != "":
+			network, address = "unix", conn.UnixPath
+		case conn.Port == "":
+			address = net.JoinHostPort(conn.Host, conn.TLSPort)
+		}
+		if err := dialGUIServer(ctx, network, address); err != nil {
+			if t.reconnect {
+				return fmt.Errorf("SPICE server of instance %q is not reachable at the saved address, run show-gui without --reconnect: %w", t.instName, err)
+			}
+			return fmt.Errorf("SPICE server of instance %q is not reachable: %w", t.instName, err)
+		}
+		t.spice = conn
+		return nil
+	}},
+	{"SPICE viewer is installed", func(_ context.Context, t *guiTarget) error {
+		if !isSPICEDisplay(t.inst) {
+			return errGUICheckNotApplicable
+		}
+		_, err := spiceclient.FindViewer()
+		return err
+	}},
+	{"VNC server is reachable", func(ctx context.Context, t *guiTarget) error {
+		if !isVNCDisplay(t.inst) {
+			return errGUICheckNotApplicable
+		}
+		hostPort, err := t.driver.DisplayConnection(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get VNC connection info: %w", err)
+		}
+		if err := dialGUIServer(ctx, "tcp", hostPort); err != nil {
+			return fmt.Errorf("VNC server of instance %q is not reachable: %w", t.instName, err)
+		}
+		t.vncHostPort = hostPort
+		return nil
+	}},
+	{"driver can run the GUI", func(_ context.Context, t *guiTarget) error {
+		// SPICE and VNC are displayed by external viewers, not by the driver
+		if isSPICEDisplay(t.inst) || isVNCDisplay(t.inst) {
+			return errGUICheckNotApplicable
+		}
+		if !t.inst.GUI.CanRunGUI {
+			return fmt.Errorf("GUI is not supported for instance %q (driver: %s, display: %s)", t.instName, t.inst.VMType, t.inst.GUI.Display)
+		}
+		return nil
+	}},
+}
+
+// checkGUIHostPlatform explains that the VZ window only exists on macOS hosts,
+// instead of letting the driver construction fail on other hosts
+func checkGUIHostPlatform(inst *limatype.Instance, goos string) error {
+	if inst.VMType == limatype.VZ && goos != "darwin" {
+		return fmt.Errorf("the GUI of VZ instance %q requires a macOS host, as it is a window of Virtualization.framework (this host: %s); "+
+			"SPICE and VNC displays of QEMU instances work on every host, see video.display", inst.Name, goos)
+	}
+	return nil
+}
+
+func dialGUIServer(ctx context.Context, network, address string) error {
+	d := net.Dialer{Timeout: guiCheckDialTimeout}
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// runGUIChecks runs the show-gui preconditions for the instance and returns the first failure
+func runGUIChecks(ctx context.Context, instName string, reconnect bool) (*guiTarget, error) {
+	t := &guiTarget{instName: instName, reconnect: reconnect}
+	for _, check := range guiChecks {
+		if err := check.run(ctx, t); err != nil && !errors.Is(err, errGUICheckNotApplicable) {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// guiCheckResult is the outcome of a show-gui precondition
+type guiCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // "ok", "fail" or "skip"
+	Error  string `json:"error,omitempty"`
+}
+
+// probeGUIChecks runs every show-gui precondition and returns their outcomes, and the first failure.
+// The checks after a failure are skipped, as they depend on it.
+func probeGUIChecks(ctx context.Context, instName string, reconnect bool) (*guiTarget, []guiCheckResult, error) {
+	t := &guiTarget{instName: instName, reconnect: reconnect}
+	var results []guiCheckResult
+	var failed error
+	for _, check := range guiChecks {
+		if failed != nil {
+			results = append(results, guiCheckResult{Name: check.name, Status: "skip"})
+			continue
+		}
+		switch err := check.run(ctx, t); {
+		case errors.Is(err, errGUICheckNotApplicable):
+			continue
+		case err != nil:
+			results = append(results, guiCheckResult{Name: check.name, Status: "fail", Error: err.Error()})
+			failed = err
+		default:
+			results = append(results, guiCheckResult{Name: check.name, Status: "ok"})
+		}
+	}
+	if failed != nil {
+		return t, results, fmt.Errorf("show-gui would fail for instance %q: %w", instName, failed)
+	}
+	return t, results, nil
+}
+
+// printGUIChecks prints the outcome of every show-gui precondition
+func printGUIChecks(w io.Writer, results []guiCheckResult) {
+	for _, r := range results {
+		switch r.Status {
+		case "fail":
+			fmt.Fprintf(w, "[fail] %s: %s\n", r.Name, r.Error)
+		case "skip":
+			fmt.Fprintf(w, "[skip] %s\n", r.Name)
+		default:
+			fmt.Fprintf(w, "[ok]   %s\n", r.Name)
+		}
+	}
+}
+
+// guiProbeReport is the output of `show-gui --json`
+type guiProbeReport struct {
+	Name   string           `json:"name"`
+	Checks []guiCheckResult `json:"checks"`
+	// GuestOutput is the active output of the guest session, the primary one or the first one,
+	// in its protobuf JSON mapping
+	GuestOutput json.RawMessage `json:"guestOutput,omitempty"`
+	// HostMonitor is the host monitor the display is shown on
+	HostMonitor *driver.MonitorInfo `json:"hostMonitor,omitempty"`
+	// RefreshMismatch is set when the guest output refreshes at another rate than the host monitor,
+	// e.g., a 60 Hz guest on a 120 Hz host, which stutters
+	RefreshMismatch bool `json:"refreshMismatch"`
+}
+
+// refreshRateTolerance is the difference in Hz below which refresh rates match, e.g., 59.94 and 60
+const refreshRateTolerance = 1
+
+// printGUIProbeReport prints the outcome of the show-gui preconditions as JSON, with the refresh rates
+// of the guest output and of the host monitor (numbered from 1) once the preconditions hold
+func printGUIProbeReport(ctx context.Context, w io.Writer, t *guiTarget, results []guiCheckResult, hostMonitor int, guestDisplay string) error {
+	report := guiProbeReport{Name: t.instName, Checks: results}
+	if t.driver != nil {
+		if monitors := t.driver.HostMonitors(); hostMonitor <= len(monitors) {
+			report.HostMonitor = &monitors[hostMonitor-1]
+		}
+		// The guest output is best effort, the report must not wait for an unresponsive guest agent
+		guestCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		guiInfo, err := guestGUIInfo(guestCtx, t.inst, &guestagentapi.GUIInfoRequest{Display: guestDisplay})
+		cancel()
+		if err != nil {
+			logrus.WithError(err).Debug("Failed to get the guest outputs")
+		} else if output := activeGuestOutput(guiInfo.Outputs); output != nil {
+			if report.GuestOutput, err = protojson.Marshal(output); err != nil {
+				return err
+			}
+			if report.HostMonitor != nil && output.RefreshRate > 0 && report.HostMonitor.RefreshRate > 0 {
+				report.RefreshMismatch = math.Abs(output.RefreshRate-report.HostMonitor.RefreshRate) >= refreshRateTolerance
+			}
+		}
+	}
+	j, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(j))
+	return err
+}
+
+// activeGuestOutput returns the primary output, or the first one
+func activeGuestOutput(outputs []*guestagentapi.DisplayMode) *guestagentapi.DisplayMode {
+	for _, o := range outputs {
+		if o.Primary {
+			return o
+		}
+	}
+	if len(outputs) > 0 {
+		return outputs[0]
+	}
+	return nil
+}
@@ -4,14 +4,32 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/lima-vm/lima/v2/pkg/driver"
 	"github.com/lima-vm/lima/v2/pkg/driverutil"
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/limayaml"
+	"github.com/lima-vm/lima/v2/pkg/localpathutil"
+	"github.com/lima-vm/lima/v2/pkg/lockutil"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
 	"github.com/lima-vm/lima/v2/pkg/store"
 )
 
@@ -29,6 +47,7 @@ For VZ instances:
 
 For QEMU/SPICE instances:
 - Launches a new viewer window that can be closed and reopened without affecting the VM
+- With --cleanup, removes the PID files of viewers that have crashed, and stops the viewers whose instance is no longer running
 
 Requirements:
 - Instance must be running
@@ -39,6 +58,25 @@ Requirements:
 		SilenceErrors:     true,
 		GroupID:           advancedCommand,
 	}
+	showGUICmd.Flags().Bool("wait", false, "Wait for the SPICE viewer to be closed, instead of detaching it")
+	showGUICmd.Flags().Bool("supervise", false, "Relaunch the SPICE viewer when it crashes, until interrupted (implies --wait)")
+	showGUICmd.Flags().Bool("probe-only", false, "Check the preconditions and print a report, without opening the display")
+	showGUICmd.Flags().Bool("json", false, "Print the --probe-only report in JSON, with the refresh rates of the guest output and of the host monitor (implies --probe-only)")
+	showGUICmd.Flags().Bool("cleanup", false, "Remove stale SPICE viewer PID files, and stop the viewers left over by a stopped instance, without opening the display")
+	showGUICmd.Flags().String("display", "", "Guest X11 display to use, e.g. :1 (default: the primary display)")
+	showGUICmd.Flags().String("shared-dir", "", "Host directory to share with the guest over SPICE WebDAV, as PATH[:ro]")
+	showGUICmd.Flags().StringSlice("monitor-mapping", nil, "Guest displays to show on host monitors in full-screen mode, as GUEST:HOST pairs, e.g. 1:1,2:2")
+	showGUICmd.Flags().Bool("per-monitor", false, "Show each guest display full-screen on the host monitor of the same number")
+	showGUICmd.Flags().Int("monitor", 0, "Host monitor to show the display on, numbered from 1 (SPICE: shorthand for --monitor-mapping 1:N)")
+	showGUICmd.Flags().String("window-size", "", "Open the SPICE viewer in a window of WxH pixels instead of full screen, or \"auto\" for the guest resolution")
+	showGUICmd.Flags().Int("zoom", 0, "Open the SPICE viewer in a window at this zoom level in percent, e.g. 100 for one host pixel per guest pixel (remote-viewer only)")
+	showGUICmd.Flags().Bool("reconnect", false, "Reopen the SPICE viewer with the connection and viewer options of the last show-gui, without discovering the server again")
+	showGUICmd.Flags().String("host-display", "", "Host display to show the SPICE viewer on, e.g. :0 (X11) or wayland-0 (Wayland) (default: the display of limactl)")
+	showGUICmd.Flags().StringSlice("channels", nil, "SPICE channels to enable, e.g. display,inputs,cursor (default: all channels)")
+	showGUICmd.Flags().Bool("view-only", false, "Show the SPICE display without controlling the guest, by disabling the inputs channel (remote-viewer only)")
+	showGUICmd.Flags().Bool("connection-file", false, "Pass the SPICE connection to the viewer in a connection file (.vv), keeping the password out of its arguments (remote-viewer only)")
+	showGUICmd.Flags().String("audio-device", "", "Host audio device to play the SPICE audio on: a PulseAudio sink (Linux), or a CoreAudio device ID (macOS) (default: the system default)")
+	showGUICmd.Flags().StringArray("hotkey", nil, "Rebind a SPICE viewer hotkey, as ACTION=KEYS, e.g. release-cursor=ctrl+shift+f12 (empty KEYS disables it)")
 
 	return showGUICmd
 }
@@ -47,48 +85,793 @@ func showGUIAction(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	instName := args[0]
 
-	// Check if instance exists
-	inst, err := store.Inspect(ctx, instName)
+	channels, err := cmd.Flags().GetStringSlice("channels")
+	if err != nil {
+		return err
+	}
+	if err := spiceclient.ValidateChannels(channels); err != nil {
+		return err
+	}
+	wait, err := cmd.Flags().GetBool("wait")
+	if err != nil {
+		return err
+	}
+	sharedDir, err := cmd.Flags().GetString("shared-dir")
+	if err != nil {
+		return err
+	}
+	supervise, err := cmd.Flags().GetBool("supervise")
+	if err != nil {
+		return err
+	}
+	monitorMappingFlag, err := cmd.Flags().GetStringSlice("monitor-mapping")
+	if err != nil {
+		return err
+	}
+	monitorMapping, err := parseMonitorMapping(monitorMappingFlag)
+	if err != nil {
+		return err
+	}
+	hostMonitor, err := cmd.Flags().GetInt("monitor")
+	if err != nil {
+		return err
+	}
+	if hostMonitor < 0 {
+		return fmt.Errorf("invalid --monitor %d, host monitors are numbered from 1", hostMonitor)
+	}
+	if hostMonitor > 0 && len(monitorMapping) > 0 {
+		return errors.New("cannot specify --monitor together with --monitor-mapping")
+	}
+	perMonitor, err := cmd.Flags().GetBool("per-monitor")
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("instance %q does not exist, run `limactl create %s` to create a new instance", instName, instName)
+		return err
+	}
+	if perMonitor && (hostMonitor > 0 || len(monitorMapping) > 0 || wait || supervise) {
+		return errors.New("cannot specify --per-monitor together with --monitor, --monitor-mapping, --wait or --supervise")
+	}
+	windowSize, err := cmd.Flags().GetString("window-size")
+	if err != nil {
+		return err
+	}
+	if windowSize != "" && windowSize != "auto" {
+		if _, _, err := spiceclient.ParseWindowSize(windowSize); err != nil {
+			return fmt.Errorf("invalid --window-size: %w", err)
 		}
+	}
+	if windowSize != "" && (perMonitor || hostMonitor > 0 || len(monitorMapping) > 0) {
+		return errors.New("cannot specify --window-size together with --per-monitor, --monitor or --monitor-mapping, which are full-screen")
+	}
+	zoom, err := cmd.Flags().GetInt("zoom")
+	if err != nil {
+		return err
+	}
+	if zoom < 0 {
+		return fmt.Errorf("invalid --zoom %d, expected a percentage", zoom)
+	}
+	if zoom != 0 && (windowSize != "" || perMonitor || hostMonitor > 0 || len(monitorMapping) > 0) {
+		return errors.New("cannot specify --zoom together with --window-size, --per-monitor, --monitor or --monitor-mapping")
+	}
+	hostDisplay, err := cmd.Flags().GetString("host-display")
+	if err != nil {
+		return err
+	}
+	hotkeyFlag, err := cmd.Flags().GetStringArray("hotkey")
+	if err != nil {
+		return err
+	}
+	hotkeys, err := parseHotkeys(hotkeyFlag)
+	if err != nil {
+		return err
+	}
+	viewOnly, err := cmd.Flags().GetBool("view-only")
+	if err != nil {
+		return err
+	}
+	connectionFile, err := cmd.Flags().GetBool("connection-file")
+	if err != nil {
+		return err
+	}
+	audioDevice, err := cmd.Flags().GetString("audio-device")
+	if err != nil {
+		return err
+	}
+	probeOnly, err := cmd.Flags().GetBool("probe-only")
+	if err != nil {
+		return err
+	}
+	jsonReport, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	guestDisplay, err := cmd.Flags().GetString("display")
+	if err != nil {
+		return err
+	}
+	reconnect, err := cmd.Flags().GetBool("reconnect")
+	if err != nil {
 		return err
 	}
+	if reconnect && (len(channels) > 0 || sharedDir != "" || len(monitorMapping) > 0 || hostMonitor > 0 || perMonitor || len(hotkeys) > 0 || windowSize != "" || zoom != 0 || viewOnly || connectionFile || audioDevice != "") {
+		return errors.New("cannot specify viewer options together with --reconnect, which reuses the saved ones")
+	}
+	var guestDisplayNum int
+	if guestDisplay != "" {
+		if guestDisplayNum, err = parseX11DisplayNumber(guestDisplay); err != nil {
+			return err
+		}
+	}
 
-	// Check if instance is running
-	if inst.Status != "Running" {
-		return fmt.Errorf("instance %q is not running (status: %s), run `limactl start %s` to start it", instName, inst.Status, instName)
+	cleanup, err := cmd.Flags().GetBool("cleanup")
+	if err != nil {
+		return err
+	}
+	if cleanup {
+		return cleanupViewers(ctx, instName)
+	}
+	if probeOnly || jsonReport {
+		target, results, err := probeGUIChecks(ctx, instName, reconnect)
+		if jsonReport {
+			monitor := max(hostMonitor, 1)
+			if reportErr := printGUIProbeReport(ctx, cmd.OutOrStdout(), target, results, monitor, guestDisplay); reportErr != nil {
+				return reportErr
+			}
+		} else {
+			printGUIChecks(cmd.OutOrStdout(), results)
+		}
+		if err != nil {
+			return explainGUIUnavailable(ctx, instName, err)
+		}
+		return nil
+	}
+	target, err := runGUIChecks(ctx, instName, reconnect)
+	if err != nil {
+		return explainGUIUnavailable(ctx, instName, err)
+	}
+	inst, configuredDriver := target.inst, target.driver
+	if hostMonitor > 0 {
+		if err := checkHostMonitor(configuredDriver.HostMonitors(), hostMonitor); err != nil {
+			return err
+		}
 	}
 
-	// Check GUI info from instance
-	if inst.GUI == nil || !inst.GUI.Enabled {
-		displayType := "none"
-		if inst.GUI != nil {
-			displayType = inst.GUI.Display
+	var guiInfo *guestagentapi.GUIInfo
+	if guestDisplay != "" {
+		guiInfo, err = guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{Display: guestDisplay})
+		if err != nil {
+			return fmt.Errorf("failed to get GUI information for guest display %s: %w", guestDisplay, err)
+		}
+		if !guiInfo.SessionActive {
+			if reason := noGraphicalTargetReason(guiInfo); reason != "" {
+				return fmt.Errorf("no GUI session on guest display %s of instance %q: %s", guestDisplay, instName, reason)
+			}
+			return fmt.Errorf("no GUI session on guest display %s of instance %q: %s", guestDisplay, instName, strings.Join(guiInfo.Warnings, "; "))
 		}
-		return fmt.Errorf("GUI is not enabled for instance %q (display: %s)", instName, displayType)
+		logrus.Infof("Guest display %s is active (resolution: %s)", guestDisplay, guiInfo.Resolution)
 	}
 
-	if !inst.GUI.CanRunGUI {
-		return fmt.Errorf("GUI is not supported for instance %q (driver: %s, display: %s)", instName, inst.VMType, inst.GUI.Display)
+	// VNC has no built-in viewer, print the address to connect a VNC client to.
+	// Each guest X11 display :N is served on port 5900+N.
+	if isVNCDisplay(inst) {
+		hostPort := target.vncHostPort
+		if guestDisplay != "" {
+			hostPort = net.JoinHostPort("127.0.0.1", strconv.Itoa(5900+guestDisplayNum))
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "vnc://%s\n", hostPort)
+		return err
 	}
 
-	// Get the configured driver for this instance
-	configuredDriver, err := driverutil.CreateConfiguredDriver(inst, inst.SSHLocalPort)
-	if err != nil {
-		return fmt.Errorf("failed to create driver for instance %q: %w", instName, err)
+	// SPICE viewers are launched by limactl itself, so that the viewer options can be applied
+	if isSPICEDisplay(inst) {
+		conn := target.spice
+		conn.Detach = !wait
+		if !reconnect {
+			conn.Channels = channels
+			conn.MonitorMapping = monitorMapping
+			if hostMonitor > 0 {
+				conn.MonitorMapping = map[int]int{1: hostMonitor}
+			}
+			conn.Hotkeys = hotkeys
+			conn.ViewOnly = viewOnly
+			conn.ConnectionFile = connectionFile
+			conn.AudioDevice = audioDevice
+			if windowSize != "" {
+				if conn.WindowSize, err = viewerWindowSize(ctx, inst, windowSize, guiInfo); err != nil {
+					return err
+				}
+			}
+			if zoom != 0 {
+				conn.ZoomLevel = zoom
+				logZoomedWindowSize(ctx, inst, zoom, guiInfo)
+			}
+			if sharedDir != "" {
+				if conn.SharedDir, conn.SharedDirReadOnly, err = parseSharedDir(sharedDir); err != nil {
+					return err
+				}
+			}
+		}
+		if hostDisplay != "" {
+			// Also applies with --reconnect, e.g., when the saved display is gone
+			conn.HostDisplayEnv = hostDisplayEnv(hostDisplay)
+		}
+		// Concurrent invocations share the viewer instead of opening a second window
+		conn.PIDFile = filepath.Join(inst.Dir, filenames.SPICEViewerPID)
+		store.AddSpiceServerInfo(ctx, inst)
+		if warning := spiceclient.ServerVersionWarning(inst.GUI.ServerVersion); warning != "" {
+			logrus.Warn(warning)
+		}
+		warnGuestGUI(ctx, inst, guiInfo)
+		if perMonitor {
+			if conn.MonitorMapping, err = perMonitorMapping(ctx, inst, guiInfo); err != nil {
+				return err
+			}
+		}
+		if wait || supervise {
+			// The viewer is a child of limactl until it is closed, stop it rather than leaking it on SIGINT/SIGTERM
+			var cancel context.CancelFunc
+			ctx, cancel = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+		}
+		if supervise {
+			logrus.Infof("Supervising SPICE viewer for instance %q, press Ctrl-C to stop", instName)
+			err = spiceclient.SuperviseViewer(ctx, conn)
+		} else {
+			logrus.Infof("Launching SPICE viewer for instance %q...", instName)
+			err = spiceclient.LaunchViewer(ctx, conn)
+		}
+		var runningErr *spiceclient.ViewerRunningError
+		if errors.As(err, &runningErr) {
+			logrus.Infof("SPICE viewer for instance %q is already open (pid %d)", instName, runningErr.PID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// For `show-gui --reconnect`
+		if err := spiceclient.SaveConnection(filepath.Join(inst.Dir, filenames.SPICEConnection), conn); err != nil {
+			logrus.WithError(err).Warn("Failed to save the SPICE connection")
+		}
+		return nil
+	}
+
+	if len(channels) > 0 {
+		return fmt.Errorf("--channels is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if sharedDir != "" {
+		return fmt.Errorf("--shared-dir is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if supervise {
+		return fmt.Errorf("--supervise is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if len(monitorMapping) > 0 {
+		return fmt.Errorf("--monitor-mapping is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if perMonitor {
+		return fmt.Errorf("--per-monitor is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if len(hotkeys) > 0 {
+		return fmt.Errorf("--hotkey is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if reconnect {
+		return fmt.Errorf("--reconnect is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if windowSize != "" {
+		return fmt.Errorf("--window-size is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if hostDisplay != "" {
+		return fmt.Errorf("--host-display is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if connectionFile {
+		return fmt.Errorf("--connection-file is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if zoom != 0 {
+		return fmt.Errorf("--zoom is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+	if audioDevice != "" {
+		return fmt.Errorf("--audio-device is only supported for SPICE displays (display: %s)", inst.GUI.Display)
+	}
+
+	if hostMonitor > 0 {
+		// Virtualization.framework offers no way to place its window, only report where to move it
+		logrus.Warnf("The %s display window cannot be moved programmatically, move it to host monitor %d manually", inst.GUI.Display, hostMonitor)
+	}
+
+	if len(inst.GUI.InputDevices) > 0 {
+		// Users debugging scrolling or gestures need to know that there is no trackpad
+		logrus.Infof("Input devices attached to the VM: %s", strings.Join(inst.GUI.InputDevices, ", "))
+	}
+
+	if guiInfo == nil {
+		// Inspect does not ask the guest; the resolution check is best effort, and must not delay the window
+		guestCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		guiInfo, _ = guestGUIInfo(guestCtx, inst, &guestagentapi.GUIInfoRequest{})
+		cancel()
+	}
+	store.AddGuestGUIInfo(inst, guiInfo)
+	if inst.GUI.ResolutionMismatch {
+		switch {
+		case inst.GUI.DynamicResolution == nil:
+			logrus.Warnf("Requested %s but the guest is at %s, the guest did not pick up the display mode; "+
+				"install an agent that resizes the display (e.g., spice-vdagent)", inst.GUI.RequestedResolution, inst.GUI.GuestResolution)
+		case !*inst.GUI.DynamicResolution:
+			logrus.Warnf("Requested %s but the guest is at %s, and nothing in the guest resizes the display, so resizing the window "+
+				"only letterboxes it; run spice-vdagent in an X11 session, or a desktop that follows the display size (e.g., GNOME or KDE)",
+				inst.GUI.RequestedResolution, inst.GUI.GuestResolution)
+		default:
+			logrus.Warnf("Requested %s but the guest is at %s, the guest did not pick up the display mode yet; "+
+				"resizing the window resizes the guest display", inst.GUI.RequestedResolution, inst.GUI.GuestResolution)
+		}
+		if err := checkVZResolution(inst.GUI.RequestedResolution, guiInfo); err != nil {
+			logrus.Warnf("%v; set video.vz.width and video.vz.height to a supported mode", err)
+		}
 	}
 
 	// Launch the GUI
 	logrus.Infof("Launching GUI window for instance %q...", instName)
-	if err := configuredDriver.RunGUI(); err != nil {
+	// Serialize concurrent invocations, so that the window is not raised twice
+	if err := lockutil.WithDirLock(inst.Dir, configuredDriver.RunGUI); err != nil {
 		return fmt.Errorf("failed to launch GUI: %w", err)
 	}
 
 	return nil
 }
 
+// perMonitorMapping maps each output of the guest desktop to the host monitor of the same number.
+// The SPICE server accepts a single client, a second viewer would disconnect the first one,
+// so a single viewer opens a full-screen window per guest display.
+func perMonitorMapping(ctx context.Context, inst *limatype.Instance, guiInfo *guestagentapi.GUIInfo) (map[int]int, error) {
+	if guiInfo == nil {
+		var err error
+		if guiInfo, err = guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{}); err != nil {
+			return nil, fmt.Errorf("failed to get the guest displays of instance %q: %w", inst.Name, err)
+		}
+	}
+	if len(guiInfo.Outputs) == 0 {
+		return nil, fmt.Errorf("cannot find the displays of the guest session of instance %q", inst.Name)
+	}
+	mapping := make(map[int]int, len(guiInfo.Outputs))
+	for n := 1; n <= len(guiInfo.Outputs); n++ {
+		mapping[n] = n
+	}
+	logrus.Infof("Showing the %d displays of instance %q on host monitors 1 to %d", len(guiInfo.Outputs), inst.Name, len(guiInfo.Outputs))
+	return mapping, nil
+}
+
+// viewerWindowSize resolves --window-size, "auto" being the resolution of the guest display.
+// The viewers size their window to the guest display, so a different guest resolution is only warned about.
+func viewerWindowSize(ctx context.Context, inst *limatype.Instance, size string, guiInfo *guestagentapi.GUIInfo) (string, error) {
+	if guiInfo == nil {
+		var err error
+		if guiInfo, err = guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{}); err != nil {
+			if size == "auto" {
+				return "", fmt.Errorf("failed to get the guest resolution for --window-size=auto: %w", err)
+			}
+			logrus.WithError(err).Debug("Failed to get the guest resolution")
+			return size, nil
+		}
+	}
+	switch {
+	case size == "auto" && guiInfo.Resolution == "":
+		return "", fmt.Errorf("cannot detect the guest resolution of instance %q, specify --window-size as WxH", inst.Name)
+	case size == "auto":
+		return guiInfo.Resolution, nil
+	case guiInfo.Resolution != "" && guiInfo.Resolution != size:
+		var width, height int32
+		if _, err := fmt.Sscanf(size, "%dx%d", &width, &height); err == nil {
+			if err := guiInfo.CheckResolution(width, height, nil); err != nil {
+				logrus.Warnf("The viewer window takes the size of the guest display, which is at %s; %v", guiInfo.Resolution, err)
+				break
+			}
+		}
+		logrus.Warnf("The viewer window takes the size of the guest display, which is at %s; set the guest resolution to %s for a window of that size",
+			guiInfo.Resolution, size)
+	}
+	return size, nil
+}
+
+// checkVZResolution checks that the guest can set its display to the resolution requested by VZ.
+// The nearest supported mode is one that VZ can request.
+func checkVZResolution(requested string, guiInfo *guestagentapi.GUIInfo) error {
+	var width, height int32
+	if _, err := fmt.Sscanf(requested, "%dx%d", &width, &height); err != nil {
+		return nil
+	}
+	return guiInfo.CheckResolution(width, height, func(m *guestagentapi.ScreenMode) bool {
+		return limayaml.ValidVZDisplaySize(int(m.GetWidth()), int(m.GetHeight()))
+	})
+}
+
+// logZoomedWindowSize reports the size of the viewer window at --zoom, from the resolution of the guest display
+func logZoomedWindowSize(ctx context.Context, inst *limatype.Instance, zoom int, guiInfo *guestagentapi.GUIInfo) {
+	if guiInfo == nil {
+		var err error
+		if guiInfo, err = guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{}); err != nil {
+			logrus.WithError(err).Debug("Failed to get the guest resolution")
+			return
+		}
+	}
+	if guiInfo.Resolution == "" {
+		logrus.Warnf("Cannot detect the guest resolution of instance %q, the size of the viewer window at %d%% is unknown", inst.Name, zoom)
+		return
+	}
+	size, err := spiceclient.ZoomedWindowSize(guiInfo.Resolution, zoom)
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to compute the size of the viewer window")
+		return
+	}
+	logrus.Infof("The viewer window shows the guest display (%s) at %d%%, %s host pixels", guiInfo.Resolution, zoom, size)
+}
+
+// checkHostMonitor checks that the 1-based host monitor exists.
+// An empty list means that the driver does not enumerate the host monitors, e.g., as the SPICE viewer does it.
+func checkHostMonitor(monitors []driver.MonitorInfo, num int) error {
+	if len(monitors) == 0 {
+		return nil
+	}
+	if num > len(monitors) {
+		return fmt.Errorf("host monitor %d does not exist, the host has %d monitors", num, len(monitors))
+	}
+	m := monitors[num-1]
+	logrus.Debugf("Host monitor %d: %gx%g at (%g,%g), scale factor %g", num, m.Width, m.Height, m.X, m.Y, m.ScaleFactor)
+	return nil
+}
+
+func isSPICEDisplay(inst *limatype.Instance) bool {
+	return inst.Config.Video.Display != nil && strings.HasPrefix(*inst.Config.Video.Display, "spice")
+}
+
+func isVNCDisplay(inst *limatype.Instance) bool {
+	return inst.Config.Video.Display != nil && strings.HasPrefix(*inst.Config.Video.Display, "vnc")
+}
+
+// parseSharedDir parses a PATH[:ro] value of --shared-dir, with PATH made absolute
+func parseSharedDir(s string) (dir string, readOnly bool, err error) {
+	dir, readOnly = strings.CutSuffix(s, ":ro")
+	if dir, err = localpathutil.Expand(dir); err != nil {
+		return "", false, fmt.Errorf("invalid --shared-dir: %w", err)
+	}
+	// The directory itself is validated by spiceclient before launching the viewer
+	return dir, readOnly, nil
+}
+
+// parseMonitorMapping parses the GUEST:HOST pairs of --monitor-mapping.
+// The monitor numbers are validated by spiceclient, against the host monitors.
+func parseMonitorMapping(pairs []string) (map[int]int, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	mapping := make(map[int]int, len(pairs))
+	for _, pair := range pairs {
+		guest, host, ok := strings.Cut(pair, ":")
+		guestNum, guestErr := strconv.Atoi(guest)
+		hostNum, hostErr := strconv.Atoi(host)
+		if !ok || guestErr != nil || hostErr != nil {
+			return nil, fmt.Errorf("invalid --monitor-mapping %q, expected GUEST:HOST, e.g. 1:2", pair)
+		}
+		if _, dup := mapping[guestNum]; dup {
+			return nil, fmt.Errorf("invalid --monitor-mapping: guest display %d is mapped twice", guestNum)
+		}
+		mapping[guestNum] = hostNum
+	}
+	return mapping, nil
+}
+
+// parseHotkeys parses the ACTION=KEYS values of --hotkey
+func parseHotkeys(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	hotkeys := make(map[string]string, len(values))
+	for _, v := range values {
+		action, keys, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --hotkey %q, expected ACTION=KEYS, e.g. release-cursor=ctrl+shift+f12", v)
+		}
+		hotkeys[action] = keys
+	}
+	if err := spiceclient.ValidateHotkeys(hotkeys); err != nil {
+		return nil, fmt.Errorf("invalid --hotkey: %w", err)
+	}
+	return hotkeys, nil
+}
+
+// hostDisplayEnv returns the environment selecting the host display, an X11 display such as ":0"
+// or a Wayland display such as "wayland-0"
+func hostDisplayEnv(display string) []string {
+	if strings.Contains(display, ":") {
+		return []string{"DISPLAY=" + display}
+	}
+	return []string{"WAYLAND_DISPLAY=" + display}
+}
+
+// parseX11DisplayNumber parses a local X11 display name such as ":1" or ":1.0".
+func parseX11DisplayNumber(display string) (int, error) {
+	num, ok := strings.CutPrefix(display, ":")
+	if ok {
+		num, _, _ = strings.Cut(num, ".")
+		if n, err := strconv.Atoi(num); err == nil && n >= 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid guest display %q, expected a display like \":1\"", display)
+}
+
+// cleanupViewers removes the stale viewer PID files of the instance, and stops the viewers it no longer needs
+func cleanupViewers(ctx context.Context, instName string) error {
+	inst, err := store.Inspect(ctx, instName)
+	if err != nil {
+		return err
+	}
+	// A viewer can only outlive its VM, e.g., when the instance was stopped while it was detached
+	orphaned := inst.Status != limatype.StatusRunning
+	stopped, removed, err := spiceclient.CleanupViewers(filepath.Join(inst.Dir, filenames.SPICEViewerPID), orphaned)
+	logrus.Infof("Stopped %d orphaned SPICE viewer(s) and removed %d stale PID file(s) for instance %q", stopped, removed, instName)
+	return err
+}
+
+// warnGuestGUI warns about guest settings that prevent a usable GUI session.
+// guiInfo may be nil, in which case it is fetched on a best-effort basis.
+func warnGuestGUI(ctx context.Context, inst *limatype.Instance, guiInfo *guestagentapi.GUIInfo) {
+	if guiInfo == nil {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		var err error
+		if guiInfo, err = guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{}); err != nil {
+			logrus.WithError(err).Debug("Failed to get GUI information from the guest")
+			return
+		}
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaCloudInit) && !guiInfo.CloudInitDone {
+		logrus.Warn("GUI may not be ready: cloud-init is still running in the guest, the user and the desktop may not be set up yet")
+	}
+	// FreeBSD guests do not report the driver
+	if guiInfo.HasSchema(guestagentapi.GUISchemaVirtioGPU) && !guiInfo.VirtioGpuLoaded && *inst.Config.OS == limatype.LINUX {
+		logrus.Warn("No virtio_gpu driver in the guest, the display will stay blank; " +
+			"install or enable the virtio_gpu kernel module (e.g., the linux-modules-extra package on Ubuntu)")
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaModesetting) && guiInfo.ModesettingConflict {
+		logrus.Warnf("Another display driver may be taking over from virtio_gpu in the guest, the display may stay blank: %s",
+			guiInfo.ModesettingConflictDetail)
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaDRIDevice) && guiInfo.DriDeviceDriver != "" && guiInfo.DriDeviceDriver != "virtio_gpu" {
+		logrus.Warnf("The display server of the guest runs on %s (%s), not on the virtio_gpu card, the display may stay blank; "+
+			"point it to the virtio_gpu card, e.g., with Option \"kmsdev\" in xorg.conf or WLR_DRM_DEVICES for wlroots compositors",
+			guiInfo.DriDevice, guiInfo.DriDeviceDriver)
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaOutputConnector) {
+		for _, o := range guiInfo.RenamedOutputs() {
+			logrus.Infof("Guest output %q is driven by the DRM connector %q; scripts have to address it as %q, e.g., with xrandr",
+				o.Name, o.Connector, o.Name)
+		}
+	}
+	if !guiInfo.SessionActive {
+		if reason := noGraphicalTargetReason(guiInfo); reason != "" {
+			logrus.Warnf("No GUI session in the guest: %s", reason)
+		} else if guiInfo.DrmMaster != "" {
+			logrus.Warnf("No GUI session in the guest, and the display is held by %s; "+
+				"a getty or plymouth still owning the console keeps the display server from starting", guiInfo.DrmMaster)
+		}
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaRemoteDisplay) && guiInfo.RemoteDisplayServer != "" {
+		logrus.Warnf("The guest also exports its display with %s; a session opened over it may conflict with the display of the VM, "+
+			"e.g., GNOME does not let the same user be logged in on both", guiInfo.RemoteDisplayServer)
+	}
+	offerGuestStreaming(guiInfo)
+	if guiInfo.SessionActive && guiInfo.DisplayServer == "Wayland" && guiInfo.Resolution == "" &&
+		!slices.ContainsFunc(waylandOutputTools, guiInfo.ToolAvailable) {
+		logrus.Warnf("The resolution of the guest session is unknown, as none of %s is installed in the guest",
+			strings.Join(waylandOutputTools, ", "))
+	}
+	if _, ok := guiInfo.IdleTime(); guiInfo.SessionActive && guiInfo.DisplayServer == "X11" && !ok &&
+		!slices.ContainsFunc(x11IdleTools, guiInfo.ToolAvailable) {
+		logrus.Infof("The idle time of the guest session is unknown, install %s in the guest", strings.Join(x11IdleTools, " or "))
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaFontconfig) && !guiInfo.FontconfigReady && guiInfo.SessionActive {
+		logrus.Warn("The font cache of the guest is not built yet, applications may render with fallback fonts; run `fc-cache` in the guest")
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaDynamicResolution) && guiInfo.SessionActive && !guiInfo.DynamicResolutionSupported {
+		logrus.Info("The guest does not resize its display to the viewer window, resizing the window letterboxes the display; " +
+			"run spice-vdagent in an X11 session, or a desktop that follows the display size (e.g., GNOME or KDE)")
+	}
+	if guiInfo.SessionActive && !guiInfo.AbsolutePointer {
+		logrus.Warn("No absolute pointing device found in the guest, the mouse will be in relative mode; add a tablet device (e.g., `-device usb-tablet`)")
+	}
+	// The mouse mode is only negotiated once a client is connected
+	if inst.GUI.MouseMode == "server" && inst.GUI.ConnectedClients > 0 && guiInfo.Spice != nil && guiInfo.Spice.AgentRunning {
+		logrus.Warn("SPICE is in server mouse mode although spice-vdagentd is running in the guest, the pointer may be offset; " +
+			"check that the spice-vdagent session client is running")
+	}
+	if guiInfo.HasSchema(guestagentapi.GUISchemaClipboardOwner) && guiInfo.Spice != nil && guiInfo.Spice.AgentRunning &&
+		slices.Contains(clipboardManagers, guiInfo.Spice.ClipboardSelectionOwner) {
+		logrus.Warnf("The clipboard of the guest is held by the %s clipboard manager, which can take the selection back from spice-vdagent "+
+			"so that text copied on the host is not pasted in the guest; disable its clipboard synchronization", guiInfo.Spice.ClipboardSelectionOwner)
+	}
+}
+
+// Detection tools of the guest agent, any of which is enough
+var (
+	waylandOutputTools = []string{"wlr-randr", "swaymsg", "kscreen-doctor"}
+	x11IdleTools       = []string{"xprintidle", "xssstate"}
+)
+
+// clipboardManagers are the process names of the clipboard managers that keep owning the CLIPBOARD selection
+var clipboardManagers = []string{"clipit", "clipmenud", "copyq", "diodon", "gpaste-daemon", "greenclip", "parcellite", "xfce4-clipman"}
+
+// explainGUIUnavailable appends the view of the guest agent to a failed show-gui precondition,
+// as the guest may know better why there is no GUI, e.g., when it booted to multi-user.target
+func explainGUIUnavailable(ctx context.Context, instName string, err error) error {
+	inst, guiInfo := runningGuestGUIInfo(ctx, instName)
+	offerGuestVNC(guiInfo)
+	offerGuestStreaming(guiInfo)
+	if view := guestGUIView(inst, guiInfo); view != "" {
+		return fmt.Errorf("%w; the guest agent reports: %s", err, view)
+	}
+	return err
+}
+
+// runningGuestGUIInfo returns the GUI information reported by the guest agent of a running instance,
+// or nil if the instance is not running or the guest agent does not answer
+func runningGuestGUIInfo(ctx context.Context, instName string) (*limatype.Instance, *guestagentapi.GUIInfo) {
+	inst, err := store.Inspect(ctx, instName)
+	if err != nil || inst.Status != limatype.StatusRunning {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	guiInfo, err := guestGUIInfo(ctx, inst, &guestagentapi.GUIInfoRequest{})
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to get GUI information from the guest")
+		return nil, nil
+	}
+	return inst, guiInfo
+}
+
+// guestGUIView summarizes the state of the GUI as seen from the guest, to complement the host-side errors of show-gui
+func guestGUIView(inst *limatype.Instance, guiInfo *guestagentapi.GUIInfo) string {
+	if guiInfo == nil {
+		return ""
+	}
+	parts := []string{"display server " + orDash(guiInfo.DisplayServer)}
+	if guiInfo.SessionActive {
+		parts = append(parts, "session active")
+		if guiInfo.Resolution != "" {
+			parts = append(parts, "resolution "+guiInfo.Resolution)
+		}
+	} else {
+		parts = append(parts, "session inactive")
+	}
+	if reason := noGraphicalTargetReason(guiInfo); reason != "" {
+		parts = append(parts, reason)
+	}
+	// FreeBSD guests do not report the driver
+	if guiInfo.HasSchema(guestagentapi.GUISchemaVirtioGPU) && !guiInfo.VirtioGpuLoaded && *inst.Config.OS == limatype.LINUX {
+		parts = append(parts, "virtio_gpu not loaded")
+	}
+	if !guiInfo.SessionActive && guiInfo.DrmMaster != "" {
+		parts = append(parts, "display held by "+guiInfo.DrmMaster)
+	}
+	if guiInfo.DriDevice != "" {
+		parts = append(parts, fmt.Sprintf("display server on %s (%s)", guiInfo.DriDevice, orDash(guiInfo.DriDeviceDriver)))
+	}
+	if guiInfo.StreamingHost != "" {
+		parts = append(parts, "streamed by "+guiInfo.StreamingHost)
+	}
+	parts = append(parts, guiInfo.Warnings...)
+	return strings.Join(parts, ", ")
+}
+
+// offerGuestVNC suggests the VNC server running in the guest (e.g., wayvnc for a headless Wayland session)
+// when the display of the instance cannot be opened
+func offerGuestVNC(guiInfo *guestagentapi.GUIInfo) {
+	if guiInfo == nil || guiInfo.VncEndpoint == "" {
+		return
+	}
+	_, port, err := net.SplitHostPort(guiInfo.VncEndpoint)
+	if err != nil {
+		return
+	}
+	// Guest ports are forwarded to the same port on the host
+	logrus.Infof("A VNC server is running in the guest on %s, connect a VNC client to vnc://%s",
+		guiInfo.VncEndpoint, net.JoinHostPort("127.0.0.1", port))
+}
+
+// offerGuestStreaming suggests Moonlight when a game streaming host such as Sunshine runs in the guest,
+// as it streams the session with hardware video encoding, which suits games better than SPICE
+func offerGuestStreaming(guiInfo *guestagentapi.GUIInfo) {
+	if guiInfo == nil || !guiInfo.HasSchema(guestagentapi.GUISchemaStreamingHost) || guiInfo.StreamingHost == "" {
+		return
+	}
+	_, port, err := net.SplitHostPort(guiInfo.StreamingEndpoint)
+	if err != nil {
+		logrus.Infof("%s is running in the guest, Moonlight can stream the guest session instead of SPICE", guiInfo.StreamingHost)
+		return
+	}
+	// Guest ports are forwarded to the same port on the host
+	logrus.Infof("%s is running in the guest, Moonlight can stream the guest session instead of SPICE; add the PC %s in Moonlight",
+		guiInfo.StreamingHost, net.JoinHostPort("127.0.0.1", port))
+}
+
+// noGraphicalTargetReason explains why no GUI session can appear when a systemd guest did not reach graphical.target
+func noGraphicalTargetReason(guiInfo *guestagentapi.GUIInfo) string {
+	if guiInfo.SystemdDefaultTarget == "" || guiInfo.ActiveGraphicalTarget {
+		return ""
+	}
+	return fmt.Sprintf("guest booted to %s, not graphical.target; no GUI will appear "+
+		"(run `sudo systemctl set-default graphical.target` in the guest and reboot)", guiInfo.SystemdDefaultTarget)
+}
+
+// guestGUIInfo asks the host agent for the GUI information reported by the guest agent.
+func guestGUIInfo(ctx context.Context, inst *limatype.Instance, req *guestagentapi.GUIInfoRequest) (*guestagentapi.GUIInfo, error) {
+	haClient, err := hostagentclient.NewHostAgentClient(filepath.Join(inst.Dir, filenames.HostAgentSock))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return haClient.GUIInfo(ctx, req)
+}
+
+// spiceConnection resolves the SPICE connection details of a running instance.
+// QEMU is asked for the live address first, the display string is parsed as a fallback.
+func spiceConnection(ctx context.Context, inst *limatype.Instance) (*spiceclient.Connection, error) {
+	display := *inst.Config.Video.Display
+	qmpSock, err := store.QMPSocketPath(inst)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := spiceclient.DiscoverFromInstance(inst.Dir, qmpSock)
+	if errors.Is(err, spiceclient.ErrNoPort) {
+		// The configuration has no port either, e.g., port=0
+		return nil, fmt.Errorf("SPICE server of instance %q has no port, set a port other than 0 in video.display: %w", inst.Name, err)
+	}
+	if err != nil {
+		logrus.WithError(err).Debug("Falling back to the SPICE display configuration")
+		if conn, err = spiceclient.GetConnectionInfo(display); err != nil {
+			return nil, fmt.Errorf("failed to get SPICE connection info: %w", err)
+		}
+	}
+	// QEMU does not report the password
+	if conn.Password, err = spicePassword(ctx, inst); err != nil {
+		return nil, err
+	}
+
+	if inst.Config.Video.SPICE.Audio != nil && *inst.Config.Video.SPICE.Audio {
+		conn.Audio = true
+	}
+	return conn, nil
+}
+
+// savedSPICEConnection returns the connection saved by the last show-gui of a running instance.
+// The password is not saved, it is resolved from the configuration again.
+func savedSPICEConnection(ctx context.Context, inst *limatype.Instance) (*spiceclient.Connection, error) {
+	conn, err := spiceclient.LoadConnection(filepath.Join(inst.Dir, filenames.SPICEConnection))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no SPICE connection saved for instance %q, run `limactl show-gui %s` first", inst.Name, inst.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if conn.Password, err = spicePassword(ctx, inst); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// spicePassword returns the SPICE password of the instance, or an empty string.
+// A password set by `limactl spice-password` takes precedence over the configured one.
+func spicePassword(ctx context.Context, inst *limatype.Instance) (string, error) {
+	if rotated, err := spiceclient.RotatedPassword(inst.Dir); err != nil || rotated != "" {
+		return rotated, err
+	}
+	if ref := inst.Config.Video.SPICE.PasswordRef; ref != nil && *ref != "" {
+		// Takes precedence over an inline password of video.display
+		return spiceclient.ResolvePasswordRef(ctx, *ref)
+	}
+	if cfgConn, err := spiceclient.GetConnectionInfo(*inst.Config.Video.Display); err == nil {
+		return cfgConn.Password, nil
+	}
+	return "", nil
+}
+
 func showGUIBashComplete(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 	// Only complete running instances with GUI support
 	instances, directive := bashCompleteInstanceNames(cmd)
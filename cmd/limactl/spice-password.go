@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sethvargo/go-password/password"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
+	"github.com/lima-vm/lima/v2/pkg/store"
+)
+
+func newSPICEPasswordCommand() *cobra.Command {
+	spicePasswordCmd := &cobra.Command{
+		Use:   "spice-password INSTANCE",
+		Short: "Change the SPICE password of a running instance.",
+		Long: `Change the SPICE password of a running instance without restarting it.
+
+A random password is generated and printed, unless --password-stdin is specified.
+The viewers already connected stay connected; "limactl show-gui" uses the new password
+until the instance is stopped, when the configured password applies again.
+
+With --expire, the password expires after the given duration, and new connections are refused
+until the password is changed again.`,
+		Example: `  Rotate the password, expiring in an hour:
+  $ limactl spice-password --expire 1h default
+
+  Set the password read from a file:
+  $ limactl spice-password --password-stdin default < password.txt`,
+		Args:              WrapArgsError(cobra.ExactArgs(1)),
+		RunE:              spicePasswordAction,
+		ValidArgsFunction: showGUIBashComplete,
+		GroupID:           advancedCommand,
+	}
+	spicePasswordCmd.Flags().Duration("expire", 0, "Expire the password after this duration (default: never)")
+	spicePasswordCmd.Flags().Bool("password-stdin", false, "Read the password from the first line of stdin")
+	return spicePasswordCmd
+}
+
+func spicePasswordAction(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	expire, err := cmd.Flags().GetDuration("expire")
+	if err != nil {
+		return err
+	}
+	if expire < 0 {
+		return errors.New("--expire must not be negative")
+	}
+	passwordStdin, err := cmd.Flags().GetBool("password-stdin")
+	if err != nil {
+		return err
+	}
+	inst, err := store.Inspect(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if inst.Status != limatype.StatusRunning {
+		return fmt.Errorf("instance %q is not running", inst.Name)
+	}
+	if !isSPICEDisplay(inst) {
+		display := "none"
+		if inst.Config.Video.Display != nil {
+			display = *inst.Config.Video.Display
+		}
+		return fmt.Errorf("instance %q has no SPICE display (video.display: %q)", inst.Name, display)
+	}
+	qmpSock, err := store.QMPSocketPath(inst)
+	if err != nil {
+		return err
+	}
+
+	var newPassword string
+	if passwordStdin {
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read the password from stdin: %w", err)
+		}
+		newPassword = strings.TrimRight(line, "\r\n")
+		if newPassword == "" {
+			return errors.New("the password read from stdin is empty")
+		}
+	} else {
+		// Avoid any special symbols, to make it easier to copy/paste, as with the VNC password
+		if newPassword, err = password.Generate(16, 4, 0, false, false); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := spiceclient.SetPassword(ctx, qmpSock, newPassword, expire); err != nil {
+		return fmt.Errorf("failed to change the SPICE password of instance %q: %w", inst.Name, err)
+	}
+	if err := os.WriteFile(filepath.Join(inst.Dir, filenames.SPICEPasswordFile), []byte(newPassword), 0o600); err != nil {
+		return err
+	}
+
+	if expire > 0 {
+		logrus.Infof("Changed the SPICE password of instance %q, expiring at %s", inst.Name, time.Now().Add(expire).Format(time.DateTime))
+	} else {
+		logrus.Infof("Changed the SPICE password of instance %q", inst.Name)
+	}
+	if !passwordStdin {
+		fmt.Fprintln(cmd.OutOrStdout(), newPassword)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ package driver
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	"github.com/lima-vm/lima/v2/pkg/limatype"
@@ -50,8 +51,30 @@ type GUI interface {
 
 	ChangeDisplayPassword(ctx context.Context, password string) error
 	DisplayConnection(ctx context.Context) (string, error)
+
+	// EnableClipboard starts sharing the clipboard with the running VM.
+	// It returns an error wrapping ErrRestartRequired when the device cannot be attached to a running VM.
+	EnableClipboard(ctx context.Context) error
+
+	// HostMonitors returns the monitors attached to the host that the display window can be placed on,
+	// the primary monitor first. It returns nil when the driver does not place its window itself.
+	HostMonitors() []MonitorInfo
 }
 
+// MonitorInfo is a monitor attached to the host.
+// The frame is in points, in the coordinates of the desktop, with the origin at the primary monitor.
+type MonitorInfo struct {
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Width       float64 `json:"width"`
+	Height      float64 `json:"height"`
+	ScaleFactor float64 `json:"scaleFactor"` // Pixels per point, e.g., 2 on Retina displays
+	RefreshRate float64 `json:"refreshRate"` // Maximum refresh rate in Hz, e.g., 120 on ProMotion displays; 0 if unknown
+}
+
+// ErrRestartRequired is returned when a change only takes effect after restarting the VM
+var ErrRestartRequired = errors.New("restart required")
+
 // SnapshotManager defines operations for managing snapshots.
 type SnapshotManager interface {
 	CreateSnapshot(ctx context.Context, tag string) error
@@ -105,10 +128,22 @@ type Info struct {
 	VirtioPort  string         `json:"virtioPort"`
 	InstanceDir string         `json:"instanceDir,omitempty"`
 	Features    DriverFeatures `json:"features"`
+	// GraphicsDeviceActive reports whether the running VM has a graphics device attached.
+	// Nil when the driver does not track it, or when the VM has not been started.
+	GraphicsDeviceActive *bool `json:"graphicsDeviceActive,omitempty"`
+	// GraphicsDeviceError is the reason the graphics device could not be attached
+	GraphicsDeviceError string `json:"graphicsDeviceError,omitempty"`
+	// InputDevices names the input devices attached to the running VM, e.g., "usb-keyboard".
+	// Empty when the driver does not track them, or when the VM has not been started.
+	InputDevices []string `json:"inputDevices,omitempty"`
+	// DisplayResolution is the resolution of the display attached to the running VM, e.g., "1920x1200".
+	// Empty when the driver does not choose it, or when the VM has not been started.
+	DisplayResolution string `json:"displayResolution,omitempty"`
 }
 
 type DriverFeatures struct {
 	CanRunGUI            bool `json:"canRunGui,omitempty"`
+	CanShareClipboard    bool `json:"canShareClipboard,omitempty"` // Whether the display can exchange the clipboard with the host
 	DynamicSSHAddress    bool `json:"dynamicSSHAddress"`
 	StaticSSHPort        bool `json:"staticSSHPort"`
 	SkipSocketForwarding bool `json:"skipSocketForwarding"`
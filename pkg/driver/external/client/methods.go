@@ -7,9 +7,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/lima-vm/lima/v2/pkg/driver"
@@ -163,6 +166,44 @@ func (d *DriverClient) DisplayConnection(ctx context.Context) (string, error) {
 	return resp.Connection, nil
 }
 
+func (d *DriverClient) EnableClipboard(ctx context.Context) error {
+	d.logger.Debug("Enabling clipboard sharing for the driver instance")
+
+	_, err := d.DriverSvc.EnableClipboard(ctx, &emptypb.Empty{})
+	if err != nil {
+		d.logger.Errorf("Failed to enable clipboard sharing: %v", err)
+		if status.Code(err) == codes.FailedPrecondition {
+			return fmt.Errorf("%w: %s", driver.ErrRestartRequired, status.Convert(err).Message())
+		}
+		return err
+	}
+
+	d.logger.Debug("Clipboard sharing enabled successfully")
+	return nil
+}
+
+func (d *DriverClient) HostMonitors() []driver.MonitorInfo {
+	d.logger.Debug("Getting host monitors")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := d.DriverSvc.HostMonitors(ctx, &emptypb.Empty{})
+	if err != nil {
+		d.logger.Errorf("Failed to get host monitors: %v", err)
+		return nil
+	}
+
+	var monitors []driver.MonitorInfo
+	if err := json.Unmarshal(resp.MonitorsJson, &monitors); err != nil {
+		d.logger.Errorf("Failed to unmarshal host monitors: %v", err)
+		return nil
+	}
+
+	d.logger.Debugf("Host monitors retrieved: %+v", monitors)
+	return monitors
+}
+
 func (d *DriverClient) CreateSnapshot(ctx context.Context, tag string) error {
 	d.logger.Debugf("Creating snapshot with tag: %s", tag)
 
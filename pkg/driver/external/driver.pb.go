@@ -338,6 +338,50 @@ func (x *GetDisplayConnectionResponse) GetConnection() string {
 	return ""
 }
 
+type HostMonitorsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MonitorsJson  []byte                 `protobuf:"bytes,1,opt,name=monitors_json,json=monitorsJson,proto3" json:"monitors_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostMonitorsResponse) Reset() {
+	*x = HostMonitorsResponse{}
+	mi := &file_driver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostMonitorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostMonitorsResponse) ProtoMessage() {}
+
+func (x *HostMonitorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostMonitorsResponse.ProtoReflect.Descriptor instead.
+func (*HostMonitorsResponse) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HostMonitorsResponse) GetMonitorsJson() []byte {
+	if x != nil {
+		return x.MonitorsJson
+	}
+	return nil
+}
+
 type CreateSnapshotRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
@@ -347,7 +391,7 @@ type CreateSnapshotRequest struct {
 
 func (x *CreateSnapshotRequest) Reset() {
 	*x = CreateSnapshotRequest{}
-	mi := &file_driver_proto_msgTypes[7]
+	mi := &file_driver_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -359,7 +403,7 @@ func (x *CreateSnapshotRequest) String() string {
 func (*CreateSnapshotRequest) ProtoMessage() {}
 
 func (x *CreateSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_driver_proto_msgTypes[7]
+	mi := &file_driver_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -372,7 +416,7 @@ func (x *CreateSnapshotRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSnapshotRequest.ProtoReflect.Descriptor instead.
 func (*CreateSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_driver_proto_rawDescGZIP(), []int{7}
+	return file_driver_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CreateSnapshotRequest) GetTag() string {
@@ -391,7 +435,7 @@ type ApplySnapshotRequest struct {
 
 func (x *ApplySnapshotRequest) Reset() {
 	*x = ApplySnapshotRequest{}
-	mi := &file_driver_proto_msgTypes[8]
+	mi := &file_driver_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -403,7 +447,7 @@ func (x *ApplySnapshotRequest) String() string {
 func (*ApplySnapshotRequest) ProtoMessage() {}
 
 func (x *ApplySnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_driver_proto_msgTypes[8]
+	mi := &file_driver_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -416,7 +460,7 @@ func (x *ApplySnapshotRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ApplySnapshotRequest.ProtoReflect.Descriptor instead.
 func (*ApplySnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_driver_proto_rawDescGZIP(), []int{8}
+	return file_driver_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ApplySnapshotRequest) GetTag() string {
@@ -435,7 +479,7 @@ type DeleteSnapshotRequest struct {
 
 func (x *DeleteSnapshotRequest) Reset() {
 	*x = DeleteSnapshotRequest{}
-	mi := &file_driver_proto_msgTypes[9]
+	mi := &file_driver_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -447,7 +491,7 @@ func (x *DeleteSnapshotRequest) String() string {
 func (*DeleteSnapshotRequest) ProtoMessage() {}
 
 func (x *DeleteSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_driver_proto_msgTypes[9]
+	mi := &file_driver_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -460,7 +504,7 @@ func (x *DeleteSnapshotRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteSnapshotRequest.ProtoReflect.Descriptor instead.
 func (*DeleteSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_driver_proto_rawDescGZIP(), []int{9}
+	return file_driver_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeleteSnapshotRequest) GetTag() string {
@@ -479,7 +523,7 @@ type ListSnapshotsResponse struct {
 
 func (x *ListSnapshotsResponse) Reset() {
 	*x = ListSnapshotsResponse{}
-	mi := &file_driver_proto_msgTypes[10]
+	mi := &file_driver_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -491,7 +535,7 @@ func (x *ListSnapshotsResponse) String() string {
 func (*ListSnapshotsResponse) ProtoMessage() {}
 
 func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_driver_proto_msgTypes[10]
+	mi := &file_driver_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -504,7 +548,7 @@ func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
 func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
-	return file_driver_proto_rawDescGZIP(), []int{10}
+	return file_driver_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ListSnapshotsResponse) GetSnapshots() string {
@@ -523,7 +567,7 @@ type ForwardGuestAgentResponse struct {
 
 func (x *ForwardGuestAgentResponse) Reset() {
 	*x = ForwardGuestAgentResponse{}
-	mi := &file_driver_proto_msgTypes[11]
+	mi := &file_driver_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -535,7 +579,7 @@ func (x *ForwardGuestAgentResponse) String() string {
 func (*ForwardGuestAgentResponse) ProtoMessage() {}
 
 func (x *ForwardGuestAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_driver_proto_msgTypes[11]
+	mi := &file_driver_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -548,7 +592,7 @@ func (x *ForwardGuestAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ForwardGuestAgentResponse.ProtoReflect.Descriptor instead.
 func (*ForwardGuestAgentResponse) Descriptor() ([]byte, []int) {
-	return file_driver_proto_rawDescGZIP(), []int{11}
+	return file_driver_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ForwardGuestAgentResponse) GetShouldForward() bool {
@@ -582,7 +626,9 @@ const file_driver_proto_rawDesc = "" +
 	"\x1cGetDisplayConnectionResponse\x12\x1e\n" +
 	"\n" +
 	"connection\x18\x01 \x01(\tR\n" +
-	"connection\")\n" +
+	"connection\";\n" +
+	"\x14HostMonitorsResponse\x12#\n" +
+	"\rmonitors_json\x18\x01 \x01(\fR\fmonitorsJson\")\n" +
 	"\x15CreateSnapshotRequest\x12\x10\n" +
 	"\x03tag\x18\x01 \x01(\tR\x03tag\"(\n" +
 	"\x14ApplySnapshotRequest\x12\x10\n" +
@@ -592,7 +638,8 @@ const file_driver_proto_rawDesc = "" +
 	"\x15ListSnapshotsResponse\x12\x1c\n" +
 	"\tsnapshots\x18\x01 \x01(\tR\tsnapshots\"B\n" +
 	"\x19ForwardGuestAgentResponse\x12%\n" +
-	"\x0eshould_forward\x18\x01 \x01(\bR\rshouldForward2\xf2\t\n" +
+	"\x0eshould_forward\x18\x01 \x01(\bR\rshouldForward2\xf4\n" +
+	"\n" +
 	"\x06Driver\x12:\n" +
 	"\bValidate\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x128\n" +
 	"\x06Create\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12<\n" +
@@ -604,7 +651,9 @@ const file_driver_proto_rawDesc = "" +
 	"\vBootScripts\x12\x16.google.protobuf.Empty\x1a\x14.BootScriptsResponse\x128\n" +
 	"\x06RunGUI\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12N\n" +
 	"\x15ChangeDisplayPassword\x12\x1d.ChangeDisplayPasswordRequest\x1a\x16.google.protobuf.Empty\x12M\n" +
-	"\x14GetDisplayConnection\x12\x16.google.protobuf.Empty\x1a\x1d.GetDisplayConnectionResponse\x12@\n" +
+	"\x14GetDisplayConnection\x12\x16.google.protobuf.Empty\x1a\x1d.GetDisplayConnectionResponse\x12A\n" +
+	"\x0fEnableClipboard\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x12=\n" +
+	"\fHostMonitors\x12\x16.google.protobuf.Empty\x1a\x15.HostMonitorsResponse\x12@\n" +
 	"\x0eCreateSnapshot\x12\x16.CreateSnapshotRequest\x1a\x16.google.protobuf.Empty\x12>\n" +
 	"\rApplySnapshot\x12\x15.ApplySnapshotRequest\x1a\x16.google.protobuf.Empty\x12@\n" +
 	"\x0eDeleteSnapshot\x12\x16.DeleteSnapshotRequest\x1a\x16.google.protobuf.Empty\x12?\n" +
@@ -629,7 +678,7 @@ func file_driver_proto_rawDescGZIP() []byte {
 	return file_driver_proto_rawDescData
 }
 
-var file_driver_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_driver_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_driver_proto_goTypes = []any{
 	(*BootScriptsResponse)(nil),          // 0: BootScriptsResponse
 	(*SSHAddressResponse)(nil),           // 1: SSHAddressResponse
@@ -638,58 +687,63 @@ var file_driver_proto_goTypes = []any{
 	(*SetConfigRequest)(nil),             // 4: SetConfigRequest
 	(*ChangeDisplayPasswordRequest)(nil), // 5: ChangeDisplayPasswordRequest
 	(*GetDisplayConnectionResponse)(nil), // 6: GetDisplayConnectionResponse
-	(*CreateSnapshotRequest)(nil),        // 7: CreateSnapshotRequest
-	(*ApplySnapshotRequest)(nil),         // 8: ApplySnapshotRequest
-	(*DeleteSnapshotRequest)(nil),        // 9: DeleteSnapshotRequest
-	(*ListSnapshotsResponse)(nil),        // 10: ListSnapshotsResponse
-	(*ForwardGuestAgentResponse)(nil),    // 11: ForwardGuestAgentResponse
-	nil,                                  // 12: BootScriptsResponse.ScriptsEntry
-	(*emptypb.Empty)(nil),                // 13: google.protobuf.Empty
+	(*HostMonitorsResponse)(nil),         // 7: HostMonitorsResponse
+	(*CreateSnapshotRequest)(nil),        // 8: CreateSnapshotRequest
+	(*ApplySnapshotRequest)(nil),         // 9: ApplySnapshotRequest
+	(*DeleteSnapshotRequest)(nil),        // 10: DeleteSnapshotRequest
+	(*ListSnapshotsResponse)(nil),        // 11: ListSnapshotsResponse
+	(*ForwardGuestAgentResponse)(nil),    // 12: ForwardGuestAgentResponse
+	nil,                                  // 13: BootScriptsResponse.ScriptsEntry
+	(*emptypb.Empty)(nil),                // 14: google.protobuf.Empty
 }
 var file_driver_proto_depIdxs = []int32{
-	12, // 0: BootScriptsResponse.scripts:type_name -> BootScriptsResponse.ScriptsEntry
-	13, // 1: Driver.Validate:input_type -> google.protobuf.Empty
-	13, // 2: Driver.Create:input_type -> google.protobuf.Empty
-	13, // 3: Driver.CreateDisk:input_type -> google.protobuf.Empty
-	13, // 4: Driver.Start:input_type -> google.protobuf.Empty
-	13, // 5: Driver.Stop:input_type -> google.protobuf.Empty
-	13, // 6: Driver.Delete:input_type -> google.protobuf.Empty
-	13, // 7: Driver.BootScripts:input_type -> google.protobuf.Empty
-	13, // 8: Driver.RunGUI:input_type -> google.protobuf.Empty
+	13, // 0: BootScriptsResponse.scripts:type_name -> BootScriptsResponse.ScriptsEntry
+	14, // 1: Driver.Validate:input_type -> google.protobuf.Empty
+	14, // 2: Driver.Create:input_type -> google.protobuf.Empty
+	14, // 3: Driver.CreateDisk:input_type -> google.protobuf.Empty
+	14, // 4: Driver.Start:input_type -> google.protobuf.Empty
+	14, // 5: Driver.Stop:input_type -> google.protobuf.Empty
+	14, // 6: Driver.Delete:input_type -> google.protobuf.Empty
+	14, // 7: Driver.BootScripts:input_type -> google.protobuf.Empty
+	14, // 8: Driver.RunGUI:input_type -> google.protobuf.Empty
 	5,  // 9: Driver.ChangeDisplayPassword:input_type -> ChangeDisplayPasswordRequest
-	13, // 10: Driver.GetDisplayConnection:input_type -> google.protobuf.Empty
-	7,  // 11: Driver.CreateSnapshot:input_type -> CreateSnapshotRequest
-	8,  // 12: Driver.ApplySnapshot:input_type -> ApplySnapshotRequest
-	9,  // 13: Driver.DeleteSnapshot:input_type -> DeleteSnapshotRequest
-	13, // 14: Driver.ListSnapshots:input_type -> google.protobuf.Empty
-	13, // 15: Driver.ForwardGuestAgent:input_type -> google.protobuf.Empty
-	13, // 16: Driver.GuestAgentConn:input_type -> google.protobuf.Empty
-	4,  // 17: Driver.Configure:input_type -> SetConfigRequest
-	13, // 18: Driver.Info:input_type -> google.protobuf.Empty
-	13, // 19: Driver.SSHAddress:input_type -> google.protobuf.Empty
-	13, // 20: Driver.AdditionalSetupForSSH:input_type -> google.protobuf.Empty
-	13, // 21: Driver.Validate:output_type -> google.protobuf.Empty
-	13, // 22: Driver.Create:output_type -> google.protobuf.Empty
-	13, // 23: Driver.CreateDisk:output_type -> google.protobuf.Empty
-	3,  // 24: Driver.Start:output_type -> StartResponse
-	13, // 25: Driver.Stop:output_type -> google.protobuf.Empty
-	13, // 26: Driver.Delete:output_type -> google.protobuf.Empty
-	0,  // 27: Driver.BootScripts:output_type -> BootScriptsResponse
-	13, // 28: Driver.RunGUI:output_type -> google.protobuf.Empty
-	13, // 29: Driver.ChangeDisplayPassword:output_type -> google.protobuf.Empty
-	6,  // 30: Driver.GetDisplayConnection:output_type -> GetDisplayConnectionResponse
-	13, // 31: Driver.CreateSnapshot:output_type -> google.protobuf.Empty
-	13, // 32: Driver.ApplySnapshot:output_type -> google.protobuf.Empty
-	13, // 33: Driver.DeleteSnapshot:output_type -> google.protobuf.Empty
-	10, // 34: Driver.ListSnapshots:output_type -> ListSnapshotsResponse
-	11, // 35: Driver.ForwardGuestAgent:output_type -> ForwardGuestAgentResponse
-	13, // 36: Driver.GuestAgentConn:output_type -> google.protobuf.Empty
-	13, // 37: Driver.Configure:output_type -> google.protobuf.Empty
-	2,  // 38: Driver.Info:output_type -> InfoResponse
-	1,  // 39: Driver.SSHAddress:output_type -> SSHAddressResponse
-	13, // 40: Driver.AdditionalSetupForSSH:output_type -> google.protobuf.Empty
-	21, // [21:41] is the sub-list for method output_type
-	1,  // [1:21] is the sub-list for method input_type
+	14, // 10: Driver.GetDisplayConnection:input_type -> google.protobuf.Empty
+	14, // 11: Driver.EnableClipboard:input_type -> google.protobuf.Empty
+	14, // 12: Driver.HostMonitors:input_type -> google.protobuf.Empty
+	8,  // 13: Driver.CreateSnapshot:input_type -> CreateSnapshotRequest
+	9,  // 14: Driver.ApplySnapshot:input_type -> ApplySnapshotRequest
+	10, // 15: Driver.DeleteSnapshot:input_type -> DeleteSnapshotRequest
+	14, // 16: Driver.ListSnapshots:input_type -> google.protobuf.Empty
+	14, // 17: Driver.ForwardGuestAgent:input_type -> google.protobuf.Empty
+	14, // 18: Driver.GuestAgentConn:input_type -> google.protobuf.Empty
+	4,  // 19: Driver.Configure:input_type -> SetConfigRequest
+	14, // 20: Driver.Info:input_type -> google.protobuf.Empty
+	14, // 21: Driver.SSHAddress:input_type -> google.protobuf.Empty
+	14, // 22: Driver.AdditionalSetupForSSH:input_type -> google.protobuf.Empty
+	14, // 23: Driver.Validate:output_type -> google.protobuf.Empty
+	14, // 24: Driver.Create:output_type -> google.protobuf.Empty
+	14, // 25: Driver.CreateDisk:output_type -> google.protobuf.Empty
+	3,  // 26: Driver.Start:output_type -> StartResponse
+	14, // 27: Driver.Stop:output_type -> google.protobuf.Empty
+	14, // 28: Driver.Delete:output_type -> google.protobuf.Empty
+	0,  // 29: Driver.BootScripts:output_type -> BootScriptsResponse
+	14, // 30: Driver.RunGUI:output_type -> google.protobuf.Empty
+	14, // 31: Driver.ChangeDisplayPassword:output_type -> google.protobuf.Empty
+	6,  // 32: Driver.GetDisplayConnection:output_type -> GetDisplayConnectionResponse
+	14, // 33: Driver.EnableClipboard:output_type -> google.protobuf.Empty
+	7,  // 34: Driver.HostMonitors:output_type -> HostMonitorsResponse
+	14, // 35: Driver.CreateSnapshot:output_type -> google.protobuf.Empty
+	14, // 36: Driver.ApplySnapshot:output_type -> google.protobuf.Empty
+	14, // 37: Driver.DeleteSnapshot:output_type -> google.protobuf.Empty
+	11, // 38: Driver.ListSnapshots:output_type -> ListSnapshotsResponse
+	12, // 39: Driver.ForwardGuestAgent:output_type -> ForwardGuestAgentResponse
+	14, // 40: Driver.GuestAgentConn:output_type -> google.protobuf.Empty
+	14, // 41: Driver.Configure:output_type -> google.protobuf.Empty
+	2,  // 42: Driver.Info:output_type -> InfoResponse
+	1,  // 43: Driver.SSHAddress:output_type -> SSHAddressResponse
+	14, // 44: Driver.AdditionalSetupForSSH:output_type -> google.protobuf.Empty
+	23, // [23:45] is the sub-list for method output_type
+	1,  // [1:23] is the sub-list for method input_type
 	1,  // [1:1] is the sub-list for extension type_name
 	1,  // [1:1] is the sub-list for extension extendee
 	0,  // [0:1] is the sub-list for field type_name
@@ -706,7 +760,7 @@ func file_driver_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_driver_proto_rawDesc), len(file_driver_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
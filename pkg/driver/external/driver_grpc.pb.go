@@ -30,6 +30,8 @@ const (
 	Driver_RunGUI_FullMethodName                = "/Driver/RunGUI"
 	Driver_ChangeDisplayPassword_FullMethodName = "/Driver/ChangeDisplayPassword"
 	Driver_GetDisplayConnection_FullMethodName  = "/Driver/GetDisplayConnection"
+	Driver_EnableClipboard_FullMethodName       = "/Driver/EnableClipboard"
+	Driver_HostMonitors_FullMethodName          = "/Driver/HostMonitors"
 	Driver_CreateSnapshot_FullMethodName        = "/Driver/CreateSnapshot"
 	Driver_ApplySnapshot_FullMethodName         = "/Driver/ApplySnapshot"
 	Driver_DeleteSnapshot_FullMethodName        = "/Driver/DeleteSnapshot"
@@ -56,6 +58,8 @@ type DriverClient interface {
 	RunGUI(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ChangeDisplayPassword(ctx context.Context, in *ChangeDisplayPasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetDisplayConnection(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetDisplayConnectionResponse, error)
+	EnableClipboard(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	HostMonitors(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostMonitorsResponse, error)
 	CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ApplySnapshot(ctx context.Context, in *ApplySnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
@@ -187,6 +191,26 @@ func (c *driverClient) GetDisplayConnection(ctx context.Context, in *emptypb.Emp
 	return out, nil
 }
 
+func (c *driverClient) EnableClipboard(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Driver_EnableClipboard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) HostMonitors(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostMonitorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostMonitorsResponse)
+	err := c.cc.Invoke(ctx, Driver_HostMonitors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *driverClient) CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -301,6 +325,8 @@ type DriverServer interface {
 	RunGUI(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	ChangeDisplayPassword(context.Context, *ChangeDisplayPasswordRequest) (*emptypb.Empty, error)
 	GetDisplayConnection(context.Context, *emptypb.Empty) (*GetDisplayConnectionResponse, error)
+	EnableClipboard(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	HostMonitors(context.Context, *emptypb.Empty) (*HostMonitorsResponse, error)
 	CreateSnapshot(context.Context, *CreateSnapshotRequest) (*emptypb.Empty, error)
 	ApplySnapshot(context.Context, *ApplySnapshotRequest) (*emptypb.Empty, error)
 	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*emptypb.Empty, error)
@@ -353,6 +379,12 @@ func (UnimplementedDriverServer) ChangeDisplayPassword(context.Context, *ChangeD
 func (UnimplementedDriverServer) GetDisplayConnection(context.Context, *emptypb.Empty) (*GetDisplayConnectionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetDisplayConnection not implemented")
 }
+func (UnimplementedDriverServer) EnableClipboard(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableClipboard not implemented")
+}
+func (UnimplementedDriverServer) HostMonitors(context.Context, *emptypb.Empty) (*HostMonitorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HostMonitors not implemented")
+}
 func (UnimplementedDriverServer) CreateSnapshot(context.Context, *CreateSnapshotRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateSnapshot not implemented")
 }
@@ -577,6 +609,42 @@ func _Driver_GetDisplayConnection_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Driver_EnableClipboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).EnableClipboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Driver_EnableClipboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).EnableClipboard(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_HostMonitors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).HostMonitors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Driver_HostMonitors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).HostMonitors(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Driver_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateSnapshotRequest)
 	if err := dec(in); err != nil {
@@ -800,6 +868,14 @@ var Driver_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDisplayConnection",
 			Handler:    _Driver_GetDisplayConnection_Handler,
 		},
+		{
+			MethodName: "EnableClipboard",
+			Handler:    _Driver_EnableClipboard_Handler,
+		},
+		{
+			MethodName: "HostMonitors",
+			Handler:    _Driver_HostMonitors_Handler,
+		},
 		{
 			MethodName: "CreateSnapshot",
 			Handler:    _Driver_CreateSnapshot_Handler,
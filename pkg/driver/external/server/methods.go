@@ -6,6 +6,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"maps"
 	"net"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/lima-vm/lima/v2/pkg/bicopy"
+	"github.com/lima-vm/lima/v2/pkg/driver"
 	pb "github.com/lima-vm/lima/v2/pkg/driver/external"
 	"github.com/lima-vm/lima/v2/pkg/limatype"
 	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
@@ -236,6 +238,31 @@ func (s *DriverServer) GetDisplayConnection(ctx context.Context, _ *emptypb.Empt
 	return &pb.GetDisplayConnectionResponse{Connection: conn}, nil
 }
 
+func (s *DriverServer) EnableClipboard(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	s.logger.Debug("Received EnableClipboard request")
+	err := s.driver.EnableClipboard(ctx)
+	if err != nil {
+		s.logger.Errorf("EnableClipboard failed: %v", err)
+		if errors.Is(err, driver.ErrRestartRequired) {
+			// Keep the error recognizable by the client across the process boundary
+			return &emptypb.Empty{}, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return &emptypb.Empty{}, err
+	}
+	s.logger.Debug("EnableClipboard succeeded")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *DriverServer) HostMonitors(_ context.Context, _ *emptypb.Empty) (*pb.HostMonitorsResponse, error) {
+	s.logger.Debug("Received HostMonitors request")
+	monitorsJSON, err := json.Marshal(s.driver.HostMonitors())
+	if err != nil {
+		s.logger.Errorf("Failed to marshal host monitors: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to marshal host monitors: %v", err)
+	}
+	return &pb.HostMonitorsResponse{MonitorsJson: monitorsJSON}, nil
+}
+
 func (s *DriverServer) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*emptypb.Empty, error) {
 	s.logger.Debugf("Received CreateSnapshot request with tag: %s", req.Tag)
 	err := s.driver.CreateSnapshot(ctx, req.Tag)
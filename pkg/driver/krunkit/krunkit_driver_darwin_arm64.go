@@ -287,6 +287,14 @@ func (l *LimaKrunkitDriver) DisplayConnection(_ context.Context) (string, error)
 	return "", errUnimplemented
 }
 
+func (l *LimaKrunkitDriver) EnableClipboard(_ context.Context) error {
+	return errUnimplemented
+}
+
+func (l *LimaKrunkitDriver) HostMonitors() []driver.MonitorInfo {
+	return nil
+}
+
 func (l *LimaKrunkitDriver) CreateSnapshot(_ context.Context, _ string) error {
 	return errUnimplemented
 }
@@ -38,6 +38,7 @@ import (
 	"github.com/lima-vm/lima/v2/pkg/networks/usernet"
 	"github.com/lima-vm/lima/v2/pkg/osutil"
 	"github.com/lima-vm/lima/v2/pkg/qemuimgutil"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
 	"github.com/lima-vm/lima/v2/pkg/store"
 )
 
@@ -245,6 +246,73 @@ func argValue(args []string, key string) (string, bool) {
 	return "", false
 }
 
+// spiceUnixScheme prefixes the video.display of a SPICE server listening on a Unix socket
+const spiceUnixScheme = "spice+unix://"
+
+// spiceUnixDisplay returns the video.display of a SPICE server listening on the socket of the instance directory,
+// where spiceclient.DiscoverFromInstance finds it without asking QEMU
+func spiceUnixDisplay(instDir string) string {
+	return spiceUnixScheme + filepath.Join(instDir, filenames.SPICESock)
+}
+
+// migrateSPICESocket moves the SPICE Unix socket of video.display to the instance directory.
+// "spice+unix" without a path, and sockets configured elsewhere by earlier versions, are rewritten;
+// abstract sockets (Linux) have no path, and are kept.
+func migrateSPICESocket(display, instDir string) (string, bool) {
+	want := spiceUnixDisplay(instDir)
+	switch {
+	case display == "spice+unix":
+		return want, true
+	case !strings.HasPrefix(display, spiceUnixScheme), display == want, strings.HasPrefix(display, spiceUnixScheme+"@"):
+		return display, false
+	}
+	conn, err := spiceclient.GetConnectionInfo(display)
+	if err == nil && conn.UnixPath == filepath.Join(instDir, filenames.SPICESock) {
+		// Percent-encoded
+		return display, false
+	}
+	return want, true
+}
+
+// spiceUnixArg returns the -spice argument of a SPICE server listening on the Unix socket of video.display.
+// Ticketing is only disabled for a socket file without a configured password: abstract sockets (Linux) are
+// reachable from every process of the network namespace, and refuse clients until a password is set over QMP.
+func spiceUnixArg(display string, hasPassword bool) (string, error) {
+	conn, err := spiceclient.GetConnectionInfo(display)
+	if err != nil {
+		return "", err
+	}
+	abstract := strings.HasPrefix(conn.UnixPath, "@")
+	if !abstract {
+		// QEMU does not remove the socket of a previous run
+		if err := removeStaleSocket(conn.UnixPath); err != nil {
+			return "", err
+		}
+	}
+	// Commas are escaped by doubling them
+	arg := "unix=on,addr=" + strings.ReplaceAll(conn.UnixPath, ",", ",,")
+	if !abstract && !hasPassword {
+		// The instance directory is only accessible by the user
+		arg += ",disable-ticketing=on"
+	}
+	return arg, nil
+}
+
+// removeStaleSocket removes the socket at path, and nothing else: the path comes from the user's configuration
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if fi.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("SPICE socket path %q exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
+
 // appendArgsIfNoConflict can be used for: -cpu, -machine, -m, -boot ...
 // appendArgsIfNoConflict cannot be used for: -drive, -cdrom, ...
 func appendArgsIfNoConflict(args []string, k, v string) []string {
@@ -878,7 +946,17 @@ func Cmdline(ctx context.Context, cfg Config) (exe string, args []string, err er
 			// use tablet to avoid double cursors
 			input = "tablet"
 		}
-		args = appendArgsIfNoConflict(args, "-display", display)
+		if strings.HasPrefix(display, spiceUnixScheme) {
+			hasPassword := y.Video.SPICE.PasswordRef != nil && *y.Video.SPICE.PasswordRef != ""
+			spiceArg, err := spiceUnixArg(display, hasPassword)
+			if err != nil {
+				return "", nil, err
+			}
+			// The SPICE server is not a display of QEMU, the default display is none with -spice
+			args = appendArgsIfNoConflict(args, "-spice", spiceArg)
+		} else {
+			args = appendArgsIfNoConflict(args, "-display", display)
+		}
 	}
 
 	if *y.Video.Display != "none" {
@@ -39,6 +39,7 @@ import (
 	"github.com/lima-vm/lima/v2/pkg/ptr"
 	"github.com/lima-vm/lima/v2/pkg/reflectutil"
 	"github.com/lima-vm/lima/v2/pkg/spiceclient"
+	"github.com/lima-vm/lima/v2/pkg/store"
 	"github.com/lima-vm/lima/v2/pkg/version/versionutil"
 )
 
@@ -193,6 +194,17 @@ func (l *LimaQemuDriver) FillConfig(_ context.Context, cfg *limatype.LimaYAML, f
 		cfg.VMOpts[limatype.QEMU] = opts
 	}
 
+	// The SPICE Unix socket is in the instance directory; migration of a socket configured elsewhere
+	if cfg.Video.Display != nil && filepath.Base(filePath) == filenames.LimaYAML {
+		if display, migrated := migrateSPICESocket(*cfg.Video.Display, instDir); migrated {
+			if strings.Contains(*cfg.Video.Display, "://") {
+				logrus.Warnf("The SPICE socket of `video.display: %s` is moved to %q, where limactl finds it; "+
+					"set `video.display: spice+unix` to silence this warning", *cfg.Video.Display, filepath.Join(instDir, filenames.SPICESock))
+			}
+			cfg.Video.Display = ptr.Of(display)
+		}
+	}
+
 	mountTypesUnsupported := make(map[string]struct{})
 	for _, f := range cfg.MountTypesUnsupported {
 		mountTypesUnsupported[f] = struct{}{}
@@ -249,6 +261,12 @@ func (l *LimaQemuDriver) Start(_ context.Context) (chan error, error) {
 		}
 	}()
 
+	// The files of a previous run survive `limactl stop -f` and crashes, e.g., a password set by `limactl spice-password`
+	// that show-gui would prefer over the configured one
+	if err := l.removeDisplayFiles(); err != nil {
+		return nil, err
+	}
+
 	qCfg := Config{
 		Name:         l.Instance.Name,
 		InstanceDir:  l.Instance.Dir,
@@ -541,7 +559,7 @@ func (l *LimaQemuDriver) getSPICEDisplayPort() (string, error) {
 	}
 
 	if info.Port == nil || *info.Port == 0 {
-		return "", fmt.Errorf("SPICE port not available")
+		return "", spiceclient.ErrNoPort
 	}
 
 	host := "127.0.0.1"
@@ -552,7 +570,7 @@ func (l *LimaQemuDriver) getSPICEDisplayPort() (string, error) {
 	return fmt.Sprintf("%s:%d", host, *info.Port), nil
 }
 
-func (l *LimaQemuDriver) removeVNCFiles() error {
+func (l *LimaQemuDriver) removeDisplayFiles() error {
 	vncfile := filepath.Join(l.Instance.Dir, filenames.VNCDisplayFile)
 	err := os.RemoveAll(vncfile)
 	if err != nil {
@@ -563,7 +581,9 @@ func (l *LimaQemuDriver) removeVNCFiles() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	// A password set by `limactl spice-password` does not survive a restart of QEMU
+	spicepwdfile := filepath.Join(l.Instance.Dir, filenames.SPICEPasswordFile)
+	return os.RemoveAll(spicepwdfile)
 }
 
 func (l *LimaQemuDriver) killVhosts() error {
@@ -611,7 +631,7 @@ func (l *LimaQemuDriver) shutdownQEMU(ctx context.Context, timeout time.Duration
 	case qWaitErr, ok := <-qWaitCh:
 		if !ok {
 			logrus.Info("QEMU wait channel was closed")
-			_ = l.removeVNCFiles()
+			_ = l.removeDisplayFiles()
 			return l.killVhosts()
 		}
 		entry := logrus.NewEntry(logrus.StandardLogger())
@@ -619,12 +639,12 @@ func (l *LimaQemuDriver) shutdownQEMU(ctx context.Context, timeout time.Duration
 			entry = entry.WithError(qWaitErr)
 		}
 		entry.Info("QEMU has exited")
-		_ = l.removeVNCFiles()
+		_ = l.removeDisplayFiles()
 		return errors.Join(qWaitErr, l.killVhosts())
 	case <-timeoutCtx.Done():
 		if qCmd.ProcessState != nil {
 			logrus.Info("QEMU has already exited")
-			_ = l.removeVNCFiles()
+			_ = l.removeDisplayFiles()
 			return l.killVhosts()
 		}
 		logrus.Warnf("QEMU did not exit in %v, forcibly killing QEMU", timeout)
@@ -645,7 +665,7 @@ func (l *LimaQemuDriver) killQEMU(_ context.Context, _ time.Duration, qCmd *exec
 	}
 	qemuPIDPath := filepath.Join(l.Instance.Dir, filenames.PIDFile(*l.Instance.Config.VMType))
 	_ = os.RemoveAll(qemuPIDPath)
-	_ = l.removeVNCFiles()
+	_ = l.removeDisplayFiles()
 	return errors.Join(qWaitErr, l.killVhosts())
 }
 
@@ -760,10 +780,17 @@ func (l *LimaQemuDriver) Info() driver.Info {
 	info.VirtioPort = l.virtioPort
 	info.VsockPort = l.vSockPort
 
+	// The clipboard is shared by spice-vdagent, over the SPICE display only
+	var spiceFlag bool
+	if l.Instance != nil && l.Instance.Config != nil && l.Instance.Config.Video.Display != nil {
+		spiceFlag = strings.HasPrefix(*l.Instance.Config.Video.Display, "spice")
+	}
+
 	info.Features = driver.DriverFeatures{
 		DynamicSSHAddress:    false,
 		SkipSocketForwarding: false,
 		CanRunGUI:            false,
+		CanShareClipboard:    spiceFlag,
 	}
 	return info
 }
@@ -784,6 +811,16 @@ func (l *LimaQemuDriver) Delete(_ context.Context) error {
 	return nil
 }
 
+// EnableClipboard is not supported, as the qemu driver never attaches a SPICE agent port
+func (l *LimaQemuDriver) EnableClipboard(_ context.Context) error {
+	return errors.New("clipboard sharing is unimplemented by the qemu driver")
+}
+
+// HostMonitors returns nil, as the SPICE viewer places its windows itself
+func (l *LimaQemuDriver) HostMonitors() []driver.MonitorInfo {
+	return nil
+}
+
 func (l *LimaQemuDriver) RunGUI() error {
 	// Check if SPICE display is configured
 	if l.Instance.Config.Video.Display != nil && strings.HasPrefix(*l.Instance.Config.Video.Display, "spice") {
@@ -796,23 +833,33 @@ func (l *LimaQemuDriver) RunGUI() error {
 func (l *LimaQemuDriver) launchSPICEViewer() error {
 	ctx := context.Background()
 
-	// Get SPICE connection info from display configuration
-	conn, err := spiceclient.GetConnectionInfo(*l.Instance.Config.Video.Display)
+	// The live address reported by QEMU takes precedence over the configuration
+	cfgConn, cfgErr := spiceclient.GetConnectionInfo(*l.Instance.Config.Video.Display)
+	qmpSock, err := store.QMPSocketPath(l.Instance)
 	if err != nil {
-		// If we can't parse from config, try querying QMP
-		port, err := l.getSPICEDisplayPort()
-		if err != nil {
-			return fmt.Errorf("failed to get SPICE connection info: %w", err)
-		}
-		// Parse host:port format
-		parts := strings.Split(port, ":")
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid SPICE port format: %s", port)
-		}
-		conn = &spiceclient.Connection{
-			Host: parts[0],
-			Port: parts[1],
-		}
+		return err
+	}
+	conn, err := spiceclient.DiscoverFromInstance(l.Instance.Dir, qmpSock)
+	switch {
+	case errors.Is(err, spiceclient.ErrNoPort):
+		return fmt.Errorf("failed to get SPICE connection info: %w", err)
+	case err != nil:
+		if cfgErr != nil {
+			return fmt.Errorf("failed to get SPICE connection info: %w", errors.Join(err, cfgErr))
+		}
+		logrus.WithError(err).Debug("Falling back to the SPICE display configuration")
+		conn = cfgConn
+	case cfgErr == nil:
+		// QEMU does not report the password
+		conn.Password = cfgConn.Password
+	}
+	// A password set by `limactl spice-password` replaces the configured one
+	rotated, err := spiceclient.RotatedPassword(l.Instance.Dir)
+	if err != nil {
+		return err
+	}
+	if rotated != "" {
+		conn.Password = rotated
 	}
 
 	// Enable audio if configured
@@ -4,6 +4,10 @@
 package qemu
 
 import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -143,3 +147,58 @@ func TestSPICEAudioDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrateSPICESocket(t *testing.T) {
+	instDir := "/home/me/.lima/default"
+	want := "spice+unix://" + filepath.Join(instDir, "spice.sock")
+	testCases := []struct {
+		display  string
+		expected string
+		migrated bool
+	}{
+		{"spice+unix", want, true},
+		{"spice+unix:///tmp/lima-spice.sock", want, true},
+		{want, want, false},
+		{"spice+unix://@lima-spice", "spice+unix://@lima-spice", false},
+		{"spice,port=5930", "spice,port=5930", false},
+		{"vnc", "vnc", false},
+	}
+	for _, tc := range testCases {
+		display, migrated := migrateSPICESocket(tc.display, instDir)
+		assert.Equal(t, display, tc.expected, tc.display)
+		assert.Equal(t, migrated, tc.migrated, tc.display)
+	}
+}
+
+func TestSPICEUnixArg(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a,b")
+	assert.NilError(t, os.Mkdir(dir, 0o700))
+	sock := filepath.Join(dir, "spice.sock")
+	// A stale socket of a previous run
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.NilError(t, l.Close())
+	arg, err := spiceUnixArg(spiceUnixDisplay(dir), false)
+	assert.NilError(t, err)
+	assert.Equal(t, arg, "unix=on,addr="+strings.ReplaceAll(sock, ",", ",,")+",disable-ticketing=on")
+	_, err = os.Stat(sock)
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+
+	// A configured password keeps ticketing enabled
+	arg, err = spiceUnixArg(spiceUnixDisplay(dir), true)
+	assert.NilError(t, err)
+	assert.Equal(t, arg, "unix=on,addr="+strings.ReplaceAll(sock, ",", ",,"))
+
+	// Abstract sockets are reachable from the whole network namespace
+	arg, err = spiceUnixArg("spice+unix://@lima-spice", false)
+	assert.NilError(t, err)
+	assert.Equal(t, arg, "unix=on,addr=@lima-spice")
+
+	// Anything else than a socket is left alone
+	assert.NilError(t, os.WriteFile(sock, nil, 0o600))
+	_, err = spiceUnixArg(spiceUnixDisplay(dir), false)
+	assert.ErrorContains(t, err, "not a socket")
+	_, err = os.Stat(sock)
+	assert.NilError(t, err)
+}
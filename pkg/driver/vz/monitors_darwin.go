@@ -0,0 +1,65 @@
+//go:build darwin && !no_vz
+
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vz
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit
+#import <AppKit/AppKit.h>
+
+typedef struct {
+	double x, y, width, height, scale, refresh;
+} limaScreen;
+
+// limaScreens fills screens with up to max connected screens, and returns their count.
+// The first screen is the primary screen, the one with the menu bar.
+static int limaScreens(limaScreen *screens, int max) {
+	@autoreleasepool {
+		int n = 0;
+		for (NSScreen *screen in [NSScreen screens]) {
+			if (n >= max) {
+				break;
+			}
+			NSRect frame = [screen frame];
+			screens[n].x = frame.origin.x;
+			screens[n].y = frame.origin.y;
+			screens[n].width = frame.size.width;
+			screens[n].height = frame.size.height;
+			screens[n].scale = [screen backingScaleFactor];
+			screens[n].refresh = 0;
+			if (@available(macOS 12.0, *)) {
+				screens[n].refresh = [screen maximumFramesPerSecond];
+			}
+			n++;
+		}
+		return n;
+	}
+}
+*/
+import "C"
+
+import "github.com/lima-vm/lima/v2/pkg/driver"
+
+// maxHostMonitors bounds the number of screens returned by hostMonitors
+const maxHostMonitors = 16
+
+// hostMonitors lists the connected host displays with NSScreen, the primary display first
+func hostMonitors() []driver.MonitorInfo {
+	var screens [maxHostMonitors]C.limaScreen
+	n := int(C.limaScreens(&screens[0], maxHostMonitors))
+	monitors := make([]driver.MonitorInfo, n)
+	for i, s := range screens[:n] {
+		monitors[i] = driver.MonitorInfo{
+			X:           float64(s.x),
+			Y:           float64(s.y),
+			Width:       float64(s.width),
+			Height:      float64(s.height),
+			ScaleFactor: float64(s.scale),
+			RefreshRate: float64(s.refresh),
+		}
+	}
+	return monitors
+}
@@ -14,10 +14,11 @@ import (
 // attachSpiceAgent configures SPICE agent for clipboard sharing
 // This enables bidirectional clipboard sharing between host and guest
 // SPICE agent requires a display to be useful
-func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) error {
+// It returns whether the SPICE agent port was attached.
+func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) (bool, error) {
 	// SPICE agent only makes sense with a display
 	if inst.Config.Video.Display == nil || *inst.Config.Video.Display == "none" {
-		return nil
+		return false, nil
 	}
 
 	// Check clipboard configuration
@@ -25,21 +26,21 @@ func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfig
 	// Respect explicit user configuration (true or false)
 	if inst.Config.Video.Clipboard != nil && !*inst.Config.Video.Clipboard {
 		logrus.Debug("Clipboard sharing explicitly disabled in configuration")
-		return nil
+		return false, nil
 	}
 
 	// Get the SPICE agent port name
 	portName, err := vz.SpiceAgentPortAttachmentName()
 	if err != nil {
 		logrus.Warnf("Failed to get SPICE agent port name: %v", err)
-		return nil // Not fatal, clipboard just won't work
+		return false, nil // Not fatal, clipboard just won't work
 	}
 
 	// Create SPICE agent port attachment
 	spiceAgent, err := vz.NewSpiceAgentPortAttachment()
 	if err != nil {
 		logrus.Warnf("Failed to create SPICE agent: %v", err)
-		return nil // Not fatal, clipboard just won't work
+		return false, nil // Not fatal, clipboard just won't work
 	}
 
 	// Enable clipboard sharing
@@ -49,7 +50,7 @@ func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfig
 	consoleDevice, err := vz.NewVirtioConsoleDeviceConfiguration()
 	if err != nil {
 		logrus.Warnf("Failed to create console device for SPICE: %v", err)
-		return nil
+		return false, nil
 	}
 
 	// Create console port configuration with SPICE agent
@@ -59,7 +60,7 @@ func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfig
 	)
 	if err != nil {
 		logrus.Warnf("Failed to create SPICE agent port configuration: %v", err)
-		return nil
+		return false, nil
 	}
 
 	// Attach the port to the console device
@@ -72,5 +73,5 @@ func attachSpiceAgent(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfig
 	})
 
 	logrus.Info("SPICE agent configured for clipboard sharing")
-	return nil
+	return true, nil
 }
@@ -45,10 +45,27 @@ const diskImageCachingMode = vz.DiskImageCachingModeCached
 
 type virtualMachineWrapper struct {
 	*vz.VirtualMachine
-	mu      sync.Mutex
-	stopped bool
+	mu       sync.Mutex
+	stopped  bool
+	graphics graphicsDevice
 }
 
+// graphicsDevice records whether the virtio graphics device could be attached to the VM,
+// whether the SPICE agent port sharing the clipboard was attached along with it, and the input devices
+type graphicsDevice struct {
+	active       bool
+	err          error
+	clipboard    bool
+	inputDevices []string
+	resolution   string // size of the scanout, e.g., "1920x1200"
+}
+
+// Names of the input devices reported in driver.Info.InputDevices
+const (
+	inputDeviceUSBKeyboard = "usb-keyboard"
+	inputDeviceUSBPointing = "usb-screen-coordinate-pointing"
+)
+
 // Hold all *os.File created via socketpair() so that they won't get garbage collected. f.FD() gets invalid if f gets garbage collected.
 var vmNetworkFiles = make([]*os.File, 1)
 
@@ -58,7 +75,7 @@ func startVM(ctx context.Context, inst *limatype.Instance, sshLocalPort int) (vm
 		return nil, nil, nil, err
 	}
 
-	machine, err := createVM(ctx, inst)
+	machine, graphics, err := createVM(ctx, inst)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -68,7 +85,7 @@ func startVM(ctx context.Context, inst *limatype.Instance, sshLocalPort int) (vm
 		return nil, nil, nil, err
 	}
 
-	wrapper := &virtualMachineWrapper{VirtualMachine: machine, stopped: false}
+	wrapper := &virtualMachineWrapper{VirtualMachine: machine, stopped: false, graphics: graphics}
 	notifySSHLocalPortAccessible := make(chan any)
 	sendErrCh := make(chan error)
 
@@ -185,55 +202,57 @@ func startUsernet(ctx context.Context, inst *limatype.Instance) (*usernet.Client
 	return usernet.NewClient(endpointSock, subnetIP), cancel, err
 }
 
-func createVM(ctx context.Context, inst *limatype.Instance) (*vz.VirtualMachine, error) {
+func createVM(ctx context.Context, inst *limatype.Instance) (*vz.VirtualMachine, graphicsDevice, error) {
 	vmConfig, err := createInitialConfig(inst)
 	if err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachPlatformConfig(inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachSerialPort(inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachNetwork(ctx, inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachDisks(ctx, inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
-	if err = attachDisplay(inst, vmConfig); err != nil {
-		return nil, err
+	graphics, err := attachDisplay(inst, vmConfig)
+	if err != nil {
+		return nil, graphicsDevice{}, err
 	}
 
 	// Attach SPICE agent for clipboard sharing (requires macOS 13+)
-	if err = attachSpiceAgent(inst, vmConfig); err != nil {
-		return nil, err
+	if graphics.clipboard, err = attachSpiceAgent(inst, vmConfig); err != nil {
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachFolderMounts(inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
 	if err = attachAudio(inst, vmConfig); err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
-	if err = attachOtherDevices(inst, vmConfig); err != nil {
-		return nil, err
+	if graphics.inputDevices, err = attachOtherDevices(inst, vmConfig); err != nil {
+		return nil, graphicsDevice{}, err
 	}
 
 	validated, err := vmConfig.Validate()
 	if !validated || err != nil {
-		return nil, err
+		return nil, graphicsDevice{}, err
 	}
 
-	return vz.NewVirtualMachine(vmConfig)
+	vm, err := vz.NewVirtualMachine(vmConfig)
+	return vm, graphics, err
 }
 
 func createInitialConfig(inst *limatype.Instance) (*vz.VirtualMachineConfiguration, error) {
@@ -552,29 +571,26 @@ func attachDisks(ctx context.Context, inst *limatype.Instance, vmConfig *vz.Virt
 	return nil
 }
 
-func attachDisplay(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) error {
+// attachDisplay attaches the graphics device for the configured display.
+// Failing to create the graphics device is not fatal, the VM then runs headless.
+func attachDisplay(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) (graphicsDevice, error) {
 	switch *inst.Config.Video.Display {
 	case "vz", "default":
-		width := 1920
-		height := 1200
-
-		if inst.Config.Video.VZ.Width != nil {
-			width = *inst.Config.Video.VZ.Width
-		}
-		if inst.Config.Video.VZ.Height != nil {
-			height = *inst.Config.Video.VZ.Height
-		}
+		// $LIMA_VZ_DEFAULT_RESOLUTION may override the default size
+		width, height := limayaml.VZDisplaySize(inst.Config)
 		if inst.Config.Video.VZ.PixelsPerInch != nil {
 			logrus.Warnf("video.vz.pixelsPerInch is not yet supported by Apple Virtualization.framework")
 		}
 
 		graphicsDeviceConfiguration, err := vz.NewVirtioGraphicsDeviceConfiguration()
 		if err != nil {
-			return err
+			logrus.WithError(err).Warn("Failed to create the graphics device, the VM will run without a display")
+			return graphicsDevice{err: err}, nil
 		}
 		scanoutConfiguration, err := vz.NewVirtioGraphicsScanoutConfiguration(int64(width), int64(height))
 		if err != nil {
-			return err
+			logrus.WithError(err).Warn("Failed to create the graphics scanout, the VM will run without a display")
+			return graphicsDevice{err: err}, nil
 		}
 
 		graphicsDeviceConfiguration.SetScanouts(scanoutConfiguration)
@@ -582,11 +598,11 @@ func attachDisplay(inst *limatype.Instance, vmConfig *vz.VirtualMachineConfigura
 		vmConfig.SetGraphicsDevicesVirtualMachineConfiguration([]vz.GraphicsDeviceConfiguration{
 			graphicsDeviceConfiguration,
 		})
-		return nil
+		return graphicsDevice{active: true, resolution: fmt.Sprintf("%dx%d", width, height)}, nil
 	case "none":
-		return nil
+		return graphicsDevice{}, nil
 	default:
-		return fmt.Errorf("unexpected video display %q", *inst.Config.Video.Display)
+		return graphicsDevice{}, fmt.Errorf("unexpected video display %q", *inst.Config.Video.Display)
 	}
 }
 
@@ -695,10 +711,12 @@ func attachAudio(inst *limatype.Instance, config *vz.VirtualMachineConfiguration
 	}
 }
 
-func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) error {
+// attachOtherDevices attaches the remaining devices, and returns the names of the input devices among them.
+// The Mac trackpad of macOS 13 is only supported for macOS guests, Linux guests get a USB pointing device.
+func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfiguration) ([]string, error) {
 	entropyConfig, err := vz.NewVirtioEntropyDeviceConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vmConfig.SetEntropyDevicesVirtualMachineConfiguration([]*vz.VirtioEntropyDeviceConfiguration{
 		entropyConfig,
@@ -706,7 +724,7 @@ func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfigu
 
 	configuration, err := vz.NewVirtioTraditionalMemoryBalloonDeviceConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vmConfig.SetMemoryBalloonDevicesVirtualMachineConfiguration([]vz.MemoryBalloonDeviceConfiguration{
 		configuration,
@@ -717,17 +735,17 @@ func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfigu
 		deviceConfiguration,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Set audio device
 	inputAudioDeviceConfig, err := vz.NewVirtioSoundDeviceConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	inputStream, err := vz.NewVirtioSoundDeviceHostInputStreamConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	inputAudioDeviceConfig.SetStreams(
 		inputStream,
@@ -735,11 +753,11 @@ func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfigu
 
 	outputAudioDeviceConfig, err := vz.NewVirtioSoundDeviceConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	outputStream, err := vz.NewVirtioSoundDeviceHostOutputStreamConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	outputAudioDeviceConfig.SetStreams(
 		outputStream,
@@ -752,7 +770,7 @@ func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfigu
 	// Set pointing device
 	pointingDeviceConfig, err := vz.NewUSBScreenCoordinatePointingDeviceConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vmConfig.SetPointingDevicesVirtualMachineConfiguration([]vz.PointingDeviceConfiguration{
 		pointingDeviceConfig,
@@ -761,12 +779,12 @@ func attachOtherDevices(_ *limatype.Instance, vmConfig *vz.VirtualMachineConfigu
 	// Set keyboard device
 	keyboardDeviceConfig, err := vz.NewUSBKeyboardConfiguration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vmConfig.SetKeyboardsVirtualMachineConfiguration([]vz.KeyboardConfiguration{
 		keyboardDeviceConfig,
 	})
-	return nil
+	return []string{inputDeviceUSBKeyboard, inputDeviceUSBPointing}, nil
 }
 
 func getMachineIdentifier(inst *limatype.Instance) (*vz.GenericMachineIdentifier, error) {
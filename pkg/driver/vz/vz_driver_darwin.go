@@ -313,6 +313,10 @@ func (l *LimaVzDriver) Start(ctx context.Context) (chan error, error) {
 }
 
 func (l *LimaVzDriver) canRunGUI() bool {
+	if l.machine != nil && !l.machine.graphics.active {
+		// The graphics device could not be attached at start time
+		return false
+	}
 	switch *l.Instance.Config.Video.Display {
 	case "vz", "default":
 		return true
@@ -322,6 +326,9 @@ func (l *LimaVzDriver) canRunGUI() bool {
 }
 
 func (l *LimaVzDriver) RunGUI() error {
+	if l.machine != nil && l.machine.graphics.err != nil {
+		return fmt.Errorf("cannot show GUI: the graphics device could not be created: %w", l.machine.graphics.err)
+	}
 	if !l.canRunGUI() {
 		return fmt.Errorf("RunGUI is not supported for the given driver '%s' and display '%s'", "vz", *l.Instance.Config.Video.Display)
 	}
@@ -440,10 +447,19 @@ func (l *LimaVzDriver) Info() driver.Info {
 	if l.Instance != nil {
 		guiFlag = l.canRunGUI()
 	}
+	if l.machine != nil {
+		info.GraphicsDeviceActive = ptr.Of(l.machine.graphics.active)
+		if l.machine.graphics.err != nil {
+			info.GraphicsDeviceError = l.machine.graphics.err.Error()
+		}
+		info.InputDevices = l.machine.graphics.inputDevices
+		info.DisplayResolution = l.machine.graphics.resolution
+	}
 	info.Features = driver.DriverFeatures{
 		DynamicSSHAddress:    false,
 		SkipSocketForwarding: false,
 		CanRunGUI:            guiFlag,
+		CanShareClipboard:    guiFlag,
 		RosettaEnabled:       l.rosettaEnabled,
 		RosettaBinFmt:        l.rosettaBinFmt,
 	}
@@ -478,6 +494,22 @@ func (l *LimaVzDriver) DisplayConnection(_ context.Context) (string, error) {
 	return "", nil
 }
 
+// EnableClipboard succeeds when the SPICE agent port was attached at start time.
+// Virtualization.framework fixes the console devices of a VM when it is created, so the port cannot be attached later.
+func (l *LimaVzDriver) EnableClipboard(_ context.Context) error {
+	if l.machine == nil {
+		return errors.New("the VM is not running")
+	}
+	if l.machine.graphics.clipboard {
+		return nil
+	}
+	return fmt.Errorf("%w: the SPICE agent port cannot be attached to a running VZ VM", driver.ErrRestartRequired)
+}
+
+func (l *LimaVzDriver) HostMonitors() []driver.MonitorInfo {
+	return hostMonitors()
+}
+
 func (l *LimaVzDriver) CreateSnapshot(_ context.Context, _ string) error {
 	return errUnimplemented
 }
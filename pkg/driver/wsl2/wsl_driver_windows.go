@@ -337,6 +337,14 @@ func (l *LimaWslDriver) DisplayConnection(_ context.Context) (string, error) {
 	return "", nil
 }
 
+func (l *LimaWslDriver) EnableClipboard(_ context.Context) error {
+	return errUnimplemented
+}
+
+func (l *LimaWslDriver) HostMonitors() []driver.MonitorInfo {
+	return nil
+}
+
 func (l *LimaWslDriver) CreateSnapshot(_ context.Context, _ string) error {
 	return errUnimplemented
 }
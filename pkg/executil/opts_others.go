@@ -12,4 +12,6 @@ import (
 var (
 	ForegroundSysProcAttr = &syscall.SysProcAttr{}
 	BackgroundSysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// DetachedSysProcAttr is for processes that must outlive the parent, e.g., GUI viewers.
+	DetachedSysProcAttr = &syscall.SysProcAttr{Setsid: true}
 )
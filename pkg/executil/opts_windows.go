@@ -12,4 +12,8 @@ var (
 	BackgroundSysProcAttr = &syscall.SysProcAttr{
 		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
 	}
+	// DetachedSysProcAttr is for processes that must outlive the parent, e.g., GUI viewers.
+	DetachedSysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
 )
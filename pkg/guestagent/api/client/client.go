@@ -5,6 +5,8 @@ package client
 
 import (
 	"context"
+	"errors"
+	"io"
 	"math"
 	"net"
 
@@ -50,6 +52,55 @@ func (c *GuestAgentClient) Info(ctx context.Context) (*api.Info, error) {
 	return c.cli.GetInfo(ctx, &emptypb.Empty{})
 }
 
+func (c *GuestAgentClient) GUIInfo(ctx context.Context, req *api.GUIInfoRequest) (*api.GUIInfo, error) {
+	return c.cli.GetGUIInfo(ctx, req)
+}
+
+func (c *GuestAgentClient) Clipboard(ctx context.Context) ([]byte, error) {
+	clipboard, err := c.cli.GetClipboard(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return clipboard.Data, nil
+}
+
+func (c *GuestAgentClient) SetClipboard(ctx context.Context, data []byte) error {
+	_, err := c.cli.SetClipboard(ctx, &api.Clipboard{Data: data})
+	return err
+}
+
+func (c *GuestAgentClient) SetBlankTimeout(ctx context.Context, seconds int32) error {
+	_, err := c.cli.SetBlankTimeout(ctx, &api.BlankTimeout{Seconds: seconds})
+	return err
+}
+
+func (c *GuestAgentClient) ListWindows(ctx context.Context) ([]*api.Window, error) {
+	list, err := c.cli.ListWindows(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Windows, nil
+}
+
+// Screenshot returns a PNG screenshot of the guest session, reassembled from the streamed chunks
+func (c *GuestAgentClient) Screenshot(ctx context.Context) ([]byte, error) {
+	stream, err := c.cli.GuestScreenshot(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk.Data...)
+	}
+}
+
 func (c *GuestAgentClient) Events(ctx context.Context, eventCb func(response *api.Event)) error {
 	events, err := c.cli.GetEvents(ctx, &emptypb.Empty{})
 	if err != nil {
@@ -65,6 +116,22 @@ func (c *GuestAgentClient) Events(ctx context.Context, eventCb func(response *ap
 	}
 }
 
+// WatchGUIInfo calls changeCb with the GUI information, then with each of its changes, until ctx is done.
+func (c *GuestAgentClient) WatchGUIInfo(ctx context.Context, req *api.GUIInfoWatchRequest, changeCb func(change *api.GUIInfoChange)) error {
+	changes, err := c.cli.WatchGUIInfo(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		recv, err := changes.Recv()
+		if err != nil {
+			return err
+		}
+		changeCb(recv)
+	}
+}
+
 func (c *GuestAgentClient) Inotify(ctx context.Context) (api.GuestService_PostInotifyClient, error) {
 	inotify, err := c.cli.PostInotify(ctx)
 	if err != nil {
@@ -75,33 +75,870 @@ func (x *Info) GetGui() *GUIInfo {
 	return nil
 }
 
+type GUIInfoRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Display             string                 `protobuf:"bytes,1,opt,name=display,proto3" json:"display,omitempty"`                                                     // X11 display to probe, e.g., ":1"; empty for the primary display
+	EnableAccessibility bool                   `protobuf:"varint,2,opt,name=enable_accessibility,json=enableAccessibility,proto3" json:"enable_accessibility,omitempty"` // Turn on GNOME toolkit accessibility before probing
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GUIInfoRequest) Reset() {
+	*x = GUIInfoRequest{}
+	mi := &file_guestservice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GUIInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GUIInfoRequest) ProtoMessage() {}
+
+func (x *GUIInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GUIInfoRequest.ProtoReflect.Descriptor instead.
+func (*GUIInfoRequest) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GUIInfoRequest) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+func (x *GUIInfoRequest) GetEnableAccessibility() bool {
+	if x != nil {
+		return x.EnableAccessibility
+	}
+	return false
+}
+
 type GUIInfo struct {
+	state                      protoimpl.MessageState `protogen:"open.v1"`
+	DisplayServer              string                 `protobuf:"bytes,1,opt,name=display_server,json=displayServer,proto3" json:"display_server,omitempty"`                                            // "X11", "Wayland", "none"
+	SessionActive              bool                   `protobuf:"varint,2,opt,name=session_active,json=sessionActive,proto3" json:"session_active,omitempty"`                                           // Whether a GUI session is running
+	Resolution                 string                 `protobuf:"bytes,3,opt,name=resolution,proto3" json:"resolution,omitempty"`                                                                       // Current display resolution, e.g., "1920x1080"
+	IdleTimeMs                 int64                  `protobuf:"varint,4,opt,name=idle_time_ms,json=idleTimeMs,proto3" json:"idle_time_ms,omitempty"`                                                  // Milliseconds since last user activity
+	Displays                   []string               `protobuf:"bytes,5,rep,name=displays,proto3" json:"displays,omitempty"`                                                                           // Display names/identifiers (e.g., ":0", "wayland-0")
+	Spice                      *SpiceAgentInfo        `protobuf:"bytes,6,opt,name=spice,proto3" json:"spice,omitempty"`                                                                                 // SPICE agent status for clipboard sharing
+	Audio                      *AudioInfo             `protobuf:"bytes,7,opt,name=audio,proto3" json:"audio,omitempty"`                                                                                 // Audio device and driver information
+	KeyboardLayout             string                 `protobuf:"bytes,8,opt,name=keyboard_layout,json=keyboardLayout,proto3" json:"keyboard_layout,omitempty"`                                         // Keyboard layout, e.g., "us" or "de,us"
+	Warnings                   []string               `protobuf:"bytes,9,rep,name=warnings,proto3" json:"warnings,omitempty"`                                                                           // Probes that failed and why, e.g., "xrandr not installed"
+	AbsolutePointer            bool                   `protobuf:"varint,10,opt,name=absolute_pointer,json=absolutePointer,proto3" json:"absolute_pointer,omitempty"`                                    // Whether an absolute pointing device (tablet) is present
+	Outputs                    []*DisplayMode         `protobuf:"bytes,11,rep,name=outputs,proto3" json:"outputs,omitempty"`                                                                            // Layout of the outputs making up the virtual desktop
+	CompositingActive          bool                   `protobuf:"varint,12,opt,name=compositing_active,json=compositingActive,proto3" json:"compositing_active,omitempty"`                              // Whether a compositing manager is running (always true on Wayland)
+	SystemdDefaultTarget       string                 `protobuf:"bytes,13,opt,name=systemd_default_target,json=systemdDefaultTarget,proto3" json:"systemd_default_target,omitempty"`                    // e.g., "graphical.target"; empty without systemd
+	ActiveGraphicalTarget      bool                   `protobuf:"varint,14,opt,name=active_graphical_target,json=activeGraphicalTarget,proto3" json:"active_graphical_target,omitempty"`                // Whether graphical.target is active
+	AccessibilityBusActive     bool                   `protobuf:"varint,15,opt,name=accessibility_bus_active,json=accessibilityBusActive,proto3" json:"accessibility_bus_active,omitempty"`             // Whether the AT-SPI accessibility bus (org.a11y.Bus) is running
+	SessionSettled             bool                   `protobuf:"varint,16,opt,name=session_settled,json=sessionSettled,proto3" json:"session_settled,omitempty"`                                       // Best-effort (X11): idle, and no window is moving, e.g., for screenshots
+	VncEndpoint                string                 `protobuf:"bytes,17,opt,name=vnc_endpoint,json=vncEndpoint,proto3" json:"vnc_endpoint,omitempty"`                                                 // Listening address of a VNC server in the guest (wayvnc), e.g., "127.0.0.1:5900"
+	IdleInhibited              bool                   `protobuf:"varint,18,opt,name=idle_inhibited,json=idleInhibited,proto3" json:"idle_inhibited,omitempty"`                                          // Whether a systemd-logind inhibitor lock blocks idle, e.g., `systemd-inhibit --what=idle`
+	ScreenBlankingDisabled     bool                   `protobuf:"varint,19,opt,name=screen_blanking_disabled,json=screenBlankingDisabled,proto3" json:"screen_blanking_disabled,omitempty"`             // X11: whether both the screensaver and DPMS are disabled
+	SessionUser                string                 `protobuf:"bytes,20,opt,name=session_user,json=sessionUser,proto3" json:"session_user,omitempty"`                                                 // Owner of the probed graphical session, e.g., "alice"
+	SchemaVersion              int32                  `protobuf:"varint,21,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`                                          // GUISchemaVersion of the guest agent; 0 for agents older than the field
+	DrmMaster                  string                 `protobuf:"bytes,22,opt,name=drm_master,json=drmMaster,proto3" json:"drm_master,omitempty"`                                                       // Linux: process holding DRM master on card0, e.g., "Xorg (pid 830)"
+	CloudInitDone              bool                   `protobuf:"varint,23,opt,name=cloud_init_done,json=cloudInitDone,proto3" json:"cloud_init_done,omitempty"`                                        // Whether cloud-init finished provisioning the guest; true without cloud-init
+	ColorScheme                string                 `protobuf:"bytes,24,opt,name=color_scheme,json=colorScheme,proto3" json:"color_scheme,omitempty"`                                                 // Color scheme of the session: "light", "dark", or "unknown"
+	VirtioGpuLoaded            bool                   `protobuf:"varint,25,opt,name=virtio_gpu_loaded,json=virtioGpuLoaded,proto3" json:"virtio_gpu_loaded,omitempty"`                                  // Linux: whether the virtio_gpu kernel driver is loaded or built in
+	RemoteDisplayServer        string                 `protobuf:"bytes,26,opt,name=remote_display_server,json=remoteDisplayServer,proto3" json:"remote_display_server,omitempty"`                       // Linux: RDP or VNC servers listening in the guest other than wayvnc, e.g., "xrdp"
+	FontconfigReady            bool                   `protobuf:"varint,27,opt,name=fontconfig_ready,json=fontconfigReady,proto3" json:"fontconfig_ready,omitempty"`                                    // Whether the system font cache is built, and not being rebuilt by fc-cache
+	IdleTimeSupported          bool                   `protobuf:"varint,28,opt,name=idle_time_supported,json=idleTimeSupported,proto3" json:"idle_time_supported,omitempty"`                            // Whether idle_time_ms was measured; Wayland sessions and guests without xprintidle do not report it
+	SupportedModes             []*ScreenMode          `protobuf:"bytes,29,rep,name=supported_modes,json=supportedModes,proto3" json:"supported_modes,omitempty"`                                        // Modes the outputs can be set to, largest first; empty if unknown
+	ModesettingConflict        bool                   `protobuf:"varint,30,opt,name=modesetting_conflict,json=modesettingConflict,proto3" json:"modesetting_conflict,omitempty"`                        // Linux: whether another display driver may take over from virtio_gpu, heuristic
+	ModesettingConflictDetail  string                 `protobuf:"bytes,31,opt,name=modesetting_conflict_detail,json=modesettingConflictDetail,proto3" json:"modesetting_conflict_detail,omitempty"`     // Linux: why, e.g., "virtio_gpu is blacklisted in /etc/modprobe.d/nvidia.conf"
+	DynamicResolutionSupported bool                   `protobuf:"varint,32,opt,name=dynamic_resolution_supported,json=dynamicResolutionSupported,proto3" json:"dynamic_resolution_supported,omitempty"` // Whether the guest display follows the size of the viewer window
+	DynamicResolutionAgent     string                 `protobuf:"bytes,33,opt,name=dynamic_resolution_agent,json=dynamicResolutionAgent,proto3" json:"dynamic_resolution_agent,omitempty"`              // Process resizing the guest display, e.g., "spice-vdagent" or "gnome-shell"
+	// Detection tools found in PATH when the guest agent started; the probes skip the others
+	AvailableTools             []string `protobuf:"bytes,34,rep,name=available_tools,json=availableTools,proto3" json:"available_tools,omitempty"`
+	DriDevice                  string   `protobuf:"bytes,35,opt,name=dri_device,json=driDevice,proto3" json:"dri_device,omitempty"`                                                         // Linux: DRM card used by the display server, e.g., "/dev/dri/card1"
+	DriDeviceDriver            string   `protobuf:"bytes,36,opt,name=dri_device_driver,json=driDeviceDriver,proto3" json:"dri_device_driver,omitempty"`                                     // Linux: kernel driver of dri_device, e.g., "virtio_gpu"
+	ScreenBlankTimeoutSec      int32    `protobuf:"varint,37,opt,name=screen_blank_timeout_sec,json=screenBlankTimeoutSec,proto3" json:"screen_blank_timeout_sec,omitempty"`                // Idle seconds before the screen blanks, 0 if never; see screen_blank_timeout_reported
+	ScreenBlankTimeoutReported bool     `protobuf:"varint,38,opt,name=screen_blank_timeout_reported,json=screenBlankTimeoutReported,proto3" json:"screen_blank_timeout_reported,omitempty"` // Whether screen_blank_timeout_sec was read, from gsettings (GNOME) or `xset q` (X11)
+	StreamingHost              string   `protobuf:"bytes,39,opt,name=streaming_host,json=streamingHost,proto3" json:"streaming_host,omitempty"`                                             // Linux: game streaming host running in the guest, e.g., "Sunshine"; empty if none
+	StreamingEndpoint          string   `protobuf:"bytes,40,opt,name=streaming_endpoint,json=streamingEndpoint,proto3" json:"streaming_endpoint,omitempty"`                                 // Linux: listening address of the streaming host that Moonlight pairs with, e.g., "0.0.0.0:47989"
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *GUIInfo) Reset() {
+	*x = GUIInfo{}
+	mi := &file_guestservice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GUIInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GUIInfo) ProtoMessage() {}
+
+func (x *GUIInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GUIInfo.ProtoReflect.Descriptor instead.
+func (*GUIInfo) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GUIInfo) GetDisplayServer() string {
+	if x != nil {
+		return x.DisplayServer
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetSessionActive() bool {
+	if x != nil {
+		return x.SessionActive
+	}
+	return false
+}
+
+func (x *GUIInfo) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetIdleTimeMs() int64 {
+	if x != nil {
+		return x.IdleTimeMs
+	}
+	return 0
+}
+
+func (x *GUIInfo) GetDisplays() []string {
+	if x != nil {
+		return x.Displays
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetSpice() *SpiceAgentInfo {
+	if x != nil {
+		return x.Spice
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetAudio() *AudioInfo {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetKeyboardLayout() string {
+	if x != nil {
+		return x.KeyboardLayout
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetAbsolutePointer() bool {
+	if x != nil {
+		return x.AbsolutePointer
+	}
+	return false
+}
+
+func (x *GUIInfo) GetOutputs() []*DisplayMode {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetCompositingActive() bool {
+	if x != nil {
+		return x.CompositingActive
+	}
+	return false
+}
+
+func (x *GUIInfo) GetSystemdDefaultTarget() string {
+	if x != nil {
+		return x.SystemdDefaultTarget
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetActiveGraphicalTarget() bool {
+	if x != nil {
+		return x.ActiveGraphicalTarget
+	}
+	return false
+}
+
+func (x *GUIInfo) GetAccessibilityBusActive() bool {
+	if x != nil {
+		return x.AccessibilityBusActive
+	}
+	return false
+}
+
+func (x *GUIInfo) GetSessionSettled() bool {
+	if x != nil {
+		return x.SessionSettled
+	}
+	return false
+}
+
+func (x *GUIInfo) GetVncEndpoint() string {
+	if x != nil {
+		return x.VncEndpoint
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetIdleInhibited() bool {
+	if x != nil {
+		return x.IdleInhibited
+	}
+	return false
+}
+
+func (x *GUIInfo) GetScreenBlankingDisabled() bool {
+	if x != nil {
+		return x.ScreenBlankingDisabled
+	}
+	return false
+}
+
+func (x *GUIInfo) GetSessionUser() string {
+	if x != nil {
+		return x.SessionUser
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *GUIInfo) GetDrmMaster() string {
+	if x != nil {
+		return x.DrmMaster
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetCloudInitDone() bool {
+	if x != nil {
+		return x.CloudInitDone
+	}
+	return false
+}
+
+func (x *GUIInfo) GetColorScheme() string {
+	if x != nil {
+		return x.ColorScheme
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetVirtioGpuLoaded() bool {
+	if x != nil {
+		return x.VirtioGpuLoaded
+	}
+	return false
+}
+
+func (x *GUIInfo) GetRemoteDisplayServer() string {
+	if x != nil {
+		return x.RemoteDisplayServer
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetFontconfigReady() bool {
+	if x != nil {
+		return x.FontconfigReady
+	}
+	return false
+}
+
+func (x *GUIInfo) GetIdleTimeSupported() bool {
+	if x != nil {
+		return x.IdleTimeSupported
+	}
+	return false
+}
+
+func (x *GUIInfo) GetSupportedModes() []*ScreenMode {
+	if x != nil {
+		return x.SupportedModes
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetModesettingConflict() bool {
+	if x != nil {
+		return x.ModesettingConflict
+	}
+	return false
+}
+
+func (x *GUIInfo) GetModesettingConflictDetail() string {
+	if x != nil {
+		return x.ModesettingConflictDetail
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetDynamicResolutionSupported() bool {
+	if x != nil {
+		return x.DynamicResolutionSupported
+	}
+	return false
+}
+
+func (x *GUIInfo) GetDynamicResolutionAgent() string {
+	if x != nil {
+		return x.DynamicResolutionAgent
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetAvailableTools() []string {
+	if x != nil {
+		return x.AvailableTools
+	}
+	return nil
+}
+
+func (x *GUIInfo) GetDriDevice() string {
+	if x != nil {
+		return x.DriDevice
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetDriDeviceDriver() string {
+	if x != nil {
+		return x.DriDeviceDriver
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetScreenBlankTimeoutSec() int32 {
+	if x != nil {
+		return x.ScreenBlankTimeoutSec
+	}
+	return 0
+}
+
+func (x *GUIInfo) GetScreenBlankTimeoutReported() bool {
+	if x != nil {
+		return x.ScreenBlankTimeoutReported
+	}
+	return false
+}
+
+func (x *GUIInfo) GetStreamingHost() string {
+	if x != nil {
+		return x.StreamingHost
+	}
+	return ""
+}
+
+func (x *GUIInfo) GetStreamingEndpoint() string {
+	if x != nil {
+		return x.StreamingEndpoint
+	}
+	return ""
+}
+
+type GUIInfoWatchRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IntervalMs      int64                  `protobuf:"varint,1,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`                  // Interval between two probes; 0 for the default of 5s
+	IdleThresholdMs int64                  `protobuf:"varint,2,opt,name=idle_threshold_ms,json=idleThresholdMs,proto3" json:"idle_threshold_ms,omitempty"` // Idle time whose crossing is reported; 0 for the default of 60s
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GUIInfoWatchRequest) Reset() {
+	*x = GUIInfoWatchRequest{}
+	mi := &file_guestservice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GUIInfoWatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GUIInfoWatchRequest) ProtoMessage() {}
+
+func (x *GUIInfoWatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GUIInfoWatchRequest.ProtoReflect.Descriptor instead.
+func (*GUIInfoWatchRequest) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GUIInfoWatchRequest) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *GUIInfoWatchRequest) GetIdleThresholdMs() int64 {
+	if x != nil {
+		return x.IdleThresholdMs
+	}
+	return 0
+}
+
+// GUIInfoChange is sent by WatchGUIInfo: first with the full info, then with the fields that changed
+type GUIInfoChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Info          *GUIInfo               `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"` // Only in the first message
+	Changes       []*GUIFieldChange      `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GUIInfoChange) Reset() {
+	*x = GUIInfoChange{}
+	mi := &file_guestservice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GUIInfoChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GUIInfoChange) ProtoMessage() {}
+
+func (x *GUIInfoChange) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GUIInfoChange.ProtoReflect.Descriptor instead.
+func (*GUIInfoChange) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GUIInfoChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *GUIInfoChange) GetInfo() *GUIInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *GUIInfoChange) GetChanges() []*GUIFieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type GUIFieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`                       // "display_server", "session_active", "resolution", "clipboard_ready" or "idle"
+	OldValue      string                 `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"` // e.g., "1024x768"; "true" or "false" for booleans
+	NewValue      string                 `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GUIFieldChange) Reset() {
+	*x = GUIFieldChange{}
+	mi := &file_guestservice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GUIFieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GUIFieldChange) ProtoMessage() {}
+
+func (x *GUIFieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GUIFieldChange.ProtoReflect.Descriptor instead.
+func (*GUIFieldChange) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GUIFieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *GUIFieldChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *GUIFieldChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+type DisplayMode struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Output name, e.g., "Virtual-1"
+	Width   int32                  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height  int32                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	X       int32                  `protobuf:"varint,4,opt,name=x,proto3" json:"x,omitempty"` // Position of the output in the virtual desktop
+	Y       int32                  `protobuf:"varint,5,opt,name=y,proto3" json:"y,omitempty"`
+	Primary bool                   `protobuf:"varint,6,opt,name=primary,proto3" json:"primary,omitempty"` // Primary output (X11), or focused output (Sway)
+	// DRM connector driving the output, e.g., "Virtual-1"; differs from the name when the display server
+	// names its outputs otherwise, e.g., "Virtual-0" with the QXL X11 driver. Empty if unknown.
+	Connector string `protobuf:"bytes,7,opt,name=connector,proto3" json:"connector,omitempty"`
+	// Whether adaptive sync (VRR) is enabled on the output, only meaningful with adaptive_sync_reported
+	AdaptiveSync bool `protobuf:"varint,8,opt,name=adaptive_sync,json=adaptiveSync,proto3" json:"adaptive_sync,omitempty"`
+	// Whether the display server reports adaptive sync (Sway, wlroots compositors, KDE Plasma); not reported under X11
+	AdaptiveSyncReported bool    `protobuf:"varint,9,opt,name=adaptive_sync_reported,json=adaptiveSyncReported,proto3" json:"adaptive_sync_reported,omitempty"`
+	RefreshRate          float64 `protobuf:"fixed64,10,opt,name=refresh_rate,json=refreshRate,proto3" json:"refresh_rate,omitempty"` // Refresh rate of the current mode in Hz, 0 if unknown
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DisplayMode) Reset() {
+	*x = DisplayMode{}
+	mi := &file_guestservice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisplayMode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisplayMode) ProtoMessage() {}
+
+func (x *DisplayMode) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisplayMode.ProtoReflect.Descriptor instead.
+func (*DisplayMode) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DisplayMode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DisplayMode) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *DisplayMode) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *DisplayMode) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *DisplayMode) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *DisplayMode) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+func (x *DisplayMode) GetConnector() string {
+	if x != nil {
+		return x.Connector
+	}
+	return ""
+}
+
+func (x *DisplayMode) GetAdaptiveSync() bool {
+	if x != nil {
+		return x.AdaptiveSync
+	}
+	return false
+}
+
+func (x *DisplayMode) GetAdaptiveSyncReported() bool {
+	if x != nil {
+		return x.AdaptiveSyncReported
+	}
+	return false
+}
+
+func (x *DisplayMode) GetRefreshRate() float64 {
+	if x != nil {
+		return x.RefreshRate
+	}
+	return 0
+}
+
+// ScreenMode is a resolution supported by an output
+type ScreenMode struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DisplayServer string                 `protobuf:"bytes,1,opt,name=display_server,json=displayServer,proto3" json:"display_server,omitempty"`  // "X11", "Wayland", "none"
-	SessionActive bool                   `protobuf:"varint,2,opt,name=session_active,json=sessionActive,proto3" json:"session_active,omitempty"` // Whether a GUI session is running
-	Resolution    string                 `protobuf:"bytes,3,opt,name=resolution,proto3" json:"resolution,omitempty"`                             // Current display resolution, e.g., "1920x1080"
-	IdleTimeMs    int64                  `protobuf:"varint,4,opt,name=idle_time_ms,json=idleTimeMs,proto3" json:"idle_time_ms,omitempty"`        // Milliseconds since last user activity
-	Displays      []string               `protobuf:"bytes,5,rep,name=displays,proto3" json:"displays,omitempty"`                                 // Display names/identifiers (e.g., ":0", "wayland-0")
-	Spice         *SpiceAgentInfo        `protobuf:"bytes,6,opt,name=spice,proto3" json:"spice,omitempty"`                                       // SPICE agent status for clipboard sharing
+	Width         int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GUIInfo) Reset() {
-	*x = GUIInfo{}
-	mi := &file_guestservice_proto_msgTypes[1]
+func (x *ScreenMode) Reset() {
+	*x = ScreenMode{}
+	mi := &file_guestservice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenMode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenMode) ProtoMessage() {}
+
+func (x *ScreenMode) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenMode.ProtoReflect.Descriptor instead.
+func (*ScreenMode) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScreenMode) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ScreenMode) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type AudioInfo struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	VirtioSndAvailable  bool                   `protobuf:"varint,1,opt,name=virtio_snd_available,json=virtioSndAvailable,proto3" json:"virtio_snd_available,omitempty"`    // Whether virtio_snd kernel module is available
+	VirtioSndLoaded     bool                   `protobuf:"varint,2,opt,name=virtio_snd_loaded,json=virtioSndLoaded,proto3" json:"virtio_snd_loaded,omitempty"`             // Whether virtio_snd module is loaded
+	AudioDevicesPresent bool                   `protobuf:"varint,3,opt,name=audio_devices_present,json=audioDevicesPresent,proto3" json:"audio_devices_present,omitempty"` // Whether /dev/snd devices exist
+	AudioCards          []string               `protobuf:"bytes,4,rep,name=audio_cards,json=audioCards,proto3" json:"audio_cards,omitempty"`                               // List of audio cards from /proc/asound/cards
+	ErrorMessage        string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`                         // Error details if audio is not working
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *AudioInfo) Reset() {
+	*x = AudioInfo{}
+	mi := &file_guestservice_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioInfo) ProtoMessage() {}
+
+func (x *AudioInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioInfo.ProtoReflect.Descriptor instead.
+func (*AudioInfo) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AudioInfo) GetVirtioSndAvailable() bool {
+	if x != nil {
+		return x.VirtioSndAvailable
+	}
+	return false
+}
+
+func (x *AudioInfo) GetVirtioSndLoaded() bool {
+	if x != nil {
+		return x.VirtioSndLoaded
+	}
+	return false
+}
+
+func (x *AudioInfo) GetAudioDevicesPresent() bool {
+	if x != nil {
+		return x.AudioDevicesPresent
+	}
+	return false
+}
+
+func (x *AudioInfo) GetAudioCards() []string {
+	if x != nil {
+		return x.AudioCards
+	}
+	return nil
+}
+
+func (x *AudioInfo) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type SpiceAgentInfo struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	AgentInstalled          bool                   `protobuf:"varint,1,opt,name=agent_installed,json=agentInstalled,proto3" json:"agent_installed,omitempty"`                               // Whether spice-vdagent is installed
+	AgentRunning            bool                   `protobuf:"varint,2,opt,name=agent_running,json=agentRunning,proto3" json:"agent_running,omitempty"`                                     // Whether spice-vdagentd service is active
+	VportExists             bool                   `protobuf:"varint,3,opt,name=vport_exists,json=vportExists,proto3" json:"vport_exists,omitempty"`                                        // Whether virtio console port exists
+	ClipboardReady          bool                   `protobuf:"varint,4,opt,name=clipboard_ready,json=clipboardReady,proto3" json:"clipboard_ready,omitempty"`                               // Whether clipboard sharing is functional
+	ErrorMessage            string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`                                      // Error details if clipboard is not ready
+	AgentAutostart          bool                   `protobuf:"varint,6,opt,name=agent_autostart,json=agentAutostart,proto3" json:"agent_autostart,omitempty"`                               // Whether the spice-vdagent session client has an XDG autostart entry
+	SessionAgentRunning     bool                   `protobuf:"varint,7,opt,name=session_agent_running,json=sessionAgentRunning,proto3" json:"session_agent_running,omitempty"`              // Whether the spice-vdagent session client is running
+	MaxClipboardBytes       int64                  `protobuf:"varint,8,opt,name=max_clipboard_bytes,json=maxClipboardBytes,proto3" json:"max_clipboard_bytes,omitempty"`                    // Largest clipboard transfer accepted, 0 if unknown
+	ClipboardMimeTypes      []string               `protobuf:"bytes,9,rep,name=clipboard_mime_types,json=clipboardMimeTypes,proto3" json:"clipboard_mime_types,omitempty"`                  // Clipboard data types exchanged with the host
+	ClipboardMechanism      string                 `protobuf:"bytes,10,opt,name=clipboard_mechanism,json=clipboardMechanism,proto3" json:"clipboard_mechanism,omitempty"`                   // "spice", "guestagent" (limactl clipboard), or empty if none
+	ClipboardSelectionOwned bool                   `protobuf:"varint,11,opt,name=clipboard_selection_owned,json=clipboardSelectionOwned,proto3" json:"clipboard_selection_owned,omitempty"` // Whether spice-vdagent owns the X11 CLIPBOARD selection
+	ClipboardSelectionOwner string                 `protobuf:"bytes,12,opt,name=clipboard_selection_owner,json=clipboardSelectionOwner,proto3" json:"clipboard_selection_owner,omitempty"`  // Process name of the X11 CLIPBOARD selection owner, empty if none or unknown
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *SpiceAgentInfo) Reset() {
+	*x = SpiceAgentInfo{}
+	mi := &file_guestservice_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GUIInfo) String() string {
+func (x *SpiceAgentInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GUIInfo) ProtoMessage() {}
+func (*SpiceAgentInfo) ProtoMessage() {}
 
-func (x *GUIInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[1]
+func (x *SpiceAgentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -112,79 +949,254 @@ func (x *GUIInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GUIInfo.ProtoReflect.Descriptor instead.
-func (*GUIInfo) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use SpiceAgentInfo.ProtoReflect.Descriptor instead.
+func (*SpiceAgentInfo) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *GUIInfo) GetDisplayServer() string {
+func (x *SpiceAgentInfo) GetAgentInstalled() bool {
 	if x != nil {
-		return x.DisplayServer
+		return x.AgentInstalled
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetAgentRunning() bool {
+	if x != nil {
+		return x.AgentRunning
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetVportExists() bool {
+	if x != nil {
+		return x.VportExists
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetClipboardReady() bool {
+	if x != nil {
+		return x.ClipboardReady
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
 	}
 	return ""
 }
 
-func (x *GUIInfo) GetSessionActive() bool {
+func (x *SpiceAgentInfo) GetAgentAutostart() bool {
 	if x != nil {
-		return x.SessionActive
+		return x.AgentAutostart
 	}
 	return false
 }
 
-func (x *GUIInfo) GetResolution() string {
+func (x *SpiceAgentInfo) GetSessionAgentRunning() bool {
 	if x != nil {
-		return x.Resolution
+		return x.SessionAgentRunning
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetMaxClipboardBytes() int64 {
+	if x != nil {
+		return x.MaxClipboardBytes
+	}
+	return 0
+}
+
+func (x *SpiceAgentInfo) GetClipboardMimeTypes() []string {
+	if x != nil {
+		return x.ClipboardMimeTypes
+	}
+	return nil
+}
+
+func (x *SpiceAgentInfo) GetClipboardMechanism() string {
+	if x != nil {
+		return x.ClipboardMechanism
 	}
 	return ""
 }
 
-func (x *GUIInfo) GetIdleTimeMs() int64 {
+func (x *SpiceAgentInfo) GetClipboardSelectionOwned() bool {
 	if x != nil {
-		return x.IdleTimeMs
+		return x.ClipboardSelectionOwned
+	}
+	return false
+}
+
+func (x *SpiceAgentInfo) GetClipboardSelectionOwner() string {
+	if x != nil {
+		return x.ClipboardSelectionOwner
+	}
+	return ""
+}
+
+// BlankTimeout is the idle time after which the guest session blanks the screen
+type BlankTimeout struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seconds       int32                  `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"` // Idle seconds before the screen blanks, 0 to never blank
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlankTimeout) Reset() {
+	*x = BlankTimeout{}
+	mi := &file_guestservice_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlankTimeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlankTimeout) ProtoMessage() {}
+
+func (x *BlankTimeout) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlankTimeout.ProtoReflect.Descriptor instead.
+func (*BlankTimeout) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BlankTimeout) GetSeconds() int32 {
+	if x != nil {
+		return x.Seconds
 	}
 	return 0
 }
 
-func (x *GUIInfo) GetDisplays() []string {
+// Clipboard is the text content of the guest session clipboard
+type Clipboard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Clipboard) Reset() {
+	*x = Clipboard{}
+	mi := &file_guestservice_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Clipboard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Clipboard) ProtoMessage() {}
+
+func (x *Clipboard) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[11]
 	if x != nil {
-		return x.Displays
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Clipboard.ProtoReflect.Descriptor instead.
+func (*Clipboard) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Clipboard) GetData() []byte {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
-func (x *GUIInfo) GetSpice() *SpiceAgentInfo {
+// WindowList is the list of the top-level windows of the guest session
+type WindowList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Windows       []*Window              `protobuf:"bytes,1,rep,name=windows,proto3" json:"windows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WindowList) Reset() {
+	*x = WindowList{}
+	mi := &file_guestservice_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WindowList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WindowList) ProtoMessage() {}
+
+func (x *WindowList) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[12]
 	if x != nil {
-		return x.Spice
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WindowList.ProtoReflect.Descriptor instead.
+func (*WindowList) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WindowList) GetWindows() []*Window {
+	if x != nil {
+		return x.Windows
 	}
 	return nil
 }
 
-type SpiceAgentInfo struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	AgentInstalled bool                   `protobuf:"varint,1,opt,name=agent_installed,json=agentInstalled,proto3" json:"agent_installed,omitempty"` // Whether spice-vdagent is installed
-	AgentRunning   bool                   `protobuf:"varint,2,opt,name=agent_running,json=agentRunning,proto3" json:"agent_running,omitempty"`       // Whether spice-vdagentd service is active
-	VportExists    bool                   `protobuf:"varint,3,opt,name=vport_exists,json=vportExists,proto3" json:"vport_exists,omitempty"`          // Whether virtio console port exists
-	ClipboardReady bool                   `protobuf:"varint,4,opt,name=clipboard_ready,json=clipboardReady,proto3" json:"clipboard_ready,omitempty"` // Whether clipboard sharing is functional
-	ErrorMessage   string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`        // Error details if clipboard is not ready
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// ScreenshotChunk is a part of a PNG screenshot of the guest session.
+// The screenshot is streamed, as it can exceed the gRPC message size limit.
+type ScreenshotChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SpiceAgentInfo) Reset() {
-	*x = SpiceAgentInfo{}
-	mi := &file_guestservice_proto_msgTypes[2]
+func (x *ScreenshotChunk) Reset() {
+	*x = ScreenshotChunk{}
+	mi := &file_guestservice_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SpiceAgentInfo) String() string {
+func (x *ScreenshotChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SpiceAgentInfo) ProtoMessage() {}
+func (*ScreenshotChunk) ProtoMessage() {}
 
-func (x *SpiceAgentInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[2]
+func (x *ScreenshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -195,46 +1207,120 @@ func (x *SpiceAgentInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SpiceAgentInfo.ProtoReflect.Descriptor instead.
-func (*SpiceAgentInfo) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ScreenshotChunk.ProtoReflect.Descriptor instead.
+func (*ScreenshotChunk) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *SpiceAgentInfo) GetAgentInstalled() bool {
+func (x *ScreenshotChunk) GetData() []byte {
 	if x != nil {
-		return x.AgentInstalled
+		return x.Data
 	}
-	return false
+	return nil
 }
 
-func (x *SpiceAgentInfo) GetAgentRunning() bool {
+type Window struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Title string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// app_id is the Wayland app id, or the WM_CLASS of an X11 window
+	AppId  string `protobuf:"bytes,2,opt,name=app_id,json=appId,proto3" json:"app_id,omitempty"`
+	X      int32  `protobuf:"varint,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y      int32  `protobuf:"varint,4,opt,name=y,proto3" json:"y,omitempty"`
+	Width  int32  `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	// workspace is the desktop or workspace of the window, empty if unknown
+	Workspace     string `protobuf:"bytes,7,opt,name=workspace,proto3" json:"workspace,omitempty"`
+	Pid           int32  `protobuf:"varint,8,opt,name=pid,proto3" json:"pid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Window) Reset() {
+	*x = Window{}
+	mi := &file_guestservice_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Window) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Window) ProtoMessage() {}
+
+func (x *Window) ProtoReflect() protoreflect.Message {
+	mi := &file_guestservice_proto_msgTypes[14]
 	if x != nil {
-		return x.AgentRunning
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *SpiceAgentInfo) GetVportExists() bool {
+// Deprecated: Use Window.ProtoReflect.Descriptor instead.
+func (*Window) Descriptor() ([]byte, []int) {
+	return file_guestservice_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Window) GetTitle() string {
 	if x != nil {
-		return x.VportExists
+		return x.Title
 	}
-	return false
+	return ""
 }
 
-func (x *SpiceAgentInfo) GetClipboardReady() bool {
+func (x *Window) GetAppId() string {
 	if x != nil {
-		return x.ClipboardReady
+		return x.AppId
 	}
-	return false
+	return ""
 }
 
-func (x *SpiceAgentInfo) GetErrorMessage() string {
+func (x *Window) GetX() int32 {
 	if x != nil {
-		return x.ErrorMessage
+		return x.X
+	}
+	return 0
+}
+
+func (x *Window) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Window) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Window) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Window) GetWorkspace() string {
+	if x != nil {
+		return x.Workspace
 	}
 	return ""
 }
 
+func (x *Window) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
 type Event struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Time              *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
@@ -247,7 +1333,7 @@ type Event struct {
 
 func (x *Event) Reset() {
 	*x = Event{}
-	mi := &file_guestservice_proto_msgTypes[3]
+	mi := &file_guestservice_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -259,7 +1345,7 @@ func (x *Event) String() string {
 func (*Event) ProtoMessage() {}
 
 func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[3]
+	mi := &file_guestservice_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -272,7 +1358,7 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Event.ProtoReflect.Descriptor instead.
 func (*Event) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{3}
+	return file_guestservice_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Event) GetTime() *timestamppb.Timestamp {
@@ -314,7 +1400,7 @@ type IPPort struct {
 
 func (x *IPPort) Reset() {
 	*x = IPPort{}
-	mi := &file_guestservice_proto_msgTypes[4]
+	mi := &file_guestservice_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -326,7 +1412,7 @@ func (x *IPPort) String() string {
 func (*IPPort) ProtoMessage() {}
 
 func (x *IPPort) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[4]
+	mi := &file_guestservice_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,7 +1425,7 @@ func (x *IPPort) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IPPort.ProtoReflect.Descriptor instead.
 func (*IPPort) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{4}
+	return file_guestservice_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *IPPort) GetProtocol() string {
@@ -373,7 +1459,7 @@ type Inotify struct {
 
 func (x *Inotify) Reset() {
 	*x = Inotify{}
-	mi := &file_guestservice_proto_msgTypes[5]
+	mi := &file_guestservice_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -385,7 +1471,7 @@ func (x *Inotify) String() string {
 func (*Inotify) ProtoMessage() {}
 
 func (x *Inotify) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[5]
+	mi := &file_guestservice_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -398,7 +1484,7 @@ func (x *Inotify) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Inotify.ProtoReflect.Descriptor instead.
 func (*Inotify) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{5}
+	return file_guestservice_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Inotify) GetMountPath() string {
@@ -428,7 +1514,7 @@ type TunnelMessage struct {
 
 func (x *TunnelMessage) Reset() {
 	*x = TunnelMessage{}
-	mi := &file_guestservice_proto_msgTypes[6]
+	mi := &file_guestservice_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -440,7 +1526,7 @@ func (x *TunnelMessage) String() string {
 func (*TunnelMessage) ProtoMessage() {}
 
 func (x *TunnelMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_guestservice_proto_msgTypes[6]
+	mi := &file_guestservice_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -453,7 +1539,7 @@ func (x *TunnelMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TunnelMessage.ProtoReflect.Descriptor instead.
 func (*TunnelMessage) Descriptor() ([]byte, []int) {
-	return file_guestservice_proto_rawDescGZIP(), []int{6}
+	return file_guestservice_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *TunnelMessage) GetId() string {
@@ -499,7 +1585,10 @@ const file_guestservice_proto_rawDesc = "" +
 	"\x04Info\x12(\n" +
 	"\vlocal_ports\x18\x01 \x03(\v2\a.IPPortR\n" +
 	"localPorts\x12\x1a\n" +
-	"\x03gui\x18\x02 \x01(\v2\b.GUIInfoR\x03gui\"\xdc\x01\n" +
+	"\x03gui\x18\x02 \x01(\v2\b.GUIInfoR\x03gui\"]\n" +
+	"\x0eGUIInfoRequest\x12\x18\n" +
+	"\adisplay\x18\x01 \x01(\tR\adisplay\x121\n" +
+	"\x14enable_accessibility\x18\x02 \x01(\bR\x13enableAccessibility\"\xf4\r\n" +
 	"\aGUIInfo\x12%\n" +
 	"\x0edisplay_server\x18\x01 \x01(\tR\rdisplayServer\x12%\n" +
 	"\x0esession_active\x18\x02 \x01(\bR\rsessionActive\x12\x1e\n" +
@@ -509,13 +1598,112 @@ const file_guestservice_proto_rawDesc = "" +
 	"\fidle_time_ms\x18\x04 \x01(\x03R\n" +
 	"idleTimeMs\x12\x1a\n" +
 	"\bdisplays\x18\x05 \x03(\tR\bdisplays\x12%\n" +
-	"\x05spice\x18\x06 \x01(\v2\x0f.SpiceAgentInfoR\x05spice\"\xcf\x01\n" +
+	"\x05spice\x18\x06 \x01(\v2\x0f.SpiceAgentInfoR\x05spice\x12 \n" +
+	"\x05audio\x18\a \x01(\v2\n" +
+	".AudioInfoR\x05audio\x12'\n" +
+	"\x0fkeyboard_layout\x18\b \x01(\tR\x0ekeyboardLayout\x12\x1a\n" +
+	"\bwarnings\x18\t \x03(\tR\bwarnings\x12)\n" +
+	"\x10absolute_pointer\x18\n" +
+	" \x01(\bR\x0fabsolutePointer\x12&\n" +
+	"\aoutputs\x18\v \x03(\v2\f.DisplayModeR\aoutputs\x12-\n" +
+	"\x12compositing_active\x18\f \x01(\bR\x11compositingActive\x124\n" +
+	"\x16systemd_default_target\x18\r \x01(\tR\x14systemdDefaultTarget\x126\n" +
+	"\x17active_graphical_target\x18\x0e \x01(\bR\x15activeGraphicalTarget\x128\n" +
+	"\x18accessibility_bus_active\x18\x0f \x01(\bR\x16accessibilityBusActive\x12'\n" +
+	"\x0fsession_settled\x18\x10 \x01(\bR\x0esessionSettled\x12!\n" +
+	"\fvnc_endpoint\x18\x11 \x01(\tR\vvncEndpoint\x12%\n" +
+	"\x0eidle_inhibited\x18\x12 \x01(\bR\ridleInhibited\x128\n" +
+	"\x18screen_blanking_disabled\x18\x13 \x01(\bR\x16screenBlankingDisabled\x12!\n" +
+	"\fsession_user\x18\x14 \x01(\tR\vsessionUser\x12%\n" +
+	"\x0eschema_version\x18\x15 \x01(\x05R\rschemaVersion\x12\x1d\n" +
+	"\n" +
+	"drm_master\x18\x16 \x01(\tR\tdrmMaster\x12&\n" +
+	"\x0fcloud_init_done\x18\x17 \x01(\bR\rcloudInitDone\x12!\n" +
+	"\fcolor_scheme\x18\x18 \x01(\tR\vcolorScheme\x12*\n" +
+	"\x11virtio_gpu_loaded\x18\x19 \x01(\bR\x0fvirtioGpuLoaded\x122\n" +
+	"\x15remote_display_server\x18\x1a \x01(\tR\x13remoteDisplayServer\x12)\n" +
+	"\x10fontconfig_ready\x18\x1b \x01(\bR\x0ffontconfigReady\x12.\n" +
+	"\x13idle_time_supported\x18\x1c \x01(\bR\x11idleTimeSupported\x124\n" +
+	"\x0fsupported_modes\x18\x1d \x03(\v2\v.ScreenModeR\x0esupportedModes\x121\n" +
+	"\x14modesetting_conflict\x18\x1e \x01(\bR\x13modesettingConflict\x12>\n" +
+	"\x1bmodesetting_conflict_detail\x18\x1f \x01(\tR\x19modesettingConflictDetail\x12@\n" +
+	"\x1cdynamic_resolution_supported\x18  \x01(\bR\x1adynamicResolutionSupported\x128\n" +
+	"\x18dynamic_resolution_agent\x18! \x01(\tR\x16dynamicResolutionAgent\x12'\n" +
+	"\x0favailable_tools\x18\" \x03(\tR\x0eavailableTools\x12\x1d\n" +
+	"\n" +
+	"dri_device\x18# \x01(\tR\tdriDevice\x12*\n" +
+	"\x11dri_device_driver\x18$ \x01(\tR\x0fdriDeviceDriver\x127\n" +
+	"\x18screen_blank_timeout_sec\x18% \x01(\x05R\x15screenBlankTimeoutSec\x12A\n" +
+	"\x1dscreen_blank_timeout_reported\x18& \x01(\bR\x1ascreenBlankTimeoutReported\x12%\n" +
+	"\x0estreaming_host\x18' \x01(\tR\rstreamingHost\x12-\n" +
+	"\x12streaming_endpoint\x18( \x01(\tR\x11streamingEndpoint\"b\n" +
+	"\x13GUIInfoWatchRequest\x12\x1f\n" +
+	"\vinterval_ms\x18\x01 \x01(\x03R\n" +
+	"intervalMs\x12*\n" +
+	"\x11idle_threshold_ms\x18\x02 \x01(\x03R\x0fidleThresholdMs\"\x88\x01\n" +
+	"\rGUIInfoChange\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1c\n" +
+	"\x04info\x18\x02 \x01(\v2\b.GUIInfoR\x04info\x12)\n" +
+	"\achanges\x18\x03 \x03(\v2\x0f.GUIFieldChangeR\achanges\"`\n" +
+	"\x0eGUIFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1b\n" +
+	"\told_value\x18\x02 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x03 \x01(\tR\bnewValue\"\xa1\x02\n" +
+	"\vDisplayMode\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12\f\n" +
+	"\x01x\x18\x04 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x05 \x01(\x05R\x01y\x12\x18\n" +
+	"\aprimary\x18\x06 \x01(\bR\aprimary\x12\x1c\n" +
+	"\tconnector\x18\a \x01(\tR\tconnector\x12#\n" +
+	"\radaptive_sync\x18\b \x01(\bR\fadaptiveSync\x124\n" +
+	"\x16adaptive_sync_reported\x18\t \x01(\bR\x14adaptiveSyncReported\x12!\n" +
+	"\frefresh_rate\x18\n" +
+	" \x01(\x01R\vrefreshRate\":\n" +
+	"\n" +
+	"ScreenMode\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\"\xe3\x01\n" +
+	"\tAudioInfo\x120\n" +
+	"\x14virtio_snd_available\x18\x01 \x01(\bR\x12virtioSndAvailable\x12*\n" +
+	"\x11virtio_snd_loaded\x18\x02 \x01(\bR\x0fvirtioSndLoaded\x122\n" +
+	"\x15audio_devices_present\x18\x03 \x01(\bR\x13audioDevicesPresent\x12\x1f\n" +
+	"\vaudio_cards\x18\x04 \x03(\tR\n" +
+	"audioCards\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\xb7\x04\n" +
 	"\x0eSpiceAgentInfo\x12'\n" +
 	"\x0fagent_installed\x18\x01 \x01(\bR\x0eagentInstalled\x12#\n" +
 	"\ragent_running\x18\x02 \x01(\bR\fagentRunning\x12!\n" +
 	"\fvport_exists\x18\x03 \x01(\bR\vvportExists\x12'\n" +
 	"\x0fclipboard_ready\x18\x04 \x01(\bR\x0eclipboardReady\x12#\n" +
-	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\xbd\x01\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\x12'\n" +
+	"\x0fagent_autostart\x18\x06 \x01(\bR\x0eagentAutostart\x122\n" +
+	"\x15session_agent_running\x18\a \x01(\bR\x13sessionAgentRunning\x12.\n" +
+	"\x13max_clipboard_bytes\x18\b \x01(\x03R\x11maxClipboardBytes\x120\n" +
+	"\x14clipboard_mime_types\x18\t \x03(\tR\x12clipboardMimeTypes\x12/\n" +
+	"\x13clipboard_mechanism\x18\n" +
+	" \x01(\tR\x12clipboardMechanism\x12:\n" +
+	"\x19clipboard_selection_owned\x18\v \x01(\bR\x17clipboardSelectionOwned\x12:\n" +
+	"\x19clipboard_selection_owner\x18\f \x01(\tR\x17clipboardSelectionOwner\"(\n" +
+	"\fBlankTimeout\x12\x18\n" +
+	"\aseconds\x18\x01 \x01(\x05R\aseconds\"\x1f\n" +
+	"\tClipboard\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"/\n" +
+	"\n" +
+	"WindowList\x12!\n" +
+	"\awindows\x18\x01 \x03(\v2\a.WindowR\awindows\"%\n" +
+	"\x0fScreenshotChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\xaf\x01\n" +
+	"\x06Window\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x15\n" +
+	"\x06app_id\x18\x02 \x01(\tR\x05appId\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x05 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x06 \x01(\x05R\x06height\x12\x1c\n" +
+	"\tworkspace\x18\a \x01(\tR\tworkspace\x12\x10\n" +
+	"\x03pid\x18\b \x01(\x05R\x03pid\"\xbd\x01\n" +
 	"\x05Event\x12.\n" +
 	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x123\n" +
 	"\x11added_local_ports\x18\x02 \x03(\v2\a.IPPortR\x0faddedLocalPorts\x127\n" +
@@ -535,11 +1723,21 @@ const file_guestservice_proto_rawDesc = "" +
 	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1d\n" +
 	"\n" +
 	"guest_addr\x18\x04 \x01(\tR\tguestAddr\x12&\n" +
-	"\x0fudp_target_addr\x18\x05 \x01(\tR\rudpTargetAddr2\xc8\x01\n" +
+	"\x0fudp_target_addr\x18\x05 \x01(\tR\rudpTargetAddr2\xbe\x04\n" +
 	"\fGuestService\x12(\n" +
-	"\aGetInfo\x12\x16.google.protobuf.Empty\x1a\x05.Info\x12-\n" +
-	"\tGetEvents\x12\x16.google.protobuf.Empty\x1a\x06.Event0\x01\x121\n" +
-	"\vPostInotify\x12\b.Inotify\x1a\x16.google.protobuf.Empty(\x01\x12,\n" +
+	"\aGetInfo\x12\x16.google.protobuf.Empty\x1a\x05.Info\x12'\n" +
+	"\n" +
+	"GetGUIInfo\x12\x0f.GUIInfoRequest\x1a\b.GUIInfo\x12-\n" +
+	"\tGetEvents\x12\x16.google.protobuf.Empty\x1a\x06.Event0\x01\x122\n" +
+	"\fGetClipboard\x12\x16.google.protobuf.Empty\x1a\n" +
+	".Clipboard\x122\n" +
+	"\fSetClipboard\x12\n" +
+	".Clipboard\x1a\x16.google.protobuf.Empty\x122\n" +
+	"\vListWindows\x12\x16.google.protobuf.Empty\x1a\v.WindowList\x121\n" +
+	"\vPostInotify\x12\b.Inotify\x1a\x16.google.protobuf.Empty(\x01\x126\n" +
+	"\fWatchGUIInfo\x12\x14.GUIInfoWatchRequest\x1a\x0e.GUIInfoChange0\x01\x12=\n" +
+	"\x0fGuestScreenshot\x12\x16.google.protobuf.Empty\x1a\x10.ScreenshotChunk0\x01\x128\n" +
+	"\x0fSetBlankTimeout\x12\r.BlankTimeout\x1a\x16.google.protobuf.Empty\x12,\n" +
 	"\x06Tunnel\x12\x0e.TunnelMessage\x1a\x0e.TunnelMessage(\x010\x01B/Z-github.com/lima-vm/lima/v2/pkg/guestagent/apib\x06proto3"
 
 var (
@@ -554,39 +1752,72 @@ func file_guestservice_proto_rawDescGZIP() []byte {
 	return file_guestservice_proto_rawDescData
 }
 
-var file_guestservice_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_guestservice_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_guestservice_proto_goTypes = []any{
 	(*Info)(nil),                  // 0: Info
-	(*GUIInfo)(nil),               // 1: GUIInfo
-	(*SpiceAgentInfo)(nil),        // 2: SpiceAgentInfo
-	(*Event)(nil),                 // 3: Event
-	(*IPPort)(nil),                // 4: IPPort
-	(*Inotify)(nil),               // 5: Inotify
-	(*TunnelMessage)(nil),         // 6: TunnelMessage
-	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
+	(*GUIInfoRequest)(nil),        // 1: GUIInfoRequest
+	(*GUIInfo)(nil),               // 2: GUIInfo
+	(*GUIInfoWatchRequest)(nil),   // 3: GUIInfoWatchRequest
+	(*GUIInfoChange)(nil),         // 4: GUIInfoChange
+	(*GUIFieldChange)(nil),        // 5: GUIFieldChange
+	(*DisplayMode)(nil),           // 6: DisplayMode
+	(*ScreenMode)(nil),            // 7: ScreenMode
+	(*AudioInfo)(nil),             // 8: AudioInfo
+	(*SpiceAgentInfo)(nil),        // 9: SpiceAgentInfo
+	(*BlankTimeout)(nil),          // 10: BlankTimeout
+	(*Clipboard)(nil),             // 11: Clipboard
+	(*WindowList)(nil),            // 12: WindowList
+	(*ScreenshotChunk)(nil),       // 13: ScreenshotChunk
+	(*Window)(nil),                // 14: Window
+	(*Event)(nil),                 // 15: Event
+	(*IPPort)(nil),                // 16: IPPort
+	(*Inotify)(nil),               // 17: Inotify
+	(*TunnelMessage)(nil),         // 18: TunnelMessage
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 20: google.protobuf.Empty
 }
 var file_guestservice_proto_depIdxs = []int32{
-	4,  // 0: Info.local_ports:type_name -> IPPort
-	1,  // 1: Info.gui:type_name -> GUIInfo
-	2,  // 2: GUIInfo.spice:type_name -> SpiceAgentInfo
-	7,  // 3: Event.time:type_name -> google.protobuf.Timestamp
-	4,  // 4: Event.added_local_ports:type_name -> IPPort
-	4,  // 5: Event.removed_local_ports:type_name -> IPPort
-	7,  // 6: Inotify.time:type_name -> google.protobuf.Timestamp
-	8,  // 7: GuestService.GetInfo:input_type -> google.protobuf.Empty
-	8,  // 8: GuestService.GetEvents:input_type -> google.protobuf.Empty
-	5,  // 9: GuestService.PostInotify:input_type -> Inotify
-	6,  // 10: GuestService.Tunnel:input_type -> TunnelMessage
-	0,  // 11: GuestService.GetInfo:output_type -> Info
-	3,  // 12: GuestService.GetEvents:output_type -> Event
-	8,  // 13: GuestService.PostInotify:output_type -> google.protobuf.Empty
-	6,  // 14: GuestService.Tunnel:output_type -> TunnelMessage
-	11, // [11:15] is the sub-list for method output_type
-	7,  // [7:11] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	16, // 0: Info.local_ports:type_name -> IPPort
+	2,  // 1: Info.gui:type_name -> GUIInfo
+	9,  // 2: GUIInfo.spice:type_name -> SpiceAgentInfo
+	8,  // 3: GUIInfo.audio:type_name -> AudioInfo
+	6,  // 4: GUIInfo.outputs:type_name -> DisplayMode
+	7,  // 5: GUIInfo.supported_modes:type_name -> ScreenMode
+	19, // 6: GUIInfoChange.time:type_name -> google.protobuf.Timestamp
+	2,  // 7: GUIInfoChange.info:type_name -> GUIInfo
+	5,  // 8: GUIInfoChange.changes:type_name -> GUIFieldChange
+	14, // 9: WindowList.windows:type_name -> Window
+	19, // 10: Event.time:type_name -> google.protobuf.Timestamp
+	16, // 11: Event.added_local_ports:type_name -> IPPort
+	16, // 12: Event.removed_local_ports:type_name -> IPPort
+	19, // 13: Inotify.time:type_name -> google.protobuf.Timestamp
+	20, // 14: GuestService.GetInfo:input_type -> google.protobuf.Empty
+	1,  // 15: GuestService.GetGUIInfo:input_type -> GUIInfoRequest
+	20, // 16: GuestService.GetEvents:input_type -> google.protobuf.Empty
+	20, // 17: GuestService.GetClipboard:input_type -> google.protobuf.Empty
+	11, // 18: GuestService.SetClipboard:input_type -> Clipboard
+	20, // 19: GuestService.ListWindows:input_type -> google.protobuf.Empty
+	17, // 20: GuestService.PostInotify:input_type -> Inotify
+	3,  // 21: GuestService.WatchGUIInfo:input_type -> GUIInfoWatchRequest
+	20, // 22: GuestService.GuestScreenshot:input_type -> google.protobuf.Empty
+	10, // 23: GuestService.SetBlankTimeout:input_type -> BlankTimeout
+	18, // 24: GuestService.Tunnel:input_type -> TunnelMessage
+	0,  // 25: GuestService.GetInfo:output_type -> Info
+	2,  // 26: GuestService.GetGUIInfo:output_type -> GUIInfo
+	15, // 27: GuestService.GetEvents:output_type -> Event
+	11, // 28: GuestService.GetClipboard:output_type -> Clipboard
+	20, // 29: GuestService.SetClipboard:output_type -> google.protobuf.Empty
+	12, // 30: GuestService.ListWindows:output_type -> WindowList
+	20, // 31: GuestService.PostInotify:output_type -> google.protobuf.Empty
+	4,  // 32: GuestService.WatchGUIInfo:output_type -> GUIInfoChange
+	13, // 33: GuestService.GuestScreenshot:output_type -> ScreenshotChunk
+	20, // 34: GuestService.SetBlankTimeout:output_type -> google.protobuf.Empty
+	18, // 35: GuestService.Tunnel:output_type -> TunnelMessage
+	25, // [25:36] is the sub-list for method output_type
+	14, // [14:25] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_guestservice_proto_init() }
@@ -600,7 +1831,7 @@ func file_guestservice_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_guestservice_proto_rawDesc), len(file_guestservice_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GuestService_GetInfo_FullMethodName     = "/GuestService/GetInfo"
-	GuestService_GetEvents_FullMethodName   = "/GuestService/GetEvents"
-	GuestService_PostInotify_FullMethodName = "/GuestService/PostInotify"
-	GuestService_Tunnel_FullMethodName      = "/GuestService/Tunnel"
+	GuestService_GetInfo_FullMethodName         = "/GuestService/GetInfo"
+	GuestService_GetGUIInfo_FullMethodName      = "/GuestService/GetGUIInfo"
+	GuestService_GetEvents_FullMethodName       = "/GuestService/GetEvents"
+	GuestService_GetClipboard_FullMethodName    = "/GuestService/GetClipboard"
+	GuestService_SetClipboard_FullMethodName    = "/GuestService/SetClipboard"
+	GuestService_ListWindows_FullMethodName     = "/GuestService/ListWindows"
+	GuestService_PostInotify_FullMethodName     = "/GuestService/PostInotify"
+	GuestService_WatchGUIInfo_FullMethodName    = "/GuestService/WatchGUIInfo"
+	GuestService_GuestScreenshot_FullMethodName = "/GuestService/GuestScreenshot"
+	GuestService_SetBlankTimeout_FullMethodName = "/GuestService/SetBlankTimeout"
+	GuestService_Tunnel_FullMethodName          = "/GuestService/Tunnel"
 )
 
 // GuestServiceClient is the client API for GuestService service.
@@ -31,8 +38,15 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type GuestServiceClient interface {
 	GetInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Info, error)
+	GetGUIInfo(ctx context.Context, in *GUIInfoRequest, opts ...grpc.CallOption) (*GUIInfo, error)
 	GetEvents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	GetClipboard(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Clipboard, error)
+	SetClipboard(ctx context.Context, in *Clipboard, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListWindows(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*WindowList, error)
 	PostInotify(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Inotify, emptypb.Empty], error)
+	WatchGUIInfo(ctx context.Context, in *GUIInfoWatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GUIInfoChange], error)
+	GuestScreenshot(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScreenshotChunk], error)
+	SetBlankTimeout(ctx context.Context, in *BlankTimeout, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Tunnel(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TunnelMessage, TunnelMessage], error)
 }
 
@@ -54,6 +68,16 @@ func (c *guestServiceClient) GetInfo(ctx context.Context, in *emptypb.Empty, opt
 	return out, nil
 }
 
+func (c *guestServiceClient) GetGUIInfo(ctx context.Context, in *GUIInfoRequest, opts ...grpc.CallOption) (*GUIInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GUIInfo)
+	err := c.cc.Invoke(ctx, GuestService_GetGUIInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *guestServiceClient) GetEvents(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[0], GuestService_GetEvents_FullMethodName, cOpts...)
@@ -73,6 +97,36 @@ func (c *guestServiceClient) GetEvents(ctx context.Context, in *emptypb.Empty, o
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GuestService_GetEventsClient = grpc.ServerStreamingClient[Event]
 
+func (c *guestServiceClient) GetClipboard(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Clipboard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Clipboard)
+	err := c.cc.Invoke(ctx, GuestService_GetClipboard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *guestServiceClient) SetClipboard(ctx context.Context, in *Clipboard, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GuestService_SetClipboard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *guestServiceClient) ListWindows(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*WindowList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WindowList)
+	err := c.cc.Invoke(ctx, GuestService_ListWindows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *guestServiceClient) PostInotify(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Inotify, emptypb.Empty], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[1], GuestService_PostInotify_FullMethodName, cOpts...)
@@ -86,9 +140,57 @@ func (c *guestServiceClient) PostInotify(ctx context.Context, opts ...grpc.CallO
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GuestService_PostInotifyClient = grpc.ClientStreamingClient[Inotify, emptypb.Empty]
 
+func (c *guestServiceClient) WatchGUIInfo(ctx context.Context, in *GUIInfoWatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GUIInfoChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[2], GuestService_WatchGUIInfo_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GUIInfoWatchRequest, GUIInfoChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GuestService_WatchGUIInfoClient = grpc.ServerStreamingClient[GUIInfoChange]
+
+func (c *guestServiceClient) GuestScreenshot(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScreenshotChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[3], GuestService_GuestScreenshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, ScreenshotChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GuestService_GuestScreenshotClient = grpc.ServerStreamingClient[ScreenshotChunk]
+
+func (c *guestServiceClient) SetBlankTimeout(ctx context.Context, in *BlankTimeout, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GuestService_SetBlankTimeout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *guestServiceClient) Tunnel(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TunnelMessage, TunnelMessage], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[2], GuestService_Tunnel_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &GuestService_ServiceDesc.Streams[4], GuestService_Tunnel_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +206,15 @@ type GuestService_TunnelClient = grpc.BidiStreamingClient[TunnelMessage, TunnelM
 // for forward compatibility.
 type GuestServiceServer interface {
 	GetInfo(context.Context, *emptypb.Empty) (*Info, error)
+	GetGUIInfo(context.Context, *GUIInfoRequest) (*GUIInfo, error)
 	GetEvents(*emptypb.Empty, grpc.ServerStreamingServer[Event]) error
+	GetClipboard(context.Context, *emptypb.Empty) (*Clipboard, error)
+	SetClipboard(context.Context, *Clipboard) (*emptypb.Empty, error)
+	ListWindows(context.Context, *emptypb.Empty) (*WindowList, error)
 	PostInotify(grpc.ClientStreamingServer[Inotify, emptypb.Empty]) error
+	WatchGUIInfo(*GUIInfoWatchRequest, grpc.ServerStreamingServer[GUIInfoChange]) error
+	GuestScreenshot(*emptypb.Empty, grpc.ServerStreamingServer[ScreenshotChunk]) error
+	SetBlankTimeout(context.Context, *BlankTimeout) (*emptypb.Empty, error)
 	Tunnel(grpc.BidiStreamingServer[TunnelMessage, TunnelMessage]) error
 	mustEmbedUnimplementedGuestServiceServer()
 }
@@ -120,12 +229,33 @@ type UnimplementedGuestServiceServer struct{}
 func (UnimplementedGuestServiceServer) GetInfo(context.Context, *emptypb.Empty) (*Info, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
 }
+func (UnimplementedGuestServiceServer) GetGUIInfo(context.Context, *GUIInfoRequest) (*GUIInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGUIInfo not implemented")
+}
 func (UnimplementedGuestServiceServer) GetEvents(*emptypb.Empty, grpc.ServerStreamingServer[Event]) error {
 	return status.Errorf(codes.Unimplemented, "method GetEvents not implemented")
 }
+func (UnimplementedGuestServiceServer) GetClipboard(context.Context, *emptypb.Empty) (*Clipboard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClipboard not implemented")
+}
+func (UnimplementedGuestServiceServer) SetClipboard(context.Context, *Clipboard) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetClipboard not implemented")
+}
+func (UnimplementedGuestServiceServer) ListWindows(context.Context, *emptypb.Empty) (*WindowList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWindows not implemented")
+}
 func (UnimplementedGuestServiceServer) PostInotify(grpc.ClientStreamingServer[Inotify, emptypb.Empty]) error {
 	return status.Errorf(codes.Unimplemented, "method PostInotify not implemented")
 }
+func (UnimplementedGuestServiceServer) WatchGUIInfo(*GUIInfoWatchRequest, grpc.ServerStreamingServer[GUIInfoChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchGUIInfo not implemented")
+}
+func (UnimplementedGuestServiceServer) GuestScreenshot(*emptypb.Empty, grpc.ServerStreamingServer[ScreenshotChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method GuestScreenshot not implemented")
+}
+func (UnimplementedGuestServiceServer) SetBlankTimeout(context.Context, *BlankTimeout) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBlankTimeout not implemented")
+}
 func (UnimplementedGuestServiceServer) Tunnel(grpc.BidiStreamingServer[TunnelMessage, TunnelMessage]) error {
 	return status.Errorf(codes.Unimplemented, "method Tunnel not implemented")
 }
@@ -168,6 +298,24 @@ func _GuestService_GetInfo_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GuestService_GetGUIInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GUIInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).GetGUIInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_GetGUIInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).GetGUIInfo(ctx, req.(*GUIInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GuestService_GetEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(emptypb.Empty)
 	if err := stream.RecvMsg(m); err != nil {
@@ -179,6 +327,60 @@ func _GuestService_GetEvents_Handler(srv interface{}, stream grpc.ServerStream)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GuestService_GetEventsServer = grpc.ServerStreamingServer[Event]
 
+func _GuestService_GetClipboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).GetClipboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_GetClipboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).GetClipboard(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GuestService_SetClipboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Clipboard)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).SetClipboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_SetClipboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).SetClipboard(ctx, req.(*Clipboard))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GuestService_ListWindows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).ListWindows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_ListWindows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).ListWindows(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GuestService_PostInotify_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(GuestServiceServer).PostInotify(&grpc.GenericServerStream[Inotify, emptypb.Empty]{ServerStream: stream})
 }
@@ -186,6 +388,46 @@ func _GuestService_PostInotify_Handler(srv interface{}, stream grpc.ServerStream
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GuestService_PostInotifyServer = grpc.ClientStreamingServer[Inotify, emptypb.Empty]
 
+func _GuestService_WatchGUIInfo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GUIInfoWatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GuestServiceServer).WatchGUIInfo(m, &grpc.GenericServerStream[GUIInfoWatchRequest, GUIInfoChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GuestService_WatchGUIInfoServer = grpc.ServerStreamingServer[GUIInfoChange]
+
+func _GuestService_GuestScreenshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GuestServiceServer).GuestScreenshot(m, &grpc.GenericServerStream[emptypb.Empty, ScreenshotChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GuestService_GuestScreenshotServer = grpc.ServerStreamingServer[ScreenshotChunk]
+
+func _GuestService_SetBlankTimeout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlankTimeout)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).SetBlankTimeout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_SetBlankTimeout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).SetBlankTimeout(ctx, req.(*BlankTimeout))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GuestService_Tunnel_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(GuestServiceServer).Tunnel(&grpc.GenericServerStream[TunnelMessage, TunnelMessage]{ServerStream: stream})
 }
@@ -204,6 +446,26 @@ var GuestService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetInfo",
 			Handler:    _GuestService_GetInfo_Handler,
 		},
+		{
+			MethodName: "GetGUIInfo",
+			Handler:    _GuestService_GetGUIInfo_Handler,
+		},
+		{
+			MethodName: "GetClipboard",
+			Handler:    _GuestService_GetClipboard_Handler,
+		},
+		{
+			MethodName: "SetClipboard",
+			Handler:    _GuestService_SetClipboard_Handler,
+		},
+		{
+			MethodName: "ListWindows",
+			Handler:    _GuestService_ListWindows_Handler,
+		},
+		{
+			MethodName: "SetBlankTimeout",
+			Handler:    _GuestService_SetBlankTimeout_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -216,6 +478,16 @@ var GuestService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _GuestService_PostInotify_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchGUIInfo",
+			Handler:       _GuestService_WatchGUIInfo_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GuestScreenshot",
+			Handler:       _GuestService_GuestScreenshot_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Tunnel",
 			Handler:       _GuestService_Tunnel_Handler,
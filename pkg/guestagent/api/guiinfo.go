@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Versions of the GUIInfo schema, reported by the guest agent in GUIInfo.SchemaVersion.
+// A field added after the version of a guest agent is left zero by it, so the host
+// has to check the version before taking false or an empty string as a probe result.
+const (
+	// GUISchemaAwake adds idle_inhibited, screen_blanking_disabled and session_user
+	GUISchemaAwake = 1
+
+	// GUISchemaDRMMaster adds drm_master
+	GUISchemaDRMMaster = 2
+
+	// GUISchemaCloudInit adds cloud_init_done
+	GUISchemaCloudInit = 3
+
+	// GUISchemaColorScheme adds color_scheme
+	GUISchemaColorScheme = 4
+
+	// GUISchemaVirtioGPU adds virtio_gpu_loaded
+	GUISchemaVirtioGPU = 5
+
+	// GUISchemaRemoteDisplay adds remote_display_server
+	GUISchemaRemoteDisplay = 6
+
+	// GUISchemaFontconfig adds fontconfig_ready
+	GUISchemaFontconfig = 7
+
+	// GUISchemaIdleTime adds idle_time_supported
+	GUISchemaIdleTime = 8
+
+	// GUISchemaClipboardOwner adds clipboard_selection_owned and clipboard_selection_owner to spice
+	GUISchemaClipboardOwner = 9
+
+	// GUISchemaSupportedModes adds supported_modes
+	GUISchemaSupportedModes = 10
+
+	// GUISchemaModesetting adds modesetting_conflict and modesetting_conflict_detail
+	GUISchemaModesetting = 11
+
+	// GUISchemaDynamicResolution adds dynamic_resolution_supported and dynamic_resolution_agent
+	GUISchemaDynamicResolution = 12
+
+	// GUISchemaOutputConnector adds connector to outputs
+	GUISchemaOutputConnector = 13
+
+	// GUISchemaAvailableTools adds available_tools
+	GUISchemaAvailableTools = 14
+
+	// GUISchemaAdaptiveSync adds adaptive_sync and adaptive_sync_reported to outputs
+	GUISchemaAdaptiveSync = 15
+
+	// GUISchemaDRIDevice adds dri_device and dri_device_driver
+	GUISchemaDRIDevice = 16
+
+	// GUISchemaBlankTimeout adds screen_blank_timeout_sec and screen_blank_timeout_reported
+	GUISchemaBlankTimeout = 17
+
+	// GUISchemaStreamingHost adds streaming_host and streaming_endpoint
+	GUISchemaStreamingHost = 18
+
+	// GUISchemaVersion is the schema version of this guest agent
+	GUISchemaVersion = GUISchemaStreamingHost
+)
+
+// HasSchema reports whether the guest agent reported at least the schema version
+func (x *GUIInfo) HasSchema(version int32) bool {
+	return x.GetSchemaVersion() >= version
+}
+
+// IdleTime returns the time since the last user activity in the session,
+// and false when the guest agent could not measure it, as IdleTimeMs is then 0
+func (x *GUIInfo) IdleTime() (time.Duration, bool) {
+	if !x.HasSchema(GUISchemaIdleTime) || !x.GetIdleTimeSupported() {
+		return 0, false
+	}
+	return time.Duration(x.GetIdleTimeMs()) * time.Millisecond, true
+}
+
+// FormatIdleTime returns the idle time rounded to the second, e.g., "3m12s", or "unknown" when it is not measured
+func (x *GUIInfo) FormatIdleTime() string {
+	idle, ok := x.IdleTime()
+	if !ok {
+		return "unknown"
+	}
+	return idle.Round(time.Second).String()
+}
+
+// ScreenBlankTimeout returns the idle time after which the guest blanks the screen, 0 if never,
+// and false when the guest agent could not read it, e.g., on Wayland outside of GNOME
+func (x *GUIInfo) ScreenBlankTimeout() (time.Duration, bool) {
+	if !x.HasSchema(GUISchemaBlankTimeout) || !x.GetScreenBlankTimeoutReported() {
+		return 0, false
+	}
+	return time.Duration(x.GetScreenBlankTimeoutSec()) * time.Second, true
+}
+
+// Size returns the mode as "WIDTHxHEIGHT", e.g., "1920x1080"
+func (x *ScreenMode) Size() string {
+	return fmt.Sprintf("%dx%d", x.GetWidth(), x.GetHeight())
+}
+
+// NearestMode returns the supported mode closest in size to width x height among the modes accepted by accept,
+// e.g., the sizes the display device can take, or nil if there is none. A nil accept accepts every mode.
+func (x *GUIInfo) NearestMode(width, height int32, accept func(*ScreenMode) bool) *ScreenMode {
+	var nearest *ScreenMode
+	var nearestDist int64
+	for _, m := range x.GetSupportedModes() {
+		if accept != nil && !accept(m) {
+			continue
+		}
+		dw, dh := int64(m.GetWidth()-width), int64(m.GetHeight()-height)
+		if dist := dw*dw + dh*dh; nearest == nil || dist < nearestDist {
+			nearest, nearestDist = m, dist
+		}
+	}
+	return nearest
+}
+
+// CheckResolution checks that an output of the guest can be set to width x height.
+// Any resolution is accepted when the guest agent does not report the supported modes.
+// The nearest supported mode suggested in the error is one accepted by accept, see NearestMode.
+func (x *GUIInfo) CheckResolution(width, height int32, accept func(*ScreenMode) bool) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid resolution %dx%d", width, height)
+	}
+	if !x.HasSchema(GUISchemaSupportedModes) || len(x.GetSupportedModes()) == 0 {
+		return nil
+	}
+	if slices.ContainsFunc(x.GetSupportedModes(), func(m *ScreenMode) bool { return m.GetWidth() == width && m.GetHeight() == height }) {
+		return nil
+	}
+	nearest := x.NearestMode(width, height, accept)
+	if nearest == nil {
+		return fmt.Errorf("resolution %dx%d is not supported by the guest", width, height)
+	}
+	return fmt.Errorf("resolution %dx%d is not supported by the guest, the nearest supported mode is %s", width, height, nearest.Size())
+}
+
+// RenamedOutputs returns the outputs whose name differs from their DRM connector, e.g., an output "Virtual-0"
+// driven by the connector "Virtual-1"; scripts cannot address these outputs by the name of the connector
+func (x *GUIInfo) RenamedOutputs() []*DisplayMode {
+	var renamed []*DisplayMode
+	for _, o := range x.GetOutputs() {
+		if o.GetConnector() != "" && o.GetConnector() != o.GetName() {
+			renamed = append(renamed, o)
+		}
+	}
+	return renamed
+}
+
+// AdaptiveSyncStatus returns "enabled" or "disabled" when the display server reports adaptive sync (VRR)
+// for the output, and "unknown" otherwise
+func (x *DisplayMode) AdaptiveSyncStatus() string {
+	switch {
+	case !x.GetAdaptiveSyncReported():
+		return "unknown"
+	case x.GetAdaptiveSync():
+		return "enabled"
+	default:
+		return "disabled"
+	}
+}
+
+// ToolAvailable reports whether a detection tool, e.g., "xrandr", is installed in the guest.
+// Tools are assumed to be installed when the guest agent does not report them.
+func (x *GUIInfo) ToolAvailable(name string) bool {
+	return !x.HasSchema(GUISchemaAvailableTools) || slices.Contains(x.GetAvailableTools(), name)
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIdleTime(t *testing.T) {
+	info := &GUIInfo{SchemaVersion: GUISchemaIdleTime, IdleTimeMs: 192400, IdleTimeSupported: true}
+	idle, ok := info.IdleTime()
+	assert.Assert(t, ok)
+	assert.Equal(t, idle, 192400*time.Millisecond)
+	assert.Equal(t, info.FormatIdleTime(), "3m12s")
+
+	info.IdleTimeSupported = false
+	assert.Equal(t, info.FormatIdleTime(), "unknown")
+
+	// Older guest agents do not tell whether they measured it
+	old := &GUIInfo{SchemaVersion: GUISchemaFontconfig, IdleTimeMs: 4200}
+	_, ok = old.IdleTime()
+	assert.Assert(t, !ok)
+}
+
+func TestScreenBlankTimeout(t *testing.T) {
+	info := &GUIInfo{SchemaVersion: GUISchemaBlankTimeout, ScreenBlankTimeoutSec: 300, ScreenBlankTimeoutReported: true}
+	timeout, ok := info.ScreenBlankTimeout()
+	assert.Assert(t, ok)
+	assert.Equal(t, timeout, 5*time.Minute)
+
+	info.ScreenBlankTimeoutReported = false
+	_, ok = info.ScreenBlankTimeout()
+	assert.Assert(t, !ok)
+
+	old := &GUIInfo{SchemaVersion: GUISchemaDRIDevice, ScreenBlankTimeoutReported: true}
+	_, ok = old.ScreenBlankTimeout()
+	assert.Assert(t, !ok)
+}
+
+func TestCheckResolution(t *testing.T) {
+	info := &GUIInfo{
+		SchemaVersion: GUISchemaSupportedModes,
+		SupportedModes: []*ScreenMode{
+			{Width: 1920, Height: 1080},
+			{Width: 1280, Height: 800},
+			{Width: 1024, Height: 768},
+		},
+	}
+	assert.NilError(t, info.CheckResolution(1280, 800, nil))
+	assert.Error(t, info.CheckResolution(1000, 700, nil),
+		"resolution 1000x700 is not supported by the guest, the nearest supported mode is 1024x768")
+	assert.ErrorContains(t, info.CheckResolution(0, 768, nil), "invalid resolution")
+
+	// The nearest mode is one the display device accepts
+	wide := func(m *ScreenMode) bool { return m.Width >= 1280 }
+	assert.Error(t, info.CheckResolution(1000, 700, wide),
+		"resolution 1000x700 is not supported by the guest, the nearest supported mode is 1280x800")
+	none := func(*ScreenMode) bool { return false }
+	assert.Error(t, info.CheckResolution(1000, 700, none), "resolution 1000x700 is not supported by the guest")
+
+	// Without supported modes, the guest is trusted to reject what it cannot display
+	assert.NilError(t, (&GUIInfo{SchemaVersion: GUISchemaSupportedModes}).CheckResolution(1000, 700, nil))
+	assert.NilError(t, (&GUIInfo{SchemaVersion: GUISchemaIdleTime}).CheckResolution(1000, 700, nil))
+}
+
+func TestRenamedOutputs(t *testing.T) {
+	info := &GUIInfo{Outputs: []*DisplayMode{
+		{Name: "Virtual-1", Connector: "Virtual-1"},
+		{Name: "Virtual-0", Connector: "Virtual-2"},
+		{Name: "XWAYLAND0"},
+	}}
+	renamed := info.RenamedOutputs()
+	assert.Equal(t, len(renamed), 1)
+	assert.Equal(t, renamed[0].Name, "Virtual-0")
+}
+
+func TestAdaptiveSyncStatus(t *testing.T) {
+	assert.Equal(t, (&DisplayMode{Name: "Virtual-1"}).AdaptiveSyncStatus(), "unknown")
+	assert.Equal(t, (&DisplayMode{AdaptiveSyncReported: true}).AdaptiveSyncStatus(), "disabled")
+	assert.Equal(t, (&DisplayMode{AdaptiveSync: true, AdaptiveSyncReported: true}).AdaptiveSyncStatus(), "enabled")
+}
+
+func TestToolAvailable(t *testing.T) {
+	info := &GUIInfo{SchemaVersion: GUISchemaAvailableTools, AvailableTools: []string{"systemctl", "xrandr"}}
+	assert.Assert(t, info.ToolAvailable("xrandr"))
+	assert.Assert(t, !info.ToolAvailable("wlr-randr"))
+	// Older guest agents do not report the tools
+	assert.Assert(t, (&GUIInfo{SchemaVersion: GUISchemaOutputConnector}).ToolAvailable("wlr-randr"))
+}
@@ -6,6 +6,7 @@ package server
 import (
 	"context"
 	"net"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -52,6 +53,50 @@ func (s *GuestServer) GetInfo(ctx context.Context, _ *emptypb.Empty) (*api.Info,
 	return s.Agent.Info(ctx)
 }
 
+func (s *GuestServer) GetGUIInfo(ctx context.Context, req *api.GUIInfoRequest) (*api.GUIInfo, error) {
+	return s.Agent.GUIInfo(ctx, req)
+}
+
+func (s *GuestServer) GetClipboard(ctx context.Context, _ *emptypb.Empty) (*api.Clipboard, error) {
+	data, err := s.Agent.Clipboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Clipboard{Data: data}, nil
+}
+
+func (s *GuestServer) SetClipboard(ctx context.Context, req *api.Clipboard) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, s.Agent.SetClipboard(ctx, req.Data)
+}
+
+func (s *GuestServer) SetBlankTimeout(ctx context.Context, req *api.BlankTimeout) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, s.Agent.SetBlankTimeout(ctx, req.Seconds)
+}
+
+func (s *GuestServer) ListWindows(ctx context.Context, _ *emptypb.Empty) (*api.WindowList, error) {
+	windows, err := s.Agent.ListWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.WindowList{Windows: windows}, nil
+}
+
+// screenshotChunkBytes is the size of the chunks of a streamed screenshot, below the gRPC message size limit
+const screenshotChunkBytes = 1 << 20
+
+func (s *GuestServer) GuestScreenshot(_ *emptypb.Empty, stream api.GuestService_GuestScreenshotServer) error {
+	data, err := s.Agent.Screenshot(stream.Context())
+	if err != nil {
+		return err
+	}
+	for chunk := range slices.Chunk(data, screenshotChunkBytes) {
+		if err := stream.Send(&api.ScreenshotChunk{Data: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *GuestServer) GetEvents(_ *emptypb.Empty, stream api.GuestService_GetEventsServer) error {
 	responses := make(chan *api.Event)
 	// expects Events() to close the channel when stream.Context() is done or ticker stops
@@ -65,6 +110,18 @@ func (s *GuestServer) GetEvents(_ *emptypb.Empty, stream api.GuestService_GetEve
 	return nil
 }
 
+func (s *GuestServer) WatchGUIInfo(req *api.GUIInfoWatchRequest, stream api.GuestService_WatchGUIInfoServer) error {
+	changes := make(chan *api.GUIInfoChange)
+	// expects WatchGUIInfo() to close the channel when stream.Context() is done
+	go s.Agent.WatchGUIInfo(stream.Context(), req, changes)
+	for change := range changes {
+		if err := stream.Send(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *GuestServer) PostInotify(server api.GuestService_PostInotifyServer) error {
 	for {
 		recv, err := server.Recv()
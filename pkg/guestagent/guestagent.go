@@ -12,7 +12,21 @@ import (
 
 type Agent interface {
 	Info(ctx context.Context) (*api.Info, error)
+	// GUIInfo returns the GUI information for the requested X11 display, or for the primary display if empty.
+	GUIInfo(ctx context.Context, req *api.GUIInfoRequest) (*api.GUIInfo, error)
+	// Clipboard returns the text content of the guest session clipboard.
+	Clipboard(ctx context.Context) ([]byte, error)
+	// SetClipboard replaces the content of the guest session clipboard.
+	SetClipboard(ctx context.Context, data []byte) error
+	// ListWindows returns the top-level windows of the guest session.
+	ListWindows(ctx context.Context) ([]*api.Window, error)
+	// Screenshot returns a PNG screenshot of the guest session, taken by a tool in the guest.
+	Screenshot(ctx context.Context) ([]byte, error)
+	// SetBlankTimeout sets the idle seconds after which the guest session blanks the screen, 0 to never blank.
+	SetBlankTimeout(ctx context.Context, seconds int32) error
 	Events(ctx context.Context, ch chan *api.Event)
+	// WatchGUIInfo sends the GUI information to ch, then its changes, until ctx is done; ch is closed on return.
+	WatchGUIInfo(ctx context.Context, req *api.GUIInfoWatchRequest, ch chan *api.GUIInfoChange)
 	LocalPorts(ctx context.Context) ([]*api.IPPort, error)
 	HandleInotify(event *api.Inotify)
 	io.Closer
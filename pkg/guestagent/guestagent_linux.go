@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -21,9 +22,13 @@ import (
 	"github.com/lima-vm/lima/v2/pkg/guestagent/sockets"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/ticker"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/timesync"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
 )
 
 func New(ctx context.Context, ticker ticker.Ticker, runtimeDir string) (Agent, error) {
+	// Look up the detection tools once, instead of on every GUI probe
+	tools.Probe()
+
 	socketsLister, err := sockets.NewLister()
 	if err != nil {
 		return nil, err
@@ -215,6 +220,83 @@ func (a *agent) Info(ctx context.Context) (*api.Info, error) {
 	return &info, nil
 }
 
+func (a *agent) GUIInfo(ctx context.Context, req *api.GUIInfoRequest) (*api.GUIInfo, error) {
+	if req.EnableAccessibility {
+		// gsettings must reach the session bus of the user, not the settings of root
+		if err := gui.EnableAccessibility(gui.WithGraphicalSession(ctx)); err != nil {
+			return nil, fmt.Errorf("failed to enable accessibility: %w", err)
+		}
+	}
+	return gui.DetectGUIInfoForDisplay(ctx, req.Display), nil
+}
+
+// Defaults of WatchGUIInfo
+const (
+	defaultGUIWatchInterval      = 5 * time.Second
+	minGUIWatchInterval          = time.Second
+	defaultGUIWatchIdleThreshold = time.Minute
+)
+
+func (a *agent) WatchGUIInfo(ctx context.Context, req *api.GUIInfoWatchRequest, ch chan *api.GUIInfoChange) {
+	defer close(ch)
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultGUIWatchInterval
+	}
+	// Every probe runs a dozen commands, do not let a client turn it into a busy loop
+	interval = max(interval, minGUIWatchInterval)
+	idleThreshold := time.Duration(req.IdleThresholdMs) * time.Millisecond
+	if idleThreshold <= 0 {
+		idleThreshold = defaultGUIWatchIdleThreshold
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *api.GUIInfo
+	for {
+		info := gui.DetectGUIInfo(ctx)
+		change := &api.GUIInfoChange{Time: timestamppb.Now()}
+		if prev == nil {
+			change.Info = info
+		} else {
+			change.Changes = gui.DiffGUIInfo(prev, info, idleThreshold)
+		}
+		prev = info
+		if change.Info != nil || len(change.Changes) > 0 {
+			select {
+			case ch <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *agent) Clipboard(ctx context.Context) ([]byte, error) {
+	return gui.GetClipboard(gui.WithGraphicalSession(ctx))
+}
+
+func (a *agent) SetClipboard(ctx context.Context, data []byte) error {
+	return gui.SetClipboard(gui.WithGraphicalSession(ctx), data)
+}
+
+func (a *agent) ListWindows(ctx context.Context) ([]*api.Window, error) {
+	return gui.ListWindows(gui.WithGraphicalSession(ctx))
+}
+
+func (a *agent) Screenshot(ctx context.Context) ([]byte, error) {
+	return gui.Screenshot(gui.WithGraphicalSession(ctx))
+}
+
+func (a *agent) SetBlankTimeout(ctx context.Context, seconds int32) error {
+	return gui.SetBlankTimeout(gui.WithGraphicalSession(ctx), seconds)
+}
+
 const deltaLimit = 2 * time.Second
 
 func (a *agent) fixSystemTimeSkew(ctx context.Context) {
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gnomeIdleDelay is the gsettings key of the idle time after which GNOME blanks the screen, in seconds
+var gnomeIdleDelay = []string{"org.gnome.desktop.session", "idle-delay"}
+
+// isGNOMESession checks XDG_CURRENT_DESKTOP, e.g., "ubuntu:GNOME", as GNOME blanks the screen itself
+// on both X11 and Wayland, ignoring the X11 screensaver settings
+func isGNOMESession(ctx context.Context) bool {
+	return strings.Contains(getenv(ctx, "XDG_CURRENT_DESKTOP"), "GNOME")
+}
+
+// getScreenBlankTimeout returns the idle seconds after which the session blanks the screen, 0 if never,
+// read from gsettings on GNOME and from `xset q` on X11; false if unknown
+func getScreenBlankTimeout(ctx context.Context, displayServer string) (int32, bool) {
+	switch {
+	case isGNOMESession(ctx):
+		output := runProbe(ctx, 2*time.Second, "gsettings", append([]string{"get"}, gnomeIdleDelay...)...)
+		if output == nil {
+			return 0, false
+		}
+		return parseGsettingsUint32(string(output))
+	case displayServer == "X11":
+		output := runProbe(ctx, 1*time.Second, "xset", "q")
+		if output == nil {
+			return 0, false
+		}
+		return parseXsetBlankTimeout(output), true
+	}
+	return 0, false
+}
+
+// parseGsettingsUint32 parses a uint32 printed by gsettings, e.g., "uint32 300"
+func parseGsettingsUint32(output string) (int32, bool) {
+	v, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(output), "uint32 "), 10, 32)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return int32(v), true
+}
+
+// parseXsetBlankTimeout returns the earliest of the screensaver timeout and of the DPMS timeouts
+// in the output of `xset q`, 0 if neither blanks the screen. DPMS timeouts of 0 are disabled.
+func parseXsetBlankTimeout(output []byte) int32 {
+	var screensaver int32
+	var dpms []int32
+	dpmsEnabled := false
+	for line := range strings.Lines(string(output)) {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "timeout:":
+			screensaver = atoi32(fields[1])
+		case len(fields) >= 6 && fields[0] == "Standby:" && fields[2] == "Suspend:" && fields[4] == "Off:":
+			dpms = []int32{atoi32(fields[1]), atoi32(fields[3]), atoi32(fields[5])}
+		case strings.Contains(line, "DPMS is Enabled"):
+			dpmsEnabled = true
+		}
+	}
+	timeouts := []int32{screensaver}
+	if dpmsEnabled {
+		timeouts = append(timeouts, dpms...)
+	}
+	var earliest int32
+	for _, t := range timeouts {
+		if t > 0 && (earliest == 0 || t < earliest) {
+			earliest = t
+		}
+	}
+	return earliest
+}
+
+// blankTimeoutCommand returns the command setting the idle seconds before the session blanks the screen,
+// 0 to never blank: gsettings on GNOME, xset on X11 for both the screensaver and DPMS
+func blankTimeoutCommand(ctx context.Context, seconds int32) ([]string, error) {
+	switch {
+	case isGNOMESession(ctx):
+		return append(append([]string{"gsettings", "set"}, gnomeIdleDelay...), strconv.Itoa(int(seconds))), nil
+	case detectWayland(ctx):
+		return nil, errors.New("setting the blank timeout is only supported on GNOME for Wayland sessions")
+	case detectX11(ctx):
+		if seconds == 0 {
+			return []string{"xset", "s", "off", "-dpms"}, nil
+		}
+		s := strconv.Itoa(int(seconds))
+		return []string{"xset", "s", s, "+dpms", "dpms", s, s, s}, nil
+	}
+	return nil, errors.New("no GUI session is running")
+}
+
+// SetBlankTimeout sets the idle seconds after which the session blanks the screen, 0 to never blank.
+// xset settings only last until the X server exits.
+func SetBlankTimeout(ctx context.Context, seconds int32) error {
+	if seconds < 0 || int64(seconds) > math.MaxUint16 {
+		// The X11 timeouts are 16-bit
+		return fmt.Errorf("invalid blank timeout %ds, expected 0 to %d", seconds, math.MaxUint16)
+	}
+	args, err := blankTimeoutCommand(ctx, seconds)
+	if err != nil {
+		return err
+	}
+	_, err = runner(ctx, 5*time.Second, args[0], args[1:]...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s is not installed in the guest", args[0])
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseXsetBlankTimeout(t *testing.T) {
+	const xsetQ = `Screen Saver:
+  prefer blanking:  yes    allow exposures:  yes
+  timeout:  %d    cycle:  600
+DPMS (Energy Star):
+  Standby: %d    Suspend: 0    Off: 900
+  DPMS is %s
+  Monitor is On
+`
+	assert.Equal(t, parseXsetBlankTimeout(fmt.Appendf(nil, xsetQ, 600, 300, "Enabled")), int32(300))
+	assert.Equal(t, parseXsetBlankTimeout(fmt.Appendf(nil, xsetQ, 600, 300, "Disabled")), int32(600))
+	assert.Equal(t, parseXsetBlankTimeout(fmt.Appendf(nil, xsetQ, 0, 0, "Enabled")), int32(900))
+	assert.Equal(t, parseXsetBlankTimeout(fmt.Appendf(nil, xsetQ, 0, 300, "Disabled")), int32(0))
+	assert.Equal(t, parseXsetBlankTimeout([]byte("Screen Saver:\n  timeout:  120    cycle:  600\nServer does not have the DPMS Extension\n")), int32(120))
+}
+
+func TestGetScreenBlankTimeout(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"gsettings get org.gnome.desktop.session idle-delay": "uint32 300\n",
+		"xset q": "Screen Saver:\n  timeout:  0    cycle:  600\nServer does not have the DPMS Extension\n",
+	})
+	ctx := withSessionEnv(t.Context(), []string{"XDG_CURRENT_DESKTOP=ubuntu:GNOME"})
+	timeout, ok := getScreenBlankTimeout(ctx, "Wayland")
+	assert.Assert(t, ok)
+	assert.Equal(t, timeout, int32(300))
+
+	ctx = withSessionEnv(t.Context(), []string{"XDG_CURRENT_DESKTOP=XFCE"})
+	timeout, ok = getScreenBlankTimeout(ctx, "X11")
+	assert.Assert(t, ok)
+	assert.Equal(t, timeout, int32(0))
+	_, ok = getScreenBlankTimeout(ctx, "Wayland")
+	assert.Assert(t, !ok)
+}
+
+func TestSetBlankTimeout(t *testing.T) {
+	var ran []string
+	orig := runner
+	t.Cleanup(func() { runner = orig })
+	runner = func(_ context.Context, _ time.Duration, name string, args ...string) ([]byte, error) {
+		ran = append(ran, strings.Join(append([]string{name}, args...), " "))
+		return nil, nil
+	}
+
+	gnome := withSessionEnv(t.Context(), []string{"XDG_CURRENT_DESKTOP=GNOME", "WAYLAND_DISPLAY=wayland-0"})
+	assert.NilError(t, SetBlankTimeout(gnome, 0))
+	x11 := withSessionEnv(t.Context(), []string{"DISPLAY=:0"})
+	assert.NilError(t, SetBlankTimeout(x11, 0))
+	assert.NilError(t, SetBlankTimeout(x11, 600))
+	assert.DeepEqual(t, ran, []string{
+		"gsettings set org.gnome.desktop.session idle-delay 0",
+		"xset s off -dpms",
+		"xset s 600 +dpms dpms 600 600 600",
+	})
+
+	sway := withSessionEnv(t.Context(), []string{"XDG_CURRENT_DESKTOP=sway", "WAYLAND_DISPLAY=wayland-1"})
+	assert.ErrorContains(t, SetBlankTimeout(sway, 0), "only supported on GNOME")
+	assert.ErrorContains(t, SetBlankTimeout(x11, -1), "invalid blank timeout")
+}
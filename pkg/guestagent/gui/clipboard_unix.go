@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// clipboardTimeout bounds the clipboard tools, which hang when no session owns the clipboard
+const clipboardTimeout = 5 * time.Second
+
+// clipboardCommands returns the commands that read and write the clipboard of the session:
+// wl-clipboard on Wayland, xclip on X11
+func clipboardCommands(ctx context.Context) (get, set []string) {
+	if detectWayland(ctx) {
+		return []string{"wl-paste", "--no-newline"}, []string{"wl-copy"}
+	}
+	return []string{"xclip", "-selection", "clipboard", "-o"}, []string{"xclip", "-selection", "clipboard", "-i"}
+}
+
+// clipboardWriter runs a command with the given standard input, tests replace it with a fake
+var clipboardWriter = func(ctx context.Context, input []byte, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, clipboardTimeout)
+	defer cancel()
+	cmd := newCommand(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	// xclip and wl-copy fork a child that serves the selection, leave its output unconnected so that Run
+	// returns when the parent exits
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// GetClipboard returns the text content of the session clipboard.
+// It is used to share the clipboard with the host when the SPICE agent is not available.
+func GetClipboard(ctx context.Context) ([]byte, error) {
+	get, _ := clipboardCommands(ctx)
+	output, err := runner(ctx, clipboardTimeout, get[0], get[1:]...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%s is not installed in the guest", get[0])
+	}
+	return output, err
+}
+
+// SetClipboard replaces the content of the session clipboard with the given text
+func SetClipboard(ctx context.Context, data []byte) error {
+	_, set := clipboardCommands(ctx)
+	err := clipboardWriter(ctx, data, set[0], set[1:]...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s is not installed in the guest", set[0])
+	}
+	return err
+}
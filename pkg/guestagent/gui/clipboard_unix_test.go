@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGetClipboard(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	fakeRunner(t, map[string]string{
+		"xclip -selection clipboard -o": "hello from the guest",
+	})
+	data, err := GetClipboard(t.Context())
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello from the guest")
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	_, err = GetClipboard(t.Context())
+	assert.ErrorContains(t, err, "wl-paste is not installed in the guest")
+}
+
+func TestSetClipboard(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	orig := clipboardWriter
+	t.Cleanup(func() { clipboardWriter = orig })
+	var got string
+	clipboardWriter = func(_ context.Context, input []byte, name string, args ...string) error {
+		got = strings.Join(append([]string{name}, args...), " ") + ": " + string(input)
+		return nil
+	}
+	assert.NilError(t, SetClipboard(t.Context(), []byte("hello from the host")))
+	assert.Equal(t, got, "wl-copy: hello from the host")
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// Finding the process that owns the X11 CLIPBOARD selection. xclip and xsel only read the
+// contents of the selection, so the owner window is mapped to its client with the X-Resource extension.
+// See https://gitlab.freedesktop.org/xorg/proto/xorgproto/-/blob/master/resproto.txt
+
+const (
+	xresOpQueryClientIDs       = 4
+	xresExtensionName          = "X-Resource"
+	xresClientIDLocalClientPID = 2 // XRES_CLIENT_ID_LOCAL_CLIENT_PID_MASK
+	spiceAgentProcessName      = "spice-vdagent"
+)
+
+// detectClipboardOwner sets which process owns the CLIPBOARD selection of the X11 session.
+// spice-vdagent grabs the selection when the host clipboard changes; a clipboard manager that keeps
+// taking it back makes the clipboard of the host and the guest diverge.
+func detectClipboardOwner(ctx context.Context, info *api.GUIInfo) {
+	if info.Spice == nil || info.DisplayServer != "X11" {
+		return
+	}
+	display := requestedDisplay(ctx)
+	if display == "" && len(info.Displays) > 0 {
+		display = info.Displays[0]
+	}
+	pid, err := x11SelectionOwnerPID(ctx, display, "CLIPBOARD")
+	if err != nil {
+		addWarning(ctx, "cannot check the owner of the X11 clipboard: %v", err)
+		return
+	}
+	if pid == 0 {
+		return
+	}
+	output := runProbe(ctx, 2*time.Second, "ps", "-o", "comm=", "-p", strconv.FormatUint(uint64(pid), 10))
+	name := strings.TrimSpace(string(output))
+	info.Spice.ClipboardSelectionOwner = name
+	info.Spice.ClipboardSelectionOwned = name == spiceAgentProcessName
+}
+
+// x11SelectionOwnerPID returns the process ID of the client owning the selection, or 0 if the
+// selection has no owner or the owner is unknown, e.g., a remote client
+func x11SelectionOwnerPID(ctx context.Context, display, selection string) (uint32, error) {
+	num, _, err := x11Display(display)
+	if err != nil {
+		return 0, err
+	}
+	cookie, err := x11Cookie(ctx, num)
+	if err != nil {
+		return 0, err
+	}
+	return x11QuerySelectionOwnerPID(filepath.Join(x11SocketDir, "X"+num), cookie, selection)
+}
+
+// x11QuerySelectionOwnerPID connects to the X server socket, and asks X-Resource for the
+// process ID of the client owning the selection
+func x11QuerySelectionOwnerPID(socketPath string, cookie []byte, selection string) (uint32, error) {
+	conn, err := net.DialTimeout("unix", socketPath, x11Timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(x11Timeout)); err != nil {
+		return 0, err
+	}
+	if _, err := x11Setup(conn, cookie); err != nil {
+		return 0, err
+	}
+	owner, err := x11GetSelectionOwner(conn, selection)
+	if err != nil || owner == 0 {
+		return 0, err
+	}
+	major, err := x11QueryExtension(conn, xresExtensionName)
+	if err != nil {
+		return 0, err
+	}
+	if major == 0 {
+		return 0, errors.New("the X server does not implement the X-Resource extension")
+	}
+
+	// XResQueryClientIds with one spec: the client owning the window, and its local process ID
+	req := make([]byte, 16)
+	req[0], req[1] = major, xresOpQueryClientIDs
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], 1)
+	binary.LittleEndian.PutUint32(req[8:], owner)
+	binary.LittleEndian.PutUint32(req[12:], xresClientIDLocalClientPID)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("XResQueryClientIds: %w", err)
+	}
+	off := 32
+	for range int(binary.LittleEndian.Uint32(reply[8:])) {
+		if off+12 > len(reply) {
+			return 0, errors.New("short XResQueryClientIds reply")
+		}
+		v := reply[off:]
+		length := int(binary.LittleEndian.Uint32(v[8:]))
+		if off+12+length > len(reply) {
+			return 0, errors.New("short XResQueryClientIds reply")
+		}
+		if binary.LittleEndian.Uint32(v[4:]) == xresClientIDLocalClientPID && length == 4 {
+			return binary.LittleEndian.Uint32(v[12:]), nil
+		}
+		off += 12 + length
+	}
+	return 0, nil
+}
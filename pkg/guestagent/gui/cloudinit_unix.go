@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Replaced in tests
+var (
+	cloudInitRunDir = "/run/cloud-init"
+	cloudInitDir    = "/var/lib/cloud"
+)
+
+// getCloudInitDone checks if cloud-init finished provisioning the guest, without waiting for it
+// like `cloud-init status --wait` does. A guest without cloud-init is reported as done.
+func getCloudInitDone() bool {
+	// cloud-init writes result.json at the end of every boot, into a directory cleared on reboot
+	if _, err := os.Stat(cloudInitRunDir); err == nil {
+		_, err := os.Stat(filepath.Join(cloudInitRunDir, "result.json"))
+		return err == nil
+	}
+	// Without the run directory, e.g., on FreeBSD, fall back to the sentinel of the instance
+	if _, err := os.Stat(filepath.Join(cloudInitDir, "instance", "boot-finished")); err == nil {
+		return true
+	}
+	_, err := os.Stat(cloudInitDir)
+	return errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGetCloudInitDone(t *testing.T) {
+	dir := t.TempDir()
+	origRun, origLib := cloudInitRunDir, cloudInitDir
+	t.Cleanup(func() { cloudInitRunDir, cloudInitDir = origRun, origLib })
+	cloudInitRunDir = filepath.Join(dir, "run")
+	cloudInitDir = filepath.Join(dir, "lib")
+
+	assert.Assert(t, getCloudInitDone(), "no cloud-init")
+
+	assert.NilError(t, os.MkdirAll(filepath.Join(cloudInitDir, "instance"), 0o755))
+	assert.Assert(t, !getCloudInitDone(), "installed, never finished")
+	assert.NilError(t, os.WriteFile(filepath.Join(cloudInitDir, "instance", "boot-finished"), nil, 0o644))
+	assert.Assert(t, getCloudInitDone(), "boot-finished without run directory")
+
+	// The run directory tells about the current boot, a boot-finished of a previous boot does not count
+	assert.NilError(t, os.MkdirAll(cloudInitRunDir, 0o755))
+	assert.Assert(t, !getCloudInitDone(), "running")
+	assert.NilError(t, os.WriteFile(filepath.Join(cloudInitRunDir, "result.json"), []byte("{}"), 0o644))
+	assert.Assert(t, getCloudInitDone(), "finished")
+}
@@ -0,0 +1,388 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// Replaced in tests
+var (
+	drmCard      = "/dev/dri/card0"
+	driDebugDir  = "/sys/kernel/debug/dri" // one directory per DRM minor
+	sysModuleDir = "/sys/module"
+	drmClassDir  = "/sys/class/drm"
+	modprobeDirs = []string{"/etc/modprobe.d", "/run/modprobe.d", "/usr/lib/modprobe.d", "/lib/modprobe.d"}
+	// Logs of the system-wide and of the rootless X servers
+	xorgLogGlobs = []string{"/var/log/Xorg.*.log", "/home/*/.local/share/xorg/Xorg.*.log"}
+)
+
+// drmCardPrefix prefixes the device nodes of the DRM cards, e.g., "/dev/dri/card1"
+const drmCardPrefix = "/dev/dri/card"
+
+// displayServerProcesses are the X servers and the Wayland compositors, which open the DRM card of the session.
+// Xwayland only opens render nodes.
+var displayServerProcesses = []string{"Xorg", "X", "gnome-shell", "kwin_wayland", "sway", "weston", "mutter", "Hyprland", "labwc", "wayfire", "river", "cage"}
+
+// getVirtioGPULoaded checks if the virtio_gpu kernel driver, which drives the display of every
+// Lima VM type, is available. Unlike lsmod, /sys/module also lists the driver when it is built in.
+func getVirtioGPULoaded() bool {
+	_, err := os.Stat(filepath.Join(sysModuleDir, "virtio_gpu"))
+	return err == nil
+}
+
+// getDRMMaster returns the process holding DRM master on card0, e.g., "Xorg (pid 830)".
+// The DRM debugfs tells which client is master; without it (debugfs not mounted, or not root),
+// the processes with card0 open are listed instead, e.g., "plymouthd (pid 312)".
+func getDRMMaster(ctx context.Context) string {
+	if _, err := os.Stat(drmCard); err != nil {
+		// No display device, e.g., a headless guest
+		return ""
+	}
+	if b, err := os.ReadFile(filepath.Join(driDebugDir, "0", "clients")); err == nil {
+		return parseDRMClients(b)
+	}
+	holders, err := drmCardHolders()
+	if err != nil {
+		addWarning(ctx, "cannot find the processes using %s: %v", drmCard, err)
+		return ""
+	}
+	return strings.Join(holders, ", ")
+}
+
+// parseDRMClients finds the master in the DRM debugfs client list:
+//
+//	command   tgid dev master a   uid      magic
+//	   Xorg    830   0   y    y     0          0
+func parseDRMClients(b []byte) string {
+	var commandCol, pidCol, masterCol int
+	header := true
+	for line := range strings.Lines(string(b)) {
+		fields := strings.Fields(line)
+		if header {
+			commandCol, pidCol, masterCol = slices.Index(fields, "command"), slices.Index(fields, "tgid"), slices.Index(fields, "master")
+			if commandCol < 0 || pidCol < 0 || masterCol < 0 {
+				return ""
+			}
+			header = false
+			continue
+		}
+		if len(fields) > max(commandCol, pidCol, masterCol) && fields[masterCol] == "y" {
+			return fmt.Sprintf("%s (pid %s)", fields[commandCol], fields[pidCol])
+		}
+	}
+	return ""
+}
+
+// drmCardHolders lists the processes with an open file descriptor on the DRM card, like fuser(1)
+func drmCardHolders() ([]string, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	var holders []string
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procDir, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// Exited, or owned by another user
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && target == drmCard {
+				comm, _ := os.ReadFile(filepath.Join(procDir, entry.Name(), "comm"))
+				holders = append(holders, fmt.Sprintf("%s (pid %s)", strings.TrimSpace(string(comm)), entry.Name()))
+				break
+			}
+		}
+	}
+	return holders, nil
+}
+
+// getDRIDevice returns the DRM card used by the display server, e.g., "/dev/dri/card1", and its kernel driver.
+// The file descriptors of the server tell it when the guest agent can read them (root);
+// otherwise the Xorg log tells which card the modesetting driver opened.
+func getDRIDevice(ctx context.Context) (device, driver string) {
+	device, err := displayServerCard()
+	if err != nil {
+		addWarning(ctx, "cannot find the DRM card of the display server: %v", err)
+	}
+	if device == "" {
+		device = xorgLogCard()
+	}
+	if device == "" {
+		return "", ""
+	}
+	cards, err := drmCards()
+	if err != nil {
+		return device, ""
+	}
+	name := filepath.Base(device)
+	for _, c := range cards {
+		if c.name == name && c.driver != "unknown driver" {
+			return device, c.driver
+		}
+	}
+	return device, ""
+}
+
+// displayServerCard returns the DRM card opened by a display server process, or an empty string
+func displayServerCard() (string, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "comm"))
+		if err != nil || !slices.Contains(displayServerProcesses, strings.TrimSpace(string(comm))) {
+			continue
+		}
+		fdDir := filepath.Join(procDir, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// Exited, or owned by another user
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && strings.HasPrefix(target, drmCardPrefix) {
+				return target, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// xorgLogCard returns the DRM card opened by the modesetting driver according to the most recent Xorg log
+func xorgLogCard() string {
+	var latest string
+	var latestTime time.Time
+	for _, glob := range xorgLogGlobs {
+		files, _ := filepath.Glob(glob)
+		for _, file := range files {
+			if fi, err := os.Stat(file); err == nil && fi.ModTime().After(latestTime) {
+				latest, latestTime = file, fi.ModTime()
+			}
+		}
+	}
+	if latest == "" {
+		return ""
+	}
+	b, err := os.ReadFile(latest)
+	if err != nil {
+		return ""
+	}
+	return parseXorgLogCard(string(b))
+}
+
+// parseXorgLogCard finds the card opened by the modesetting driver in an Xorg log, e.g.,
+// "[    10.532] (II) modeset(0): using drv /dev/dri/card1"
+func parseXorgLogCard(log string) string {
+	var card string
+	for line := range strings.Lines(log) {
+		_, rest, ok := strings.Cut(line, "using drv ")
+		if !ok {
+			continue
+		}
+		if dev := strings.TrimSpace(rest); strings.HasPrefix(dev, drmCardPrefix) {
+			// A restarted server appends to the log, the last one is current
+			card = dev
+		}
+	}
+	return card
+}
+
+// getModesettingConflict looks for another display driver taking over from virtio_gpu, which leaves the
+// display of the VM blank: virtio_gpu disabled by a blacklist or by nomodeset, or another DRM card,
+// e.g., driven by nvidia, holding DRM master. The checks are heuristic; it returns why it suspects
+// a conflict, or an empty string.
+func getModesettingConflict(ctx context.Context) string {
+	if cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline")); err == nil {
+		if reason := parseModesettingCmdline(string(cmdline)); reason != "" {
+			return reason
+		}
+	}
+	for _, dir := range modprobeDirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.conf"))
+		for _, file := range files {
+			if blacklistsVirtioGPU(file) {
+				return "virtio_gpu is blacklisted in " + file
+			}
+		}
+	}
+
+	cards, err := drmCards()
+	if errors.Is(err, os.ErrNotExist) {
+		// No DRM driver at all
+		return ""
+	}
+	if err != nil {
+		addWarning(ctx, "cannot list the DRM cards: %v", err)
+		return ""
+	}
+	if len(cards) < 2 {
+		return ""
+	}
+	for _, c := range cards {
+		if c.driver == "virtio_gpu" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(driDebugDir, c.minor, "clients"))
+		if err != nil {
+			continue
+		}
+		if master := parseDRMClients(b); master != "" {
+			return fmt.Sprintf("%s (%s) is held by %s instead of the virtio_gpu card", c.name, c.driver, master)
+		}
+	}
+	return ""
+}
+
+// parseModesettingCmdline finds the kernel parameters that keep virtio_gpu from setting the mode
+func parseModesettingCmdline(cmdline string) string {
+	for _, param := range strings.Fields(cmdline) {
+		key, value, _ := strings.Cut(param, "=")
+		switch {
+		case key == "nomodeset":
+			return "the kernel command line has nomodeset, which disables virtio_gpu"
+		case key == "virtio_gpu.modeset" && value == "0":
+			return "the kernel command line has virtio_gpu.modeset=0"
+		case key == "modprobe.blacklist" && slices.Contains(strings.Split(value, ","), "virtio_gpu"):
+			return "virtio_gpu is blacklisted on the kernel command line"
+		}
+	}
+	return ""
+}
+
+// blacklistsVirtioGPU checks if a modprobe.d file blacklists virtio_gpu, or replaces its loading
+// with a no-op command, e.g., "install virtio_gpu /bin/false"
+func blacklistsVirtioGPU(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] != "virtio_gpu" {
+			continue
+		}
+		if fields[0] == "blacklist" || (fields[0] == "install" && len(fields) > 2 && (fields[2] == "/bin/false" || fields[2] == "/bin/true")) {
+			return true
+		}
+	}
+	return false
+}
+
+// drmCardInfo is a DRM card of /sys/class/drm, e.g., "card1" driven by "nvidia"
+type drmCardInfo struct {
+	name, minor, driver string
+}
+
+// drmCards lists the DRM cards in the order of their minor numbers
+func drmCards() ([]drmCardInfo, error) {
+	entries, err := os.ReadDir(drmClassDir)
+	if err != nil {
+		return nil, err
+	}
+	var cards []drmCardInfo
+	for _, entry := range entries {
+		minor, ok := strings.CutPrefix(entry.Name(), "card")
+		if _, err := strconv.Atoi(minor); !ok || err != nil {
+			// Connectors, e.g., "card0-Virtual-1", and render nodes
+			continue
+		}
+		driver, err := os.Readlink(filepath.Join(drmClassDir, entry.Name(), "device", "driver"))
+		if err != nil {
+			driver = "unknown driver"
+		}
+		cards = append(cards, drmCardInfo{name: entry.Name(), minor: minor, driver: filepath.Base(driver)})
+	}
+	slices.SortFunc(cards, func(a, b drmCardInfo) int {
+		return cmp.Compare(atoi32(a.minor), atoi32(b.minor))
+	})
+	return cards, nil
+}
+
+// drmConnector is a connected DRM connector, e.g., "Virtual-1" for /sys/class/drm/card0-Virtual-1
+type drmConnector struct {
+	name string
+	// id is the DRM object ID of the connector, 0 when the kernel is too old to expose it in sysfs
+	id uint32
+}
+
+// drmConnectors lists the connected DRM connectors
+func drmConnectors() []drmConnector {
+	entries, err := os.ReadDir(drmClassDir)
+	if err != nil {
+		return nil
+	}
+	var connectors []drmConnector
+	for _, entry := range entries {
+		card, name, ok := strings.Cut(entry.Name(), "-")
+		if !ok || !strings.HasPrefix(card, "card") {
+			continue
+		}
+		status, err := os.ReadFile(filepath.Join(drmClassDir, entry.Name(), "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+		c := drmConnector{name: name}
+		if b, err := os.ReadFile(filepath.Join(drmClassDir, entry.Name(), "connector_id")); err == nil {
+			if id, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 32); err == nil {
+				c.id = uint32(id)
+			}
+		}
+		connectors = append(connectors, c)
+	}
+	return connectors
+}
+
+// setOutputConnectors sets the DRM connector of the outputs, matched by the connector IDs of the outputs
+// reported by the X server. The modesetting X11 driver and the Wayland compositors name the outputs after
+// their connector, but the QXL X11 driver numbers them from 0, e.g., its "Virtual-1" is the connector
+// "Virtual-2". So without IDs, the outputs are matched by name only when every output names a connector;
+// otherwise a single remaining output is matched with a single remaining connector.
+func setOutputConnectors(outputs []*api.DisplayMode, connectorIDs map[string]uint32, connectors []drmConnector) {
+	remaining := slices.Clone(connectors)
+	var unmatched []*api.DisplayMode
+	for _, o := range outputs {
+		id := connectorIDs[o.Name]
+		if i := slices.IndexFunc(remaining, func(c drmConnector) bool { return id != 0 && c.id == id }); i >= 0 {
+			o.Connector = remaining[i].name
+			remaining = slices.Delete(remaining, i, i+1)
+			continue
+		}
+		unmatched = append(unmatched, o)
+	}
+	namesMatch := !slices.ContainsFunc(unmatched, func(o *api.DisplayMode) bool {
+		return !slices.ContainsFunc(remaining, func(c drmConnector) bool { return c.name == o.Name })
+	})
+	switch {
+	case namesMatch:
+		for _, o := range unmatched {
+			o.Connector = o.Name
+		}
+	case len(unmatched) == 1 && len(remaining) == 1:
+		unmatched[0].Connector = remaining[0].name
+	}
+}
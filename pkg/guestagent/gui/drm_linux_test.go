@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+func TestParseDRMClients(t *testing.T) {
+	tests := []struct {
+		name     string
+		clients  string
+		expected string
+	}{
+		{
+			name: "Xorg is master",
+			clients: `             command   tgid dev master a   uid      magic
+                Xorg    830   0   y    y     0          0
+         gnome-shell   1204   0   n    y  1000          2
+`,
+			expected: "Xorg (pid 830)",
+		},
+		{
+			name: "newer kernels add the client name and id",
+			clients: `             command  tgid dev master a   uid      magic                 name   id
+           plymouthd    312   0   y    y     0          0             <unset>    1
+`,
+			expected: "plymouthd (pid 312)",
+		},
+		{
+			name: "no master",
+			clients: `             command   tgid dev master a   uid      magic
+         gnome-shell   1204   0   n    y  1000          2
+`,
+			expected: "",
+		},
+		{
+			name:     "unknown format",
+			clients:  "something else\n",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, parseDRMClients([]byte(tt.clients)), tt.expected)
+		})
+	}
+}
+
+func TestGetDRMMasterWithoutDebugfs(t *testing.T) {
+	dir := t.TempDir()
+	oldCard, oldDebug := drmCard, driDebugDir
+	procDir, drmCard, driDebugDir = filepath.Join(dir, "proc"), filepath.Join(dir, "card0"), filepath.Join(dir, "debug")
+	t.Cleanup(func() { procDir, drmCard, driDebugDir = "/proc", oldCard, oldDebug })
+
+	ctx, warnings := withWarnings(context.Background())
+	assert.Equal(t, getDRMMaster(ctx), "", "no display device")
+
+	assert.NilError(t, os.WriteFile(drmCard, nil, 0o644))
+	addProcess := func(pid, comm string, files ...string) {
+		fdDir := filepath.Join(procDir, pid, "fd")
+		assert.NilError(t, os.MkdirAll(fdDir, 0o755))
+		assert.NilError(t, os.WriteFile(filepath.Join(procDir, pid, "comm"), []byte(comm+"\n"), 0o644))
+		for i, f := range files {
+			assert.NilError(t, os.Symlink(f, filepath.Join(fdDir, strconv.Itoa(3+i))))
+		}
+	}
+	addProcess("1", "systemd", "/dev/null")
+	addProcess("312", "plymouthd", "/dev/null", drmCard)
+	assert.Equal(t, getDRMMaster(ctx), "plymouthd (pid 312)")
+	assert.Equal(t, len(*warnings), 0)
+}
+
+func TestGetVirtioGPULoaded(t *testing.T) {
+	oldDir := sysModuleDir
+	sysModuleDir = t.TempDir()
+	t.Cleanup(func() { sysModuleDir = oldDir })
+
+	assert.Assert(t, !getVirtioGPULoaded())
+	assert.NilError(t, os.Mkdir(filepath.Join(sysModuleDir, "virtio_gpu"), 0o755))
+	assert.Assert(t, getVirtioGPULoaded())
+}
+
+func TestGetModesettingConflict(t *testing.T) {
+	dir := t.TempDir()
+	oldDebug, oldClass, oldModprobe := driDebugDir, drmClassDir, modprobeDirs
+	procDir, driDebugDir, drmClassDir = filepath.Join(dir, "proc"), filepath.Join(dir, "debug"), filepath.Join(dir, "drm")
+	modprobeDirs = []string{filepath.Join(dir, "modprobe.d")}
+	t.Cleanup(func() { procDir, driDebugDir, drmClassDir, modprobeDirs = "/proc", oldDebug, oldClass, oldModprobe })
+	for _, d := range []string{procDir, modprobeDirs[0]} {
+		assert.NilError(t, os.MkdirAll(d, 0o755))
+	}
+	addCard := func(name, driver string) {
+		assert.NilError(t, os.MkdirAll(filepath.Join(drmClassDir, name, "device"), 0o755))
+		assert.NilError(t, os.Symlink(filepath.Join("/sys/bus/pci/drivers", driver), filepath.Join(drmClassDir, name, "device", "driver")))
+	}
+
+	ctx, warnings := withWarnings(context.Background())
+	assert.Equal(t, getModesettingConflict(ctx), "", "no DRM driver")
+
+	addCard("card0", "virtio_gpu")
+	assert.NilError(t, os.MkdirAll(filepath.Join(drmClassDir, "card0-Virtual-1"), 0o755))
+	assert.Equal(t, getModesettingConflict(ctx), "")
+
+	// A second card is only a conflict when it holds DRM master
+	addCard("card1", "nvidia")
+	assert.Equal(t, getModesettingConflict(ctx), "")
+	assert.NilError(t, os.MkdirAll(filepath.Join(driDebugDir, "1"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(driDebugDir, "1", "clients"), []byte(`             command   tgid dev master a   uid      magic
+                Xorg    830   1   y    y     0          0
+`), 0o644))
+	assert.Equal(t, getModesettingConflict(ctx), "card1 (nvidia) is held by Xorg (pid 830) instead of the virtio_gpu card")
+
+	conf := filepath.Join(modprobeDirs[0], "nvidia.conf")
+	assert.NilError(t, os.WriteFile(conf, []byte("# added by the driver installer\nblacklist nouveau\nblacklist virtio_gpu\n"), 0o644))
+	assert.Equal(t, getModesettingConflict(ctx), "virtio_gpu is blacklisted in "+conf)
+
+	assert.NilError(t, os.WriteFile(filepath.Join(procDir, "cmdline"), []byte("BOOT_IMAGE=/vmlinuz root=/dev/vda1 nomodeset quiet\n"), 0o644))
+	assert.Equal(t, getModesettingConflict(ctx), "the kernel command line has nomodeset, which disables virtio_gpu")
+	assert.Equal(t, len(*warnings), 0)
+}
+
+func TestGetDRIDevice(t *testing.T) {
+	dir := t.TempDir()
+	oldClass, oldLogs := drmClassDir, xorgLogGlobs
+	procDir, drmClassDir = filepath.Join(dir, "proc"), filepath.Join(dir, "drm")
+	xorgLogGlobs = []string{filepath.Join(dir, "Xorg.*.log")}
+	t.Cleanup(func() { procDir, drmClassDir, xorgLogGlobs = "/proc", oldClass, oldLogs })
+	for _, card := range []struct{ name, driver string }{{"card0", "nvidia"}, {"card1", "virtio_gpu"}} {
+		assert.NilError(t, os.MkdirAll(filepath.Join(drmClassDir, card.name, "device"), 0o755))
+		assert.NilError(t, os.Symlink(filepath.Join("/sys/bus/pci/drivers", card.driver), filepath.Join(drmClassDir, card.name, "device", "driver")))
+	}
+	assert.NilError(t, os.MkdirAll(procDir, 0o755))
+
+	ctx, warnings := withWarnings(context.Background())
+	device, driver := getDRIDevice(ctx)
+	assert.Equal(t, device, "")
+	assert.Equal(t, driver, "")
+
+	// Without access to the file descriptors of the server, the Xorg log tells the card
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Xorg.0.log"), []byte(`[    10.530] (II) modeset(0): using drv /dev/dri/card1
+[    10.532] (II) modeset(0): glamor X acceleration enabled
+[   310.532] (II) modeset(0): using drv /dev/dri/card0
+`), 0o644))
+	device, driver = getDRIDevice(ctx)
+	assert.Equal(t, device, "/dev/dri/card0")
+	assert.Equal(t, driver, "nvidia")
+
+	fdDir := filepath.Join(procDir, "830", "fd")
+	assert.NilError(t, os.MkdirAll(fdDir, 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(procDir, "830", "comm"), []byte("Xorg\n"), 0o644))
+	assert.NilError(t, os.Symlink("/dev/null", filepath.Join(fdDir, "0")))
+	assert.NilError(t, os.Symlink("/dev/dri/card1", filepath.Join(fdDir, "14")))
+	device, driver = getDRIDevice(ctx)
+	assert.Equal(t, device, "/dev/dri/card1")
+	assert.Equal(t, driver, "virtio_gpu")
+	assert.Equal(t, len(*warnings), 0)
+}
+
+func TestParseModesettingCmdline(t *testing.T) {
+	assert.Equal(t, parseModesettingCmdline("root=/dev/vda1 quiet"), "")
+	assert.Equal(t, parseModesettingCmdline("root=/dev/vda1 virtio_gpu.modeset=0"), "the kernel command line has virtio_gpu.modeset=0")
+	assert.Equal(t, parseModesettingCmdline("modprobe.blacklist=nouveau,virtio_gpu"), "virtio_gpu is blacklisted on the kernel command line")
+	assert.Equal(t, parseModesettingCmdline("modprobe.blacklist=nouveau"), "")
+}
+
+func TestSetOutputConnectors(t *testing.T) {
+	dir := t.TempDir()
+	oldClass := drmClassDir
+	drmClassDir = dir
+	t.Cleanup(func() { drmClassDir = oldClass })
+	for _, c := range []struct{ name, status, id string }{
+		{"card0-Virtual-1", "connected", "35"},
+		{"card0-Virtual-2", "disconnected", "36"},
+		{"card0-Virtual-3", "connected", "37"},
+	} {
+		assert.NilError(t, os.MkdirAll(filepath.Join(dir, c.name), 0o755))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, c.name, "status"), []byte(c.status+"\n"), 0o644))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, c.name, "connector_id"), []byte(c.id+"\n"), 0o644))
+	}
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "card0"), 0o755))
+	connectors := drmConnectors()
+	assert.DeepEqual(t, connectors, []drmConnector{{name: "Virtual-1", id: 35}, {name: "Virtual-3", id: 37}}, cmp.AllowUnexported(drmConnector{}))
+
+	// The Wayland compositors name the outputs after their connector
+	outputs := []*api.DisplayMode{{Name: "Virtual-1"}, {Name: "Virtual-3"}}
+	setOutputConnectors(outputs, nil, connectors)
+	assert.Equal(t, outputs[0].Connector, "Virtual-1")
+	assert.Equal(t, outputs[1].Connector, "Virtual-3")
+
+	// The QXL X11 driver numbers its outputs from 0, so its "Virtual-1" is not the connector "Virtual-1"
+	outputs = []*api.DisplayMode{{Name: "Virtual-0"}, {Name: "Virtual-1"}}
+	setOutputConnectors(outputs, map[string]uint32{"Virtual-0": 35, "Virtual-1": 37}, connectors)
+	assert.Equal(t, outputs[0].Connector, "Virtual-1")
+	assert.Equal(t, outputs[1].Connector, "Virtual-3")
+
+	// Without connector IDs, two renamed outputs cannot be told apart
+	outputs = []*api.DisplayMode{{Name: "Virtual-0"}, {Name: "Virtual-1"}}
+	setOutputConnectors(outputs, nil, connectors)
+	assert.Equal(t, outputs[0].Connector, "")
+	assert.Equal(t, outputs[1].Connector, "")
+
+	outputs = []*api.DisplayMode{{Name: "Virtual-0"}}
+	setOutputConnectors(outputs, nil, connectors[1:])
+	assert.Equal(t, outputs[0].Connector, "Virtual-3")
+}
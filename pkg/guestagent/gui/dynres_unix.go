@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// hotplugCompositors are the compositors that switch an output to its preferred mode when the host
+// changes it, e.g., when the window of a virtio-gpu display is resized; sway and Xfce keep the mode
+var hotplugCompositors = []string{"gnome-shell", "kwin_wayland", "kwin_x11"}
+
+// getDynamicResolutionAgent returns the process that resizes the guest display to the viewer window,
+// or an empty string if the display keeps its size. On X11, the spice-vdagent session client applies the
+// monitor configuration sent by SPICE clients with RandR.
+func getDynamicResolutionAgent(ctx context.Context, info *api.GUIInfo) string {
+	if !info.SessionActive {
+		return ""
+	}
+	if info.DisplayServer == "X11" && info.GetSpice().GetSessionAgentRunning() {
+		return "spice-vdagent"
+	}
+	// pgrep fails when no process matches; "-l" prints "PID NAME"
+	output, err := runner(ctx, 2*time.Second, "pgrep", "-l", "-x", strings.Join(hotplugCompositors, "|"))
+	if err != nil {
+		return ""
+	}
+	for line := range strings.Lines(string(output)) {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			return fields[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+func TestGetDynamicResolutionAgent(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"pgrep -l -x gnome-shell|kwin_wayland|kwin_x11": "1234 gnome-shell\n",
+	})
+	// The session agent resizes X11 sessions itself
+	x11 := &api.GUIInfo{SessionActive: true, DisplayServer: "X11", Spice: &api.SpiceAgentInfo{SessionAgentRunning: true}}
+	assert.Equal(t, "spice-vdagent", getDynamicResolutionAgent(t.Context(), x11))
+	// The spice-vdagent session client does not resize Wayland sessions
+	wayland := &api.GUIInfo{SessionActive: true, DisplayServer: "Wayland", Spice: &api.SpiceAgentInfo{SessionAgentRunning: true}}
+	assert.Equal(t, "gnome-shell", getDynamicResolutionAgent(t.Context(), wayland))
+	assert.Equal(t, "", getDynamicResolutionAgent(t.Context(), &api.GUIInfo{DisplayServer: "Wayland"}))
+
+	// sway keeps the mode of its outputs
+	fakeRunner(t, map[string]string{})
+	assert.Equal(t, "", getDynamicResolutionAgent(t.Context(), wayland))
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// fontconfigCacheDirs are the system font cache directories of the distributions, replaced in tests:
+// Debian and Ubuntu, Fedora, and FreeBSD
+var fontconfigCacheDirs = []string{"/var/cache/fontconfig", "/usr/lib/fontconfig/cache", "/var/db/fontconfig"}
+
+// getFontconfigReady checks if the system font cache is built, and not being rebuilt by fc-cache.
+// Applications started before then render with fallback fonts, e.g., boxes instead of glyphs.
+func getFontconfigReady(ctx context.Context) bool {
+	built := false
+	for _, dir := range fontconfigCacheDirs {
+		// e.g., "9b89f8e3dae116d678bbf48e5f21f69b-le64.cache-9"
+		if matches, _ := filepath.Glob(filepath.Join(dir, "*.cache-*")); len(matches) > 0 {
+			built = true
+			break
+		}
+	}
+	if !built {
+		return false
+	}
+	// pgrep fails when no process matches; without pgrep, the cache is assumed to be complete
+	_, err := runner(ctx, 2*time.Second, "pgrep", "-x", "fc-cache")
+	return err != nil
+}
@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGetFontconfigReady(t *testing.T) {
+	dir := t.TempDir()
+	orig := fontconfigCacheDirs
+	t.Cleanup(func() { fontconfigCacheDirs = orig })
+	fontconfigCacheDirs = []string{filepath.Join(dir, "missing"), dir}
+	fakeRunner(t, nil)
+
+	assert.Assert(t, !getFontconfigReady(t.Context()), "no cache")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "9b89f8e3dae116d678bbf48e5f21f69b-le64.cache-9"), nil, 0o644))
+	assert.Assert(t, getFontconfigReady(t.Context()), "cache built")
+
+	fakeRunner(t, map[string]string{"pgrep -x fc-cache": "1234\n"})
+	assert.Assert(t, !getFontconfigReady(t.Context()), "fc-cache running")
+}
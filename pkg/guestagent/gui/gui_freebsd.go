@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/spiceservice"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
+)
+
+// DetectGUIInfo detects GUI-related information from the FreeBSD guest.
+// Unlike on Linux, the SPICE agent is never installed automatically.
+func DetectGUIInfo(ctx context.Context) *api.GUIInfo {
+	info := &api.GUIInfo{
+		DisplayServer:  "none",
+		SessionActive:  false,
+		SchemaVersion:  api.GUISchemaVersion,
+		AvailableTools: tools.Available(),
+	}
+	ctx, warnings := withWarnings(ctx)
+
+	// Detect display server type
+	detectDisplays(ctx, info)
+
+	// Check if any GUI session is active
+	info.SessionActive = len(info.Displays) > 0
+
+	if info.SessionActive {
+		info.Resolution = getResolution(ctx, info.DisplayServer)
+		info.Outputs, _ = getOutputs(ctx, info.DisplayServer)
+		info.SupportedModes = getSupportedModes(ctx, info.DisplayServer)
+		info.CompositingActive = detectCompositing(ctx, info)
+		info.IdleTimeMs, info.IdleTimeSupported = getIdleTime(ctx, info.DisplayServer)
+		info.SessionSettled = detectSessionSettled(ctx, info)
+		info.AccessibilityBusActive = detectAccessibilityBus(ctx, info.DisplayServer)
+		info.ColorScheme = getColorScheme(ctx)
+	}
+	if info.SessionActive && info.DisplayServer == "X11" {
+		info.ScreenBlankingDisabled = getScreenBlankingDisabled(ctx)
+	}
+	if info.SessionActive {
+		info.ScreenBlankTimeoutSec, info.ScreenBlankTimeoutReported = getScreenBlankTimeout(ctx, info.DisplayServer)
+	}
+
+	// localectl is not available on FreeBSD, fall back to the console keymap from rc.conf
+	info.KeyboardLayout = getKeyboardLayout(ctx, info.DisplayServer)
+	if info.KeyboardLayout == "" {
+		info.KeyboardLayout = getConsoleKeymap(ctx)
+	}
+
+	info.CloudInitDone = getCloudInitDone()
+	info.FontconfigReady = getFontconfigReady(ctx)
+
+	spiceStatus := spiceservice.DetectSpiceStatus(ctx)
+	info.Spice = &api.SpiceAgentInfo{
+		AgentInstalled:      spiceStatus.AgentInstalled,
+		AgentRunning:        spiceStatus.AgentRunning,
+		AgentAutostart:      spiceStatus.AgentAutostart,
+		SessionAgentRunning: spiceStatus.SessionAgentRunning,
+		VportExists:         spiceStatus.VPortExists,
+		ClipboardReady:      spiceStatus.ClipboardReady,
+		ErrorMessage:        spiceStatus.ErrorMessage,
+		MaxClipboardBytes:   spiceStatus.MaxClipboardBytes,
+		ClipboardMimeTypes:  spiceStatus.ClipboardMimeTypes,
+		ClipboardMechanism:  spiceStatus.ClipboardMechanism,
+	}
+	if !info.SessionActive && info.Spice.ClipboardMechanism == spiceservice.ClipboardMechanismGuestAgent {
+		// xclip and wl-clipboard need a display to reach
+		info.Spice.ClipboardMechanism = ""
+	}
+	if info.SessionActive {
+		detectClipboardOwner(ctx, info)
+	}
+	info.DynamicResolutionAgent = getDynamicResolutionAgent(ctx, info)
+	info.DynamicResolutionSupported = info.DynamicResolutionAgent != ""
+
+	info.Warnings = *warnings
+	return info
+}
+
+// getConsoleKeymap gets the console keymap configured in rc.conf, e.g., "us.kbd"
+func getConsoleKeymap(ctx context.Context) string {
+	output := runProbe(ctx, 1*time.Second, "sysrc", "-n", "keymap")
+	if output == nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSpace(string(output)), ".kbd")
+}
@@ -4,10 +4,12 @@
 package gui
 
 import (
-	"bytes"
+	"bufio"
 	"context"
+	"io"
+	"net"
 	"os"
-	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,334 +18,328 @@ import (
 
 	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
 	"github.com/lima-vm/lima/v2/pkg/guestagent/spiceservice"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
 )
 
 // DetectGUIInfo detects GUI-related information from the Linux guest
 func DetectGUIInfo(ctx context.Context) *api.GUIInfo {
 	info := &api.GUIInfo{
-		DisplayServer: "none",
-		SessionActive: false,
+		DisplayServer:  "none",
+		SessionActive:  false,
+		SchemaVersion:  api.GUISchemaVersion,
+		AvailableTools: tools.Available(),
 	}
+	ctx, warnings := withWarnings(ctx)
+
+	// A guest agent running as root probes the graphical session of the logged in user
+	ctx, info.SessionUser = withGraphicalSession(ctx)
 
 	// Detect display server type
-	if detectWayland() {
-		info.DisplayServer = "Wayland"
-		info.Displays = getWaylandDisplays()
-	} else if detectX11() {
-		info.DisplayServer = "X11"
-		info.Displays = getX11Displays()
-	}
+	detectDisplays(ctx, info)
 
 	// Check if any GUI session is active
 	info.SessionActive = len(info.Displays) > 0
 
 	// Get resolution if available
 	if info.SessionActive {
-		info.Resolution = getResolution(info.DisplayServer)
+		info.Resolution = getResolution(ctx, info.DisplayServer)
+		var connectorIDs map[string]uint32
+		info.Outputs, connectorIDs = getOutputs(ctx, info.DisplayServer)
+		setOutputConnectors(info.Outputs, connectorIDs, drmConnectors())
+		info.SupportedModes = getSupportedModes(ctx, info.DisplayServer)
+		info.CompositingActive = detectCompositing(ctx, info)
+		info.AccessibilityBusActive = detectAccessibilityBus(ctx, info.DisplayServer)
+		info.ColorScheme = getColorScheme(ctx)
 	}
 
 	// Get idle time
 	if info.SessionActive {
-		info.IdleTimeMs = getIdleTime(info.DisplayServer)
+		info.IdleTimeMs, info.IdleTimeSupported = getIdleTime(ctx, info.DisplayServer)
+		info.SessionSettled = detectSessionSettled(ctx, info)
+	}
+
+	// Get keyboard layout for SPICE input mapping
+	info.KeyboardLayout = getKeyboardLayout(ctx, info.DisplayServer)
+
+	// Check for a tablet-like device, without it the pointer is relative
+	info.AbsolutePointer = hasAbsolutePointer(ctx)
+
+	// A guest booted to multi-user.target never starts a display manager
+	info.SystemdDefaultTarget, info.ActiveGraphicalTarget = getSystemdTargets(ctx)
+
+	// Keep-awake setups for unattended sessions hold an idle inhibitor, or turn off the X11 screen blanking
+	info.IdleInhibited = getIdleInhibited(ctx)
+	if info.SessionActive && info.DisplayServer == "X11" {
+		info.ScreenBlankingDisabled = getScreenBlankingDisabled(ctx)
+	}
+	if info.SessionActive {
+		info.ScreenBlankTimeoutSec, info.ScreenBlankTimeoutReported = getScreenBlankTimeout(ctx, info.DisplayServer)
+	}
+
+	// getty or plymouth keeping DRM master prevents the display server from taking over the console
+	info.DrmMaster = getDRMMaster(ctx)
+	// On multi-GPU guests, the display server may have grabbed another card than virtio_gpu
+	if info.SessionActive {
+		info.DriDevice, info.DriDeviceDriver = getDRIDevice(ctx)
+	}
+
+	// Custom images may lack the display driver, leaving the window blank
+	info.VirtioGpuLoaded = getVirtioGPULoaded()
+	// A proprietary driver, e.g., nvidia, can take the display over from virtio_gpu
+	info.ModesettingConflictDetail = getModesettingConflict(ctx)
+	info.ModesettingConflict = info.ModesettingConflictDetail != ""
+
+	// The user and the autologin of the desktop may not be set up yet while cloud-init runs
+	info.CloudInitDone = getCloudInitDone()
+
+	// Applications render with fallback fonts until the font cache is built
+	info.FontconfigReady = getFontconfigReady(ctx)
+
+	// Headless Wayland sessions have no output to show over SPICE, but may be served by wayvnc.
+	// Other RDP and VNC servers may compete with SPICE for the session.
+	// Only root can see the processes of the sockets owned by other users.
+	// Sunshine streams the session to Moonlight clients
+	if output := runProbe(ctx, 2*time.Second, "ss", "-H", "-l", "-t", "-n", "-p"); output != nil {
+		info.VncEndpoint = parseWayVNCEndpoint(string(output))
+		info.RemoteDisplayServer = parseRemoteDisplayServers(string(output))
+		info.StreamingEndpoint = parseSunshineEndpoint(string(output))
+	}
+	if info.StreamingEndpoint != "" || sunshineRunning(ctx) {
+		info.StreamingHost = "Sunshine"
 	}
 
 	// Detect SPICE agent status for clipboard sharing
 	spiceStatus := spiceservice.DetectSpiceStatus(ctx)
 	info.Spice = &api.SpiceAgentInfo{
-		AgentInstalled: spiceStatus.AgentInstalled,
-		AgentRunning:   spiceStatus.AgentRunning,
-		VportExists:    spiceStatus.VPortExists,
-		ClipboardReady: spiceStatus.ClipboardReady,
-		ErrorMessage:   spiceStatus.ErrorMessage,
+		AgentInstalled:      spiceStatus.AgentInstalled,
+		AgentRunning:        spiceStatus.AgentRunning,
+		AgentAutostart:      spiceStatus.AgentAutostart,
+		SessionAgentRunning: spiceStatus.SessionAgentRunning,
+		VportExists:         spiceStatus.VPortExists,
+		ClipboardReady:      spiceStatus.ClipboardReady,
+		ErrorMessage:        spiceStatus.ErrorMessage,
+		MaxClipboardBytes:   spiceStatus.MaxClipboardBytes,
+		ClipboardMimeTypes:  spiceStatus.ClipboardMimeTypes,
+		ClipboardMechanism:  spiceStatus.ClipboardMechanism,
 	}
 
 	// If SPICE port exists but agent isn't running, try to auto-enable it
 	if spiceStatus.VPortExists && !spiceStatus.ClipboardReady {
-		logrus.Info("SPICE virtio port detected, attempting to enable clipboard sharing...")
-		if err := spiceservice.EnsureSpiceAgent(ctx); err != nil {
+		if skipped, err := ensureSpiceAgent(ctx, time.Now()); skipped != "" {
+			addWarning(ctx, "%s", skipped)
+		} else if err != nil {
 			logrus.Warnf("Failed to auto-enable SPICE agent: %v", err)
+			addWarning(ctx, "failed to auto-enable SPICE agent: %v", err)
 		} else {
 			// Re-detect status after enabling
 			spiceStatus = spiceservice.DetectSpiceStatus(ctx)
 			info.Spice.AgentInstalled = spiceStatus.AgentInstalled
 			info.Spice.AgentRunning = spiceStatus.AgentRunning
+			info.Spice.AgentAutostart = spiceStatus.AgentAutostart
+			info.Spice.SessionAgentRunning = spiceStatus.SessionAgentRunning
 			info.Spice.ClipboardReady = spiceStatus.ClipboardReady
 			info.Spice.ErrorMessage = spiceStatus.ErrorMessage
+			info.Spice.MaxClipboardBytes = spiceStatus.MaxClipboardBytes
+			info.Spice.ClipboardMimeTypes = spiceStatus.ClipboardMimeTypes
+			info.Spice.ClipboardMechanism = spiceStatus.ClipboardMechanism
 		}
 	}
-
-	return info
-}
-
-// detectX11 checks if X11 is running
-func detectX11() bool {
-	// Check for common X11 sockets
-	if _, err := os.Stat("/tmp/.X11-unix"); err == nil {
-		return true
+	if !info.SessionActive && info.Spice.ClipboardMechanism == spiceservice.ClipboardMechanismGuestAgent {
+		// xclip and wl-clipboard need a display to reach
+		info.Spice.ClipboardMechanism = ""
 	}
-	// Check if DISPLAY is set
-	if os.Getenv("DISPLAY") != "" {
-		return true
+	if info.SessionActive {
+		detectClipboardOwner(ctx, info)
 	}
-	return false
-}
+	info.DynamicResolutionAgent = getDynamicResolutionAgent(ctx, info)
+	info.DynamicResolutionSupported = info.DynamicResolutionAgent != ""
 
-// detectWayland checks if Wayland is running
-func detectWayland() bool {
-	// Check for Wayland socket
-	if os.Getenv("WAYLAND_DISPLAY") != "" {
-		return true
-	}
-	// Check XDG_SESSION_TYPE
-	if os.Getenv("XDG_SESSION_TYPE") == "wayland" {
-		return true
-	}
-	return false
+	info.Warnings = *warnings
+	return info
 }
 
-// getX11Displays returns list of active X11 displays
-func getX11Displays() []string {
-	displays := []string{}
+// inputDevicesFile lists the input devices known to the kernel
+const inputDevicesFile = "/proc/bus/input/devices"
 
-	// Check DISPLAY environment variable
-	if display := os.Getenv("DISPLAY"); display != "" {
-		displays = append(displays, display)
-		return displays
-	}
-
-	// Check /tmp/.X11-unix for active displays
-	entries, err := os.ReadDir("/tmp/.X11-unix")
+// hasAbsolutePointer checks if an absolute pointing device (e.g., usb-tablet or virtio-tablet) is present
+func hasAbsolutePointer(ctx context.Context) bool {
+	f, err := os.Open(inputDevicesFile)
 	if err != nil {
-		return displays
+		addWarning(ctx, "cannot read %s: %v", inputDevicesFile, err)
+		return false
 	}
+	defer f.Close()
+	return parseAbsolutePointer(f)
+}
 
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "X") {
-			displayNum := strings.TrimPrefix(entry.Name(), "X")
-			displays = append(displays, ":"+displayNum)
+// parseAbsolutePointer parses /proc/bus/input/devices, looking for a pointer ("mouse" handler)
+// that reports absolute X and Y axes
+func parseAbsolutePointer(r io.Reader) bool {
+	var isPointer, hasAbsXY bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			// End of a device block
+			if isPointer && hasAbsXY {
+				return true
+			}
+			isPointer, hasAbsXY = false, false
+		case strings.HasPrefix(line, "H: Handlers="):
+			for _, h := range strings.Fields(strings.TrimPrefix(line, "H: Handlers=")) {
+				if strings.HasPrefix(h, "mouse") {
+					isPointer = true
+				}
+			}
+		case strings.HasPrefix(line, "B: ABS="):
+			// Hex words, most significant first; ABS_X and ABS_Y are bits 0 and 1
+			words := strings.Fields(strings.TrimPrefix(line, "B: ABS="))
+			if len(words) > 0 {
+				if bits, err := strconv.ParseUint(words[len(words)-1], 16, 64); err == nil {
+					hasAbsXY = bits&0x3 == 0x3
+				}
+			}
 		}
 	}
-
-	return displays
+	return isPointer && hasAbsXY
 }
 
-// getWaylandDisplays returns list of active Wayland displays
-func getWaylandDisplays() []string {
-	displays := []string{}
-
-	if display := os.Getenv("WAYLAND_DISPLAY"); display != "" {
-		displays = append(displays, display)
+// getSystemdTargets returns the systemd default target and whether graphical.target is active
+func getSystemdTargets(ctx context.Context) (defaultTarget string, graphicalActive bool) {
+	output := runProbe(ctx, 2*time.Second, "systemctl", "get-default")
+	if output == nil {
+		return "", false
 	}
+	defaultTarget = strings.TrimSpace(string(output))
 
-	return displays
+	// is-active exits with a non-zero status for inactive units, so the output is what matters
+	output, _ = runner(ctx, 2*time.Second, "systemctl", "is-active", "graphical.target")
+	return defaultTarget, strings.TrimSpace(string(output)) == "active"
 }
 
-// getResolution attempts to get the current display resolution
-func getResolution(displayServer string) string {
-	switch displayServer {
-	case "X11":
-		return getX11Resolution()
-	case "Wayland":
-		return getWaylandResolution()
+// parseWayVNCEndpoint finds the socket of wayvnc in the output of `ss -Hltnp`, e.g.,
+// "LISTEN 0 16 127.0.0.1:5900 0.0.0.0:* users:(("wayvnc",pid=1234,fd=9))"
+func parseWayVNCEndpoint(output string) string {
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !strings.Contains(fields[5], `(("wayvnc",`) {
+			continue
+		}
+		return fields[3]
 	}
 	return ""
 }
 
-// getX11Resolution gets resolution from X11
-func getX11Resolution() string {
-	// Try xrandr first
-	if resolution := tryXrandr(); resolution != "" {
-		return resolution
-	}
-
-	// Try xdpyinfo as fallback
-	if resolution := tryXdpyinfo(); resolution != "" {
-		return resolution
-	}
-
-	return ""
+// remoteDisplayServers are the process names of the RDP and VNC servers reported by
+// parseRemoteDisplayServers; names longer than 15 characters are truncated by the kernel
+var remoteDisplayServers = []string{
+	"xrdp",
+	"x11vnc",
+	"Xvnc",
+	"Xtigervnc",
+	"x0vncserver",
+	"gnome-remote-de", // gnome-remote-desktop-daemon
+	"krfb",
+	"krdpserver",
+	"freerdp-shadow-",
 }
 
-// tryXrandr tries to get resolution from xrandr
-func tryXrandr() string {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "xrandr")
-	if display := os.Getenv("DISPLAY"); display != "" {
-		cmd.Env = append(os.Environ(), "DISPLAY="+display)
-	}
-
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	// Parse xrandr output for current resolution
-	// Look for lines like "   1920x1080     60.00*+"
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "*") {
-			fields := strings.Fields(line)
-			if len(fields) > 0 && strings.Contains(fields[0], "x") {
-				return fields[0]
+// parseRemoteDisplayServers lists the RDP and VNC servers listening in the output of `ss -Hltnp`,
+// e.g., "xrdp, x11vnc". wayvnc is reported as the VNC endpoint instead.
+func parseRemoteDisplayServers(output string) string {
+	var servers []string
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		for _, name := range remoteDisplayServers {
+			if strings.Contains(fields[5], `(("`+name+`",`) && !slices.Contains(servers, name) {
+				servers = append(servers, name)
 			}
 		}
 	}
-
-	return ""
+	return strings.Join(servers, ", ")
 }
 
-// tryXdpyinfo tries to get resolution from xdpyinfo
-func tryXdpyinfo() string {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "xdpyinfo")
-	if display := os.Getenv("DISPLAY"); display != "" {
-		cmd.Env = append(os.Environ(), "DISPLAY="+display)
-	}
-
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	// Look for "dimensions:    1920x1080 pixels"
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "dimensions:") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				return fields[1]
+// sunshinePortOffset is the offset of the HTTPS port of Sunshine below its HTTP port,
+// which Moonlight pairs with (47984 and 47989 by default)
+const sunshinePortOffset = 5
+
+// parseSunshineEndpoint finds the HTTP socket of Sunshine in the output of `ss -Hltnp`, e.g.,
+// "LISTEN 0 4096 0.0.0.0:47989 0.0.0.0:* users:(("sunshine",pid=1402,fd=33))".
+// Sunshine derives all its ports from the HTTP one, so the HTTP socket is the one whose port
+// less sunshinePortOffset is also listened on by Sunshine; the first socket is returned otherwise.
+func parseSunshineEndpoint(output string) string {
+	var endpoints []string
+	ports := map[int]bool{}
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !strings.Contains(fields[5], `(("sunshine",`) {
+			continue
+		}
+		endpoints = append(endpoints, fields[3])
+		if _, port, err := net.SplitHostPort(fields[3]); err == nil {
+			if p, err := strconv.Atoi(port); err == nil {
+				ports[p] = true
 			}
 		}
 	}
-
-	return ""
-}
-
-// getWaylandResolution gets resolution from Wayland
-func getWaylandResolution() string {
-	// Try wlr-randr for wlroots-based compositors
-	if resolution := tryWlrRandr(); resolution != "" {
-		return resolution
-	}
-
-	// Try parsing from swaymsg for Sway
-	if resolution := trySwaymsg(); resolution != "" {
-		return resolution
-	}
-
-	return ""
-}
-
-// tryWlrRandr tries to get resolution from wlr-randr
-func tryWlrRandr() string {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "wlr-randr")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	// Parse for current mode
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "current") {
-			fields := strings.Fields(line)
-			for _, field := range fields {
-				if strings.Contains(field, "x") && strings.Contains(field, "@") {
-					// Format: 1920x1080@60.000000
-					parts := strings.Split(field, "@")
-					if len(parts) > 0 {
-						return parts[0]
-					}
-				}
+	for _, endpoint := range endpoints {
+		if _, port, err := net.SplitHostPort(endpoint); err == nil {
+			if p, err := strconv.Atoi(port); err == nil && ports[p-sunshinePortOffset] {
+				return endpoint
 			}
 		}
 	}
-
-	return ""
-}
-
-// trySwaymsg tries to get resolution from swaymsg
-func trySwaymsg() string {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "swaymsg", "-t", "get_outputs")
-	output, err := cmd.Output()
-	if err != nil {
+	if len(endpoints) == 0 {
 		return ""
 	}
-
-	// Parse JSON output (simplified - look for "current_mode")
-	// This is a simple string search, not full JSON parsing
-	if strings.Contains(string(output), "current_mode") {
-		// Try to extract resolution pattern
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "width") || strings.Contains(line, "height") {
-				// This is a simplified parser - for production use proper JSON
-				logrus.Debug("Found sway output, but skipping complex JSON parsing")
-				break
-			}
-		}
-	}
-
-	return ""
+	return endpoints[0]
 }
 
-// getIdleTime gets the idle time in milliseconds
-func getIdleTime(displayServer string) int64 {
-	switch displayServer {
-	case "X11":
-		return getX11IdleTime()
-	case "Wayland":
-		// Wayland idle time detection is compositor-specific and complex
-		return 0
-	}
-	return 0
+// sunshineRunning checks for a Sunshine process, whose sockets are hidden from ss when the guest agent is not root
+func sunshineRunning(ctx context.Context) bool {
+	// pgrep fails when no process matches
+	_, err := runner(ctx, 2*time.Second, "pgrep", "-x", "sunshine")
+	return err == nil
 }
 
-// getX11IdleTime gets idle time from X11 using xprintidle or xssstate
-func getX11IdleTime() int64 {
-	// Try xprintidle first
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "xprintidle")
-	if display := os.Getenv("DISPLAY"); display != "" {
-		cmd.Env = append(os.Environ(), "DISPLAY="+display)
+// getIdleInhibited checks if a systemd-logind inhibitor lock blocks idle.
+// Wayland clients using the idle-inhibit protocol are only known to the compositor, and are not detected.
+func getIdleInhibited(ctx context.Context) bool {
+	output := runProbe(ctx, 2*time.Second, "systemd-inhibit", "--list", "--no-pager")
+	if output == nil {
+		return false
 	}
+	return parseIdleInhibited(string(output))
+}
 
-	output, err := cmd.Output()
-	if err == nil {
-		idleStr := strings.TrimSpace(string(output))
-		if idleMs, err := strconv.ParseInt(idleStr, 10, 64); err == nil {
-			return idleMs
+// parseIdleInhibited looks for a "block" lock on "idle" in the table printed by `systemd-inhibit --list`:
+//
+//	WHO            UID  USER PID  COMM  WHAT  WHY         MODE
+//	gnome-session  1000 user 1234 sleep idle  keep awake  block
+//
+// WHO and WHY may contain spaces, so the WHAT column is found by its position in the header.
+func parseIdleInhibited(output string) bool {
+	whatColumn := -1
+	for line := range strings.Lines(output) {
+		if whatColumn < 0 {
+			if strings.HasPrefix(strings.TrimSpace(line), "WHO") {
+				whatColumn = strings.Index(line, " WHAT ") + 1
+			}
+			continue
 		}
-	}
-
-	// Try xssstate as fallback
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel2()
-
-	cmd2 := exec.CommandContext(ctx2, "xssstate", "-i")
-	if display := os.Getenv("DISPLAY"); display != "" {
-		cmd2.Env = append(os.Environ(), "DISPLAY="+display)
-	}
-
-	output2, err := cmd2.Output()
-	if err == nil {
-		output2 = bytes.TrimSpace(output2)
-		if idleMs, err := strconv.ParseInt(string(output2), 10, 64); err == nil {
-			return idleMs
+		runes := []rune(line)
+		fields := strings.Fields(line)
+		if whatColumn == 0 || len(runes) <= whatColumn || len(fields) == 0 || fields[len(fields)-1] != "block" {
+			continue
+		}
+		what, _, _ := strings.Cut(strings.TrimLeft(string(runes[whatColumn:]), " "), " ")
+		if slices.Contains(strings.Split(what, ":"), "idle") {
+			return true
 		}
 	}
-
-	return 0
+	return false
 }
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAbsolutePointer(t *testing.T) {
+	const keyboard = `I: Bus=0006 Vendor=0627 Product=0001 Version=0001
+N: Name="QEMU Virtio Keyboard"
+H: Handlers=sysrq kbd event0
+B: EV=120013
+`
+	const relativeMouse = `I: Bus=0006 Vendor=0627 Product=0002 Version=0001
+N: Name="QEMU Virtio Mouse"
+H: Handlers=mouse0 event1
+B: EV=7
+B: REL=143
+`
+	const tablet = `I: Bus=0003 Vendor=0627 Product=0001 Version=0001
+N: Name="QEMU QEMU USB Tablet"
+H: Handlers=mouse1 event2
+B: EV=1f
+B: ABS=3
+`
+	const touchpadWithoutXY = `I: Bus=0011 Vendor=0002 Product=0007 Version=01b1
+N: Name="Fake Pad"
+H: Handlers=mouse2 event3
+B: ABS=260800000000000
+`
+	assert.Assert(t, !parseAbsolutePointer(strings.NewReader(keyboard+"\n"+relativeMouse+"\n")))
+	assert.Assert(t, !parseAbsolutePointer(strings.NewReader(touchpadWithoutXY)))
+	assert.Assert(t, parseAbsolutePointer(strings.NewReader(keyboard+"\n"+tablet+"\n")))
+	assert.Assert(t, parseAbsolutePointer(strings.NewReader(relativeMouse+"\n"+tablet)))
+}
+
+func TestGetSystemdTargets(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"systemctl get-default":                "multi-user.target\n",
+		"systemctl is-active graphical.target": "inactive\n",
+	})
+	target, active := getSystemdTargets(t.Context())
+	assert.Equal(t, target, "multi-user.target")
+	assert.Assert(t, !active)
+
+	fakeRunner(t, map[string]string{
+		"systemctl get-default":                "graphical.target\n",
+		"systemctl is-active graphical.target": "active\n",
+	})
+	target, active = getSystemdTargets(t.Context())
+	assert.Equal(t, target, "graphical.target")
+	assert.Assert(t, active)
+
+	// Without systemd
+	fakeRunner(t, nil)
+	target, active = getSystemdTargets(t.Context())
+	assert.Equal(t, target, "")
+	assert.Assert(t, !active)
+}
+
+func TestParseWayVNCEndpoint(t *testing.T) {
+	const output = `LISTEN 0      4096   127.0.0.53%lo:53        0.0.0.0:*    users:(("systemd-resolve",pid=512,fd=14))
+LISTEN 0      16         127.0.0.1:5900      0.0.0.0:*    users:(("wayvnc",pid=1234,fd=9))
+LISTEN 0      128           [::]:22           [::]:*    users:(("sshd",pid=801,fd=4))
+`
+	assert.Equal(t, parseWayVNCEndpoint(output), "127.0.0.1:5900")
+	assert.Equal(t, parseWayVNCEndpoint(`LISTEN 0 128 [::]:22 [::]:* users:(("sshd",pid=801,fd=4))`), "")
+	assert.Equal(t, parseWayVNCEndpoint(""), "")
+}
+
+func TestParseRemoteDisplayServers(t *testing.T) {
+	const output = `LISTEN 0      2          0.0.0.0:3389      0.0.0.0:*    users:(("xrdp",pid=901,fd=11))
+LISTEN 0      2        127.0.0.1:3350      0.0.0.0:*    users:(("xrdp-sesman",pid=890,fd=7))
+LISTEN 0      32       127.0.0.1:5900      0.0.0.0:*    users:(("x11vnc",pid=1500,fd=6))
+LISTEN 0      32           [::1]:5900         [::]:*    users:(("x11vnc",pid=1500,fd=7))
+LISTEN 0      16       127.0.0.1:5901      0.0.0.0:*    users:(("wayvnc",pid=1234,fd=9))
+`
+	assert.Equal(t, parseRemoteDisplayServers(output), "xrdp, x11vnc")
+	assert.Equal(t, parseRemoteDisplayServers(`LISTEN 0 128 [::]:22 [::]:* users:(("sshd",pid=801,fd=4))`), "")
+}
+
+func TestParseSunshineEndpoint(t *testing.T) {
+	const output = `LISTEN 0      4096         0.0.0.0:47984      0.0.0.0:*    users:(("sunshine",pid=1402,fd=31))
+LISTEN 0      4096         0.0.0.0:47989      0.0.0.0:*    users:(("sunshine",pid=1402,fd=33))
+LISTEN 0      4096       127.0.0.1:47990      0.0.0.0:*    users:(("sunshine",pid=1402,fd=35))
+LISTEN 0      4096         0.0.0.0:48010      0.0.0.0:*    users:(("sunshine",pid=1402,fd=37))
+LISTEN 0      128             [::]:22            [::]:*    users:(("sshd",pid=801,fd=4))
+`
+	assert.Equal(t, parseSunshineEndpoint(output), "0.0.0.0:47989")
+	// A custom port moves all the ports of Sunshine
+	assert.Equal(t, parseSunshineEndpoint(`LISTEN 0 4096 0.0.0.0:50000 0.0.0.0:* users:(("sunshine",pid=1402,fd=31))
+LISTEN 0 4096 0.0.0.0:50021 0.0.0.0:* users:(("sunshine",pid=1402,fd=37))
+LISTEN 0 4096 0.0.0.0:50005 0.0.0.0:* users:(("sunshine",pid=1402,fd=33))
+`), "0.0.0.0:50005")
+	assert.Equal(t, parseSunshineEndpoint(`LISTEN 0 4096 [::]:48010 [::]:* users:(("sunshine",pid=1402,fd=37))`), "[::]:48010")
+	assert.Equal(t, parseSunshineEndpoint(`LISTEN 0 128 [::]:22 [::]:* users:(("sshd",pid=801,fd=4))`), "")
+}
+
+func TestParseIdleInhibited(t *testing.T) {
+	const header = "WHO                UID  USER PID  COMM           WHAT                 WHY                        MODE\n"
+	const delayOnly = header +
+		"NetworkManager     0    root 795  NetworkManager sleep                NetworkManager needs to... delay\n" +
+		"\n1 inhibitors listed.\n"
+	assert.Assert(t, !parseIdleInhibited(delayOnly))
+
+	// COMM is "sleep", and WHY mentions idle, but only the WHAT column counts
+	const keepAwake = header +
+		"NetworkManager     0    root 795  NetworkManager sleep                NetworkManager needs to... delay\n" +
+		"keep awake for CI  1000 user 1234 sleep          sleep:idle           no idle please             block\n" +
+		"\n2 inhibitors listed.\n"
+	assert.Assert(t, parseIdleInhibited(keepAwake))
+
+	const delayedIdle = header +
+		"gnome-session      1000 user 1234 gnome-session  idle                 user session inhibited     delay\n"
+	assert.Assert(t, !parseIdleInhibited(delayedIdle))
+
+	assert.Assert(t, !parseIdleInhibited("0 inhibitors listed.\n"))
+}
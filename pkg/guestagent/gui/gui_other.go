@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux && !freebsd
+
+package gui
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// DetectGUIInfo returns a stub GUIInfo for platforms without GUI detection
+func DetectGUIInfo(_ context.Context) *api.GUIInfo {
+	return &api.GUIInfo{
+		DisplayServer: "unsupported",
+		SchemaVersion: api.GUISchemaVersion,
+	}
+}
+
+// DetectGUIInfoForDisplay returns a stub GUIInfo for platforms without GUI detection
+func DetectGUIInfoForDisplay(ctx context.Context, _ string) *api.GUIInfo {
+	return DetectGUIInfo(ctx)
+}
+
+// EnableAccessibility is not supported on platforms without GUI detection
+func EnableAccessibility(_ context.Context) error {
+	return errors.New("accessibility is not supported on this platform")
+}
+
+// GetClipboard is not supported on platforms without GUI detection
+func GetClipboard(_ context.Context) ([]byte, error) {
+	return nil, errors.New("clipboard is not supported on this platform")
+}
+
+// SetClipboard is not supported on platforms without GUI detection
+func SetClipboard(_ context.Context, _ []byte) error {
+	return errors.New("clipboard is not supported on this platform")
+}
+
+// ListWindows is not supported on platforms without GUI detection
+func ListWindows(_ context.Context) ([]*api.Window, error) {
+	return nil, errors.New("listing windows is not supported on this platform")
+}
+
+// Screenshot is not supported on platforms without GUI detection
+func Screenshot(_ context.Context) ([]byte, error) {
+	return nil, errors.New("screenshots are not supported on this platform")
+}
+
+// SetBlankTimeout is not supported on platforms without GUI detection
+func SetBlankTimeout(_ context.Context, _ int32) error {
+	return errors.New("setting the blank timeout is not supported on this platform")
+}
@@ -0,0 +1,866 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
+)
+
+// commandRunner runs an external command and returns its standard output.
+type commandRunner func(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error)
+
+// runner is used by all probes to run external commands, tests replace it with a fake.
+var runner commandRunner = runCmd
+
+// runCmd runs the named command with a timeout.
+// The output collected so far is returned even when the command fails or is killed by the deadline.
+func runCmd(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	if !tools.Installed(name) {
+		// Fail as exec does
+		return nil, &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := newCommand(ctx, name, args...)
+
+	// Don't wait for grandchildren holding stdout open after the command was killed
+	cmd.WaitDelay = 500 * time.Millisecond
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return stdout.Bytes(), fmt.Errorf("%s timed out after %v", name, timeout)
+	}
+	if err != nil {
+		return stdout.Bytes(), fmt.Errorf("%s failed: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// newCommand prepares the named command, passing DISPLAY and the session environment through to it
+func newCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if env, ok := ctx.Value(sessionEnvKey{}).([]string); ok {
+		// Later entries override the environment of the guest agent
+		cmd.Env = append(os.Environ(), env...)
+	}
+	display := requestedDisplay(ctx)
+	if display == "" {
+		display = getenv(ctx, "DISPLAY")
+	}
+	if display != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "DISPLAY="+display)
+	}
+	return cmd
+}
+
+// sessionEnvKey is the context key for the environment of the graphical session probed by DetectGUIInfo,
+// when it differs from the environment of the guest agent
+type sessionEnvKey struct{}
+
+// withSessionEnv returns a context in which probes see env ("KEY=value" entries) instead of the agent environment
+func withSessionEnv(ctx context.Context, env []string) context.Context {
+	return context.WithValue(ctx, sessionEnvKey{}, env)
+}
+
+// getenv returns the value of an environment variable of the probed graphical session
+func getenv(ctx context.Context, key string) string {
+	env, ok := ctx.Value(sessionEnvKey{}).([]string)
+	if !ok {
+		return os.Getenv(key)
+	}
+	for _, kv := range slices.Backward(env) {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// displayKey is the context key for the X11 display selected by DetectGUIInfoForDisplay
+type displayKey struct{}
+
+// DetectGUIInfoForDisplay is like DetectGUIInfo, but probes the given X11 display (e.g., ":1")
+// instead of the primary one. An empty display selects the primary display.
+func DetectGUIInfoForDisplay(ctx context.Context, display string) *api.GUIInfo {
+	if display == "" {
+		return DetectGUIInfo(ctx)
+	}
+	return DetectGUIInfo(context.WithValue(ctx, displayKey{}, display))
+}
+
+// requestedDisplay returns the X11 display selected by DetectGUIInfoForDisplay, if any
+func requestedDisplay(ctx context.Context) string {
+	display, _ := ctx.Value(displayKey{}).(string)
+	return display
+}
+
+// detectDisplays sets the display server and the list of active displays
+func detectDisplays(ctx context.Context, info *api.GUIInfo) {
+	if display := requestedDisplay(ctx); display != "" {
+		// An explicitly selected display is always an X11 display
+		if x11DisplayExists(ctx, display) {
+			info.DisplayServer = "X11"
+			info.Displays = []string{display}
+		} else {
+			addWarning(ctx, "X11 display %s not found", display)
+		}
+		return
+	}
+
+	if detectWayland(ctx) {
+		info.DisplayServer = "Wayland"
+		info.Displays = getWaylandDisplays(ctx)
+	} else if detectX11(ctx) {
+		info.DisplayServer = "X11"
+		info.Displays = getX11Displays(ctx)
+	}
+}
+
+// x11DisplayExists checks if the local X11 display (e.g., ":1" or ":1.0") has a server socket
+func x11DisplayExists(ctx context.Context, display string) bool {
+	if display == getenv(ctx, "DISPLAY") {
+		return true
+	}
+	num, ok := strings.CutPrefix(display, ":")
+	if !ok {
+		return false
+	}
+	num, _, _ = strings.Cut(num, ".")
+	_, err := os.Stat("/tmp/.X11-unix/X" + num)
+	return err == nil
+}
+
+// warningsKey is the context key for the warnings collected by a DetectGUIInfo run
+type warningsKey struct{}
+
+// withWarnings returns a context in which probes record why they could not complete
+func withWarnings(ctx context.Context) (context.Context, *[]string) {
+	warnings := &[]string{}
+	return context.WithValue(ctx, warningsKey{}, warnings), warnings
+}
+
+// addWarning records a probe warning, if the context collects them
+func addWarning(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	logrus.Debugf("GUI probe: %s", msg)
+	// Several probes may run the same command, record each failure once
+	if warnings, ok := ctx.Value(warningsKey{}).(*[]string); ok && !slices.Contains(*warnings, msg) {
+		*warnings = append(*warnings, msg)
+	}
+}
+
+// runProbe runs a probe command, recording failures as warnings.
+// It returns nil only when the command produced no usable output.
+func runProbe(ctx context.Context, timeout time.Duration, name string, args ...string) []byte {
+	output, err := runner(ctx, timeout, name, args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		addWarning(ctx, "%s not installed", name)
+	} else if err != nil {
+		addWarning(ctx, "%v", err)
+	}
+	if len(output) == 0 {
+		return nil
+	}
+	return output
+}
+
+// detectX11 checks if X11 is running
+func detectX11(ctx context.Context) bool {
+	// Check for common X11 sockets
+	if _, err := os.Stat("/tmp/.X11-unix"); err == nil {
+		return true
+	}
+	// Check if DISPLAY is set
+	if getenv(ctx, "DISPLAY") != "" {
+		return true
+	}
+	return false
+}
+
+// detectWayland checks if Wayland is running
+func detectWayland(ctx context.Context) bool {
+	// Check for Wayland socket
+	if getenv(ctx, "WAYLAND_DISPLAY") != "" {
+		return true
+	}
+	// Check XDG_SESSION_TYPE
+	if getenv(ctx, "XDG_SESSION_TYPE") == "wayland" {
+		return true
+	}
+	return false
+}
+
+// getX11Displays returns list of active X11 displays
+func getX11Displays(ctx context.Context) []string {
+	displays := []string{}
+
+	// Check DISPLAY environment variable
+	if display := getenv(ctx, "DISPLAY"); display != "" {
+		displays = append(displays, display)
+		return displays
+	}
+
+	// Check /tmp/.X11-unix for active displays
+	entries, err := os.ReadDir("/tmp/.X11-unix")
+	if err != nil {
+		return displays
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "X") {
+			displayNum := strings.TrimPrefix(entry.Name(), "X")
+			displays = append(displays, ":"+displayNum)
+		}
+	}
+
+	return displays
+}
+
+// getWaylandDisplays returns list of active Wayland displays
+func getWaylandDisplays(ctx context.Context) []string {
+	displays := []string{}
+
+	if display := getenv(ctx, "WAYLAND_DISPLAY"); display != "" {
+		displays = append(displays, display)
+	}
+
+	return displays
+}
+
+// getResolution attempts to get the current display resolution
+func getResolution(ctx context.Context, displayServer string) string {
+	switch displayServer {
+	case "X11":
+		return getX11Resolution(ctx)
+	case "Wayland":
+		return getWaylandResolution(ctx)
+	}
+	return ""
+}
+
+// getX11Resolution gets resolution from X11
+func getX11Resolution(ctx context.Context) string {
+	// Ask the X server directly, without depending on X11 utilities in the guest
+	screen, err := x11QueryScreen(ctx)
+	if err == nil {
+		return screen.Resolution()
+	}
+	logrus.WithError(err).Debug("GUI probe: cannot read the X11 screen, falling back to xrandr")
+
+	// Try xrandr
+	if resolution := tryXrandr(ctx); resolution != "" {
+		return resolution
+	}
+
+	// Try xdpyinfo as fallback
+	if resolution := tryXdpyinfo(ctx); resolution != "" {
+		return resolution
+	}
+
+	return ""
+}
+
+// tryXrandr tries to get resolution from xrandr
+func tryXrandr(ctx context.Context) string {
+	output := runProbe(ctx, 2*time.Second, "xrandr")
+	if output == nil {
+		return ""
+	}
+
+	// Parse xrandr output for current resolution
+	// Look for lines like "   1920x1080     60.00*+"
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "*") {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && strings.Contains(fields[0], "x") {
+				return fields[0]
+			}
+		}
+	}
+
+	return ""
+}
+
+// tryXdpyinfo tries to get resolution from xdpyinfo
+func tryXdpyinfo(ctx context.Context) string {
+	output := runProbe(ctx, 2*time.Second, "xdpyinfo")
+	if output == nil {
+		return ""
+	}
+
+	// Look for "dimensions:    1920x1080 pixels"
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "dimensions:") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				return fields[1]
+			}
+		}
+	}
+
+	return ""
+}
+
+// getWaylandResolution gets resolution from Wayland
+func getWaylandResolution(ctx context.Context) string {
+	// Try wlr-randr for wlroots-based compositors
+	if resolution := tryWlrRandr(ctx); resolution != "" {
+		return resolution
+	}
+
+	// Try parsing from swaymsg for Sway
+	if resolution := trySwaymsg(ctx); resolution != "" {
+		return resolution
+	}
+
+	// Try kscreen-doctor for KDE Plasma, as KWin implements neither
+	if resolution := tryKscreenDoctor(ctx); resolution != "" {
+		return resolution
+	}
+
+	return ""
+}
+
+// tryWlrRandr tries to get resolution from wlr-randr
+func tryWlrRandr(ctx context.Context) string {
+	output := runProbe(ctx, 2*time.Second, "wlr-randr")
+	if output == nil {
+		return ""
+	}
+
+	// Parse for current mode
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "current") {
+			fields := strings.Fields(line)
+			for _, field := range fields {
+				if strings.Contains(field, "x") && strings.Contains(field, "@") {
+					// Format: 1920x1080@60.000000
+					parts := strings.Split(field, "@")
+					if len(parts) > 0 {
+						return parts[0]
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// swayOutput is the subset of a swaymsg get_outputs entry used for detection
+type swayOutput struct {
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+	Focused     bool   `json:"focused"`
+	Primary     bool   `json:"primary"`
+	CurrentMode struct {
+		Width   int `json:"width"`
+		Height  int `json:"height"`
+		Refresh int `json:"refresh"` // mHz
+	} `json:"current_mode"`
+	Modes []struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"modes"`
+	Rect struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"rect"`
+	AdaptiveSyncStatus string `json:"adaptive_sync_status"` // "enabled" or "disabled", since Sway 1.5
+}
+
+// trySwaymsg tries to get resolution from swaymsg
+func trySwaymsg(ctx context.Context) string {
+	output := runProbe(ctx, 2*time.Second, "swaymsg", "-t", "get_outputs")
+	if output == nil {
+		return ""
+	}
+
+	var outputs []swayOutput
+	if err := json.Unmarshal(output, &outputs); err != nil {
+		addWarning(ctx, "swaymsg returned unparseable JSON: %v", err)
+		return ""
+	}
+
+	// Prefer the focused output, then the first active one
+	var resolution string
+	for _, o := range outputs {
+		if !o.Active || o.CurrentMode.Width == 0 || o.CurrentMode.Height == 0 {
+			continue
+		}
+		current := fmt.Sprintf("%dx%d", o.CurrentMode.Width, o.CurrentMode.Height)
+		if o.Focused {
+			return current
+		}
+		if resolution == "" {
+			resolution = current
+		}
+	}
+	return resolution
+}
+
+// kscreenOutput is the subset of an output of `kscreen-doctor -j` used for detection
+type kscreenOutput struct {
+	Name          string `json:"name"`
+	Enabled       bool   `json:"enabled"`
+	Connected     bool   `json:"connected"`
+	Primary       bool   `json:"primary"`  // Plasma 5
+	Priority      int    `json:"priority"` // Plasma 6, 1 for the primary output
+	CurrentModeID string `json:"currentModeId"`
+	Modes         []struct {
+		ID   string `json:"id"`
+		Size struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"size"`
+		RefreshRate float64 `json:"refreshRate"`
+	} `json:"modes"`
+	Pos struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"pos"`
+	VrrPolicy *int `json:"vrrPolicy"` // 0: never, 1: always, 2: automatic (fullscreen applications); since Plasma 5.22
+}
+
+// parseKscreenDoctor returns the enabled outputs of `kscreen-doctor -j`, with the size and refresh rate of their current mode
+func parseKscreenDoctor(output []byte) ([]*api.DisplayMode, error) {
+	var doc struct {
+		Outputs []kscreenOutput `json:"outputs"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		return nil, fmt.Errorf("kscreen-doctor returned unparseable JSON: %w", err)
+	}
+	var outputs []*api.DisplayMode
+	for _, o := range doc.Outputs {
+		if !o.Enabled || !o.Connected {
+			continue
+		}
+		for _, m := range o.Modes {
+			if m.ID != o.CurrentModeID || m.Size.Width == 0 || m.Size.Height == 0 {
+				continue
+			}
+			outputs = append(outputs, &api.DisplayMode{
+				Name:                 o.Name,
+				Width:                int32(m.Size.Width),
+				Height:               int32(m.Size.Height),
+				X:                    int32(o.Pos.X),
+				Y:                    int32(o.Pos.Y),
+				Primary:              o.Primary || o.Priority == 1,
+				AdaptiveSync:         o.VrrPolicy != nil && *o.VrrPolicy != 0,
+				AdaptiveSyncReported: o.VrrPolicy != nil,
+				RefreshRate:          m.RefreshRate,
+			})
+			break
+		}
+	}
+	return outputs, nil
+}
+
+// kscreenDoctorOutputs parses the enabled outputs from kscreen-doctor, on KDE Plasma
+func kscreenDoctorOutputs(ctx context.Context) []*api.DisplayMode {
+	output := runProbe(ctx, 2*time.Second, "kscreen-doctor", "-j")
+	if output == nil {
+		return nil
+	}
+	outputs, err := parseKscreenDoctor(output)
+	if err != nil {
+		addWarning(ctx, "%v", err)
+		return nil
+	}
+	return outputs
+}
+
+// tryKscreenDoctor tries to get the resolution of the primary output, or of the first one, from kscreen-doctor
+func tryKscreenDoctor(ctx context.Context) string {
+	outputs := kscreenDoctorOutputs(ctx)
+	var resolution string
+	for _, o := range outputs {
+		current := fmt.Sprintf("%dx%d", o.Width, o.Height)
+		if o.Primary {
+			return current
+		}
+		if resolution == "" {
+			resolution = current
+		}
+	}
+	return resolution
+}
+
+// getOutputs describes the layout of the outputs making up the virtual desktop, and the DRM connector IDs
+// of the outputs by name when the X server reports them
+func getOutputs(ctx context.Context, displayServer string) ([]*api.DisplayMode, map[string]uint32) {
+	switch displayServer {
+	case "X11":
+		if screen, err := x11QueryScreen(ctx); err == nil && screen.Monitors != nil {
+			return screen.Monitors, screen.ConnectorIDs
+		}
+		return xrandrOutputs(ctx), nil
+	case "Wayland":
+		if outputs := wlrRandrOutputs(ctx); len(outputs) > 0 {
+			return outputs, nil
+		}
+		if outputs := swaymsgOutputs(ctx); len(outputs) > 0 {
+			return outputs, nil
+		}
+		return kscreenDoctorOutputs(ctx), nil
+	}
+	return nil, nil
+}
+
+// xrandrGeometry matches the geometry of an active output, e.g., "1920x1080+1920+0"
+var xrandrGeometry = regexp.MustCompile(`^(\d+)x(\d+)\+(-?\d+)\+(-?\d+)$`)
+
+// xrandrOutputs parses the connected outputs from xrandr, e.g.,
+// "Virtual-1 connected primary 1920x1080+0+0 (normal left inverted right x axis y axis) 0mm x 0mm",
+// with the refresh rate marked with "*" in their mode list, e.g., "   1920x1080     60.00*+  59.94"
+func xrandrOutputs(ctx context.Context) []*api.DisplayMode {
+	output := runProbe(ctx, 2*time.Second, "xrandr")
+	if output == nil {
+		return nil
+	}
+
+	var outputs []*api.DisplayMode
+	var current *api.DisplayMode
+	for line := range strings.Lines(string(output)) {
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, " ") {
+			if current != nil && current.RefreshRate == 0 {
+				current.RefreshRate = xrandrCurrentRate(fields)
+			}
+			continue
+		}
+		current = nil
+		if len(fields) < 3 || fields[1] != "connected" {
+			continue
+		}
+		mode := &api.DisplayMode{Name: fields[0]}
+		for _, field := range fields[2:] {
+			if field == "primary" {
+				mode.Primary = true
+				continue
+			}
+			if m := xrandrGeometry.FindStringSubmatch(field); m != nil {
+				mode.Width, mode.Height, mode.X, mode.Y = atoi32(m[1]), atoi32(m[2]), atoi32(m[3]), atoi32(m[4])
+				break
+			}
+		}
+		// Connected outputs without a geometry are disabled
+		if mode.Width > 0 {
+			outputs = append(outputs, mode)
+			current = mode
+		}
+	}
+	return outputs
+}
+
+// xrandrCurrentRate returns the refresh rate marked as current in the fields of an xrandr mode line,
+// e.g., 60 for "1920x1080 60.00*+ 59.94", or 0 if the mode is not the current one
+func xrandrCurrentRate(fields []string) float64 {
+	for _, field := range fields[min(1, len(fields)):] {
+		rate, ok := strings.CutSuffix(strings.TrimSuffix(field, "+"), "*")
+		if !ok {
+			continue
+		}
+		if hz, err := strconv.ParseFloat(rate, 64); err == nil {
+			return hz
+		}
+	}
+	return 0
+}
+
+// wlrRandrMode matches the size and the refresh rate of a mode, e.g., "1920x1080@60.000000" or "1920x1080 px, 60.000000 Hz (current)"
+var wlrRandrMode = regexp.MustCompile(`^\s+(\d+)x(\d+)(?:@| px, )?([\d.]+)?`)
+
+// wlrRandrOutputs parses the enabled outputs from wlr-randr
+func wlrRandrOutputs(ctx context.Context) []*api.DisplayMode {
+	output := runProbe(ctx, 2*time.Second, "wlr-randr")
+	if output == nil {
+		return nil
+	}
+
+	var outputs []*api.DisplayMode
+	var current *api.DisplayMode
+	enabled := true
+	flush := func() {
+		if current != nil && enabled && current.Width > 0 {
+			outputs = append(outputs, current)
+		}
+	}
+	for line := range strings.Lines(string(output)) {
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			// A new output starts with its unindented name
+			flush()
+			name, _, _ := strings.Cut(line, " ")
+			current, enabled = &api.DisplayMode{Name: name}, true
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Enabled:"):
+			enabled = strings.TrimSpace(strings.TrimPrefix(trimmed, "Enabled:")) == "yes"
+		case strings.HasPrefix(trimmed, "Position:"):
+			x, y, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(trimmed, "Position:")), ",")
+			current.X, current.Y = atoi32(x), atoi32(y)
+		case strings.HasPrefix(trimmed, "Adaptive Sync:"):
+			// Since wlr-randr 0.3
+			current.AdaptiveSync = strings.TrimSpace(strings.TrimPrefix(trimmed, "Adaptive Sync:")) == "enabled"
+			current.AdaptiveSyncReported = true
+		case strings.Contains(trimmed, "current"):
+			if m := wlrRandrMode.FindStringSubmatch(line); m != nil {
+				current.Width, current.Height = atoi32(m[1]), atoi32(m[2])
+				current.RefreshRate, _ = strconv.ParseFloat(m[3], 64)
+			}
+		}
+	}
+	flush()
+	return outputs
+}
+
+// swaymsgOutputs parses the active outputs from swaymsg; the focused output is reported as primary
+func swaymsgOutputs(ctx context.Context) []*api.DisplayMode {
+	output := runProbe(ctx, 2*time.Second, "swaymsg", "-t", "get_outputs")
+	if output == nil {
+		return nil
+	}
+
+	var swayOutputs []swayOutput
+	if err := json.Unmarshal(output, &swayOutputs); err != nil {
+		addWarning(ctx, "swaymsg returned unparseable JSON: %v", err)
+		return nil
+	}
+	var outputs []*api.DisplayMode
+	for _, o := range swayOutputs {
+		if !o.Active || o.CurrentMode.Width == 0 || o.CurrentMode.Height == 0 {
+			continue
+		}
+		outputs = append(outputs, &api.DisplayMode{
+			Name:                 o.Name,
+			Width:                int32(o.CurrentMode.Width),
+			Height:               int32(o.CurrentMode.Height),
+			X:                    int32(o.Rect.X),
+			Y:                    int32(o.Rect.Y),
+			Primary:              o.Primary || o.Focused,
+			AdaptiveSync:         o.AdaptiveSyncStatus == "enabled",
+			AdaptiveSyncReported: o.AdaptiveSyncStatus != "",
+			RefreshRate:          float64(o.CurrentMode.Refresh) / 1000,
+		})
+	}
+	return outputs
+}
+
+// atoi32 converts a decimal string, returning 0 if it is not a valid int32
+func atoi32(s string) int32 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(n)
+}
+
+// detectCompositing checks if a compositing manager is running.
+// Wayland compositors always composite; under X11 the compositing manager owns the _NET_WM_CM_S<screen> selection.
+func detectCompositing(ctx context.Context, info *api.GUIInfo) bool {
+	switch info.DisplayServer {
+	case "Wayland":
+		return true
+	case "X11":
+		display := requestedDisplay(ctx)
+		if display == "" && len(info.Displays) > 0 {
+			display = info.Displays[0]
+		}
+		_, screen, err := x11Display(display)
+		if err != nil {
+			addWarning(ctx, "cannot check the X11 compositing manager: %v", err)
+			return false
+		}
+		owned, err := x11SelectionOwned(ctx, display, "_NET_WM_CM_S"+screen)
+		if err != nil {
+			addWarning(ctx, "cannot check the X11 compositing manager: %v", err)
+			return false
+		}
+		return owned
+	}
+	return false
+}
+
+// detectAccessibilityBus checks if the AT-SPI accessibility bus is running.
+// The bus launcher advertises its address in AT_SPI_BUS_ADDRESS, on the AT_SPI_BUS property of the X11 root window,
+// and by owning org.a11y.Bus on the session bus.
+func detectAccessibilityBus(ctx context.Context, displayServer string) bool {
+	if getenv(ctx, "AT_SPI_BUS_ADDRESS") != "" {
+		return true
+	}
+	if displayServer == "X11" {
+		if output := runProbe(ctx, 2*time.Second, "xprop", "-root", "AT_SPI_BUS"); output != nil {
+			// "AT_SPI_BUS(STRING) = ..." when set, "AT_SPI_BUS:  not found." otherwise
+			return bytes.HasPrefix(output, []byte("AT_SPI_BUS("))
+		}
+	}
+	output := runProbe(ctx, 2*time.Second, "dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.DBus",
+		"/org/freedesktop/DBus", "org.freedesktop.DBus.NameHasOwner", "string:org.a11y.Bus")
+	return bytes.Contains(output, []byte("boolean true"))
+}
+
+// EnableAccessibility turns on GNOME toolkit accessibility, so that applications register with the AT-SPI bus
+func EnableAccessibility(ctx context.Context) error {
+	_, err := runner(ctx, 5*time.Second, "gsettings", "set", "org.gnome.desktop.interface", "toolkit-accessibility", "true")
+	return err
+}
+
+// getIdleTime gets the idle time in milliseconds
+func getIdleTime(ctx context.Context, displayServer string) (int64, bool) {
+	switch displayServer {
+	case "X11":
+		return getX11IdleTime(ctx)
+	case "Wayland":
+		// Wayland idle time detection is compositor-specific and complex
+		return 0, false
+	}
+	return 0, false
+}
+
+// settledIdleThreshold is how long the user must have been idle for the session to be considered settled
+const settledIdleThreshold = 5 * time.Second
+
+// settledSampleInterval is the delay between the two window layout samples of detectSessionSettled
+var settledSampleInterval = 250 * time.Millisecond
+
+// detectSessionSettled checks that the session is idle and that no window is moving or resizing,
+// by comparing two snapshots of the X11 window tree. Animations that do not change the window geometry
+// (e.g., fades drawn by the compositor) are not detected. Wayland sessions never report an idle time,
+// so they are never considered settled.
+func detectSessionSettled(ctx context.Context, info *api.GUIInfo) bool {
+	if info.DisplayServer != "X11" || time.Duration(info.IdleTimeMs)*time.Millisecond < settledIdleThreshold {
+		return false
+	}
+	before := runProbe(ctx, 2*time.Second, "xwininfo", "-root", "-tree")
+	if before == nil {
+		// The window tree is unknown, go by the idle time alone
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(settledSampleInterval):
+	}
+	after := runProbe(ctx, 2*time.Second, "xwininfo", "-root", "-tree")
+	return bytes.Equal(before, after)
+}
+
+// getX11IdleTime gets idle time from X11 using xprintidle or xssstate, and false if neither works
+func getX11IdleTime(ctx context.Context) (int64, bool) {
+	// Try xprintidle first, xssstate as fallback
+	probes := [][]string{
+		{"xprintidle"},
+		{"xssstate", "-i"},
+	}
+	for _, probe := range probes {
+		output := runProbe(ctx, 1*time.Second, probe[0], probe[1:]...)
+		if output == nil {
+			continue
+		}
+		if idleMs, err := strconv.ParseInt(string(bytes.TrimSpace(output)), 10, 64); err == nil {
+			return idleMs, true
+		}
+	}
+
+	return 0, false
+}
+
+// getScreenBlankingDisabled checks with `xset q` that neither the X11 screensaver nor DPMS will blank the screen
+func getScreenBlankingDisabled(ctx context.Context) bool {
+	output := runProbe(ctx, 1*time.Second, "xset", "q")
+	if output == nil {
+		return false
+	}
+	return parseScreenBlankingDisabled(output)
+}
+
+// parseScreenBlankingDisabled parses the output of `xset q`: the screensaver is off with "timeout:  0",
+// and DPMS with "DPMS is Disabled", or when the server lacks the DPMS extension.
+func parseScreenBlankingDisabled(output []byte) bool {
+	var screensaverOff, dpmsOff bool
+	for line := range strings.Lines(string(output)) {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "timeout:":
+			screensaverOff = fields[1] == "0"
+		case strings.Contains(line, "DPMS is Disabled"), strings.Contains(line, "does not have the DPMS Extension"):
+			dpmsOff = true
+		}
+	}
+	return screensaverOff && dpmsOff
+}
+
+// getKeyboardLayout gets the keyboard layout from setxkbmap (X11) or localectl
+func getKeyboardLayout(ctx context.Context, displayServer string) string {
+	if displayServer == "X11" {
+		// Look for "layout:     us"
+		if output := runProbe(ctx, 1*time.Second, "setxkbmap", "-query"); output != nil {
+			if layout := parseKeyValue(output, "layout"); layout != "" {
+				return layout
+			}
+		}
+	}
+
+	// Look for "X11 Layout: us", falling back to "VC Keymap: us"
+	output := runProbe(ctx, 2*time.Second, "localectl", "status")
+	if output == nil {
+		return ""
+	}
+	if layout := parseKeyValue(output, "X11 Layout"); layout != "" {
+		return layout
+	}
+	if keymap := parseKeyValue(output, "VC Keymap"); keymap != "" && keymap != "n/a" {
+		return keymap
+	}
+
+	return ""
+}
+
+// parseKeyValue returns the value of the first "key: value" line matching key
+func parseKeyValue(output []byte, key string) string {
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		k, v, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
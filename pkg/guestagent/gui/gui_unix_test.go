@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/testing/protocmp"
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// fakeRunner returns canned output keyed by the command line.
+func fakeRunner(t *testing.T, outputs map[string]string) {
+	t.Helper()
+	orig := runner
+	t.Cleanup(func() { runner = orig })
+	runner = func(_ context.Context, _ time.Duration, name string, args ...string) ([]byte, error) {
+		key := strings.Join(append([]string{name}, args...), " ")
+		if out, ok := outputs[key]; ok {
+			return []byte(out), nil
+		}
+		return nil, &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+}
+
+func TestTryXrandr(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"xrandr": `Screen 0: minimum 320 x 200, current 1920 x 1080, maximum 16384 x 16384
+Virtual-1 connected primary 1920x1080+0+0 0mm x 0mm
+   1920x1080     60.00*+
+   1280x800      59.81
+`,
+	})
+	assert.Equal(t, "1920x1080", tryXrandr(t.Context()))
+}
+
+func TestGetX11ResolutionFallsBackToXdpyinfo(t *testing.T) {
+	// No X server to query directly
+	orig := x11SocketDir
+	t.Cleanup(func() { x11SocketDir = orig })
+	x11SocketDir = t.TempDir()
+	t.Setenv("DISPLAY", ":0")
+	fakeRunner(t, map[string]string{
+		"xdpyinfo": "screen #0:\n  dimensions:    1280x800 pixels (338x211 millimeters)\n",
+	})
+	assert.Equal(t, "1280x800", getX11Resolution(t.Context()))
+}
+
+func TestGetX11IdleTime(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"xssstate -i": "4200\n",
+	})
+	idleMs, ok := getX11IdleTime(t.Context())
+	assert.Assert(t, ok)
+	assert.Equal(t, int64(4200), idleMs)
+
+	fakeRunner(t, nil)
+	_, ok = getX11IdleTime(t.Context())
+	assert.Assert(t, !ok, "no idle time tool")
+}
+
+func TestRunCmdReturnsPartialOutputOnTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	output, err := runCmd(t.Context(), 500*time.Millisecond, "sh", "-c", "echo partial; sleep 5")
+	assert.ErrorContains(t, err, "timed out")
+	assert.Equal(t, "partial\n", string(output))
+}
+
+func TestGetKeyboardLayout(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"setxkbmap -query": "rules:      evdev\nmodel:      pc105\nlayout:     de,us\n",
+		"localectl status": "   System Locale: LANG=en_US.UTF-8\n       VC Keymap: n/a\n      X11 Layout: fr\n",
+	})
+	assert.Equal(t, "de,us", getKeyboardLayout(t.Context(), "X11"))
+	assert.Equal(t, "fr", getKeyboardLayout(t.Context(), "Wayland"))
+}
+
+func TestTrySwaymsg(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"swaymsg -t get_outputs": `[
+  {"name": "HEADLESS-1", "active": true, "focused": false, "current_mode": {"width": 1280, "height": 720}},
+  {"name": "Virtual-1", "active": true, "focused": true, "current_mode": {"width": 1920, "height": 1200}}
+]`,
+	})
+	assert.Equal(t, "1920x1200", trySwaymsg(t.Context()))
+}
+
+func TestXrandrOutputs(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"xrandr": `Screen 0: minimum 320 x 200, current 3200 x 1080, maximum 16384 x 16384
+Virtual-1 connected primary 1920x1080+0+0 (normal left inverted right x axis y axis) 0mm x 0mm
+   1920x1080     60.00*+
+Virtual-2 connected 1280x800+1920+0 (normal left inverted right x axis y axis) 0mm x 0mm
+   1280x800      59.81*
+Virtual-3 connected (normal left inverted right x axis y axis)
+Virtual-4 disconnected (normal left inverted right x axis y axis)
+`,
+	})
+	outputs := xrandrOutputs(t.Context())
+	assert.DeepEqual(t, []*api.DisplayMode{
+		{Name: "Virtual-1", Width: 1920, Height: 1080, Primary: true, RefreshRate: 60},
+		{Name: "Virtual-2", Width: 1280, Height: 800, X: 1920, RefreshRate: 59.81},
+	}, outputs, protocmp.Transform())
+}
+
+func TestWlrRandrOutputs(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"wlr-randr": `Virtual-1 "Red Hat, Inc. QEMU Monitor (Virtual-1)"
+  Enabled: yes
+  Modes:
+    1024x768 px, 60.000000 Hz (preferred)
+    1920x1080 px, 60.000000 Hz (current)
+  Position: 0,0
+  Transform: normal
+  Scale: 1.000000
+  Adaptive Sync: enabled
+Virtual-2 "Red Hat, Inc. QEMU Monitor (Virtual-2)"
+  Enabled: yes
+  Modes:
+    1280x800@59.810001 (current)
+  Position: 1920,0
+Virtual-3 "Red Hat, Inc. QEMU Monitor (Virtual-3)"
+  Enabled: no
+  Modes:
+    1280x800 px, 59.810001 Hz (current)
+`,
+	})
+	outputs := wlrRandrOutputs(t.Context())
+	assert.DeepEqual(t, []*api.DisplayMode{
+		{Name: "Virtual-1", Width: 1920, Height: 1080, AdaptiveSync: true, AdaptiveSyncReported: true, RefreshRate: 60},
+		{Name: "Virtual-2", Width: 1280, Height: 800, X: 1920, RefreshRate: 59.810001},
+	}, outputs, protocmp.Transform())
+}
+
+func TestSwaymsgOutputs(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"swaymsg -t get_outputs": `[
+  {"name": "Virtual-1", "active": true, "focused": true, "current_mode": {"width": 1920, "height": 1200, "refresh": 120000}, "rect": {"x": 0, "y": 0}, "adaptive_sync_status": "disabled"},
+  {"name": "Virtual-2", "active": true, "focused": false, "current_mode": {"width": 1280, "height": 720}, "rect": {"x": 1920, "y": 240}, "adaptive_sync_status": "enabled"},
+  {"name": "HEADLESS-1", "active": false}
+]`,
+	})
+	outputs, _ := getOutputs(t.Context(), "Wayland")
+	assert.DeepEqual(t, []*api.DisplayMode{
+		{Name: "Virtual-1", Width: 1920, Height: 1200, Primary: true, AdaptiveSyncReported: true, RefreshRate: 120},
+		{Name: "Virtual-2", Width: 1280, Height: 720, X: 1920, Y: 240, AdaptiveSync: true, AdaptiveSyncReported: true},
+	}, outputs, protocmp.Transform())
+}
+
+func TestKscreenDoctorOutputs(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"kscreen-doctor -j": `{"outputs": [
+  {"name": "Virtual-1", "enabled": true, "connected": true, "priority": 2, "currentModeId": "2", "pos": {"x": 0, "y": 0},
+   "modes": [{"id": "1", "size": {"width": 1920, "height": 1080}}, {"id": "2", "size": {"width": 1280, "height": 800}, "refreshRate": 74.93}]},
+  {"name": "Virtual-2", "enabled": true, "connected": true, "priority": 1, "currentModeId": "1", "pos": {"x": 1280, "y": 0}, "vrrPolicy": 2,
+   "modes": [{"id": "1", "size": {"width": 2560, "height": 1440}}]},
+  {"name": "Virtual-3", "enabled": false, "connected": true, "currentModeId": "1",
+   "modes": [{"id": "1", "size": {"width": 1024, "height": 768}}]}
+]}`,
+	})
+	outputs, _ := getOutputs(t.Context(), "Wayland")
+	assert.DeepEqual(t, []*api.DisplayMode{
+		{Name: "Virtual-1", Width: 1280, Height: 800, RefreshRate: 74.93},
+		{Name: "Virtual-2", Width: 2560, Height: 1440, X: 1280, Primary: true, AdaptiveSync: true, AdaptiveSyncReported: true},
+	}, outputs, protocmp.Transform())
+	// wlr-randr and swaymsg are not installed on KDE Plasma
+	assert.Equal(t, "2560x1440", getWaylandResolution(t.Context()))
+}
+
+func TestDetectAccessibilityBus(t *testing.T) {
+	t.Setenv("AT_SPI_BUS_ADDRESS", "")
+	fakeRunner(t, map[string]string{
+		"xprop -root AT_SPI_BUS": "AT_SPI_BUS(STRING) = \"unix:path=/run/user/1000/at-spi/bus_0\"\n",
+	})
+	assert.Assert(t, detectAccessibilityBus(t.Context(), "X11"))
+
+	fakeRunner(t, map[string]string{
+		"xprop -root AT_SPI_BUS": "AT_SPI_BUS:  not found.\n",
+	})
+	assert.Assert(t, !detectAccessibilityBus(t.Context(), "X11"))
+
+	fakeRunner(t, map[string]string{
+		"dbus-send --session --print-reply --dest=org.freedesktop.DBus /org/freedesktop/DBus " +
+			"org.freedesktop.DBus.NameHasOwner string:org.a11y.Bus": "method return time=1 sender=org.freedesktop.DBus\n   boolean true\n",
+	})
+	assert.Assert(t, detectAccessibilityBus(t.Context(), "Wayland"))
+}
+
+func TestParseScreenBlankingDisabled(t *testing.T) {
+	const xsetQ = `Keyboard Control:
+  auto repeat:  on    key click percent:  0    LED mask:  00000000
+Screen Saver:
+  prefer blanking:  yes    allow exposures:  yes
+  timeout:  %s    cycle:  600
+DPMS (Energy Star):
+  Standby: 600    Suspend: 600    Off: 600
+  DPMS is %s
+  Monitor is On
+`
+	assert.Assert(t, parseScreenBlankingDisabled(fmt.Appendf(nil, xsetQ, "0", "Disabled")))
+	assert.Assert(t, !parseScreenBlankingDisabled(fmt.Appendf(nil, xsetQ, "600", "Disabled")))
+	assert.Assert(t, !parseScreenBlankingDisabled(fmt.Appendf(nil, xsetQ, "0", "Enabled")))
+	assert.Assert(t, parseScreenBlankingDisabled([]byte("Screen Saver:\n  timeout:  0    cycle:  600\nServer does not have the DPMS Extension\n")))
+}
+
+func TestDetectSessionSettled(t *testing.T) {
+	orig := settledSampleInterval
+	t.Cleanup(func() { settledSampleInterval = orig })
+	settledSampleInterval = time.Millisecond
+
+	tree := "xwininfo: Window id: 0x1e3 (the root window) (has no name)\n  1 child:\n     0x1800003 \"xterm\": (\"xterm\" \"XTerm\")  484x316+10+10  +10+10\n"
+	fakeRunner(t, map[string]string{"xwininfo -root -tree": tree})
+	idle := &api.GUIInfo{DisplayServer: "X11", SessionActive: true, IdleTimeMs: 10000}
+	assert.Assert(t, detectSessionSettled(t.Context(), idle))
+
+	busy := &api.GUIInfo{DisplayServer: "X11", SessionActive: true, IdleTimeMs: 100}
+	assert.Assert(t, !detectSessionSettled(t.Context(), busy))
+
+	// A window moving between the two samples
+	origRunner := runner
+	t.Cleanup(func() { runner = origRunner })
+	x := 0
+	runner = func(context.Context, time.Duration, string, ...string) ([]byte, error) {
+		x += 10
+		return []byte(strings.Replace(tree, "+10+10  +10+10", fmt.Sprintf("+%d+10  +%d+10", x, x), 1)), nil
+	}
+	assert.Assert(t, !detectSessionSettled(t.Context(), idle))
+}
+
+func TestProbeWarnings(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"swaymsg -t get_outputs": "not json",
+	})
+	ctx, warnings := withWarnings(t.Context())
+	assert.Equal(t, "", getWaylandResolution(ctx))
+	assert.DeepEqual(t, []string{
+		"wlr-randr not installed",
+		"swaymsg returned unparseable JSON: invalid character 'o' in literal null (expecting 'u')",
+		"kscreen-doctor not installed",
+	}, *warnings)
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// modeSize matches the size at the start of an indented mode line of xrandr, e.g., "   1920x1080     60.00*+",
+// and of wlr-randr, e.g., "    1920x1080 px, 60.000000 Hz (preferred, current)"
+var modeSize = regexp.MustCompile(`^\s+(\d+)x(\d+)`)
+
+// getSupportedModes lists the modes the outputs of the session can be set to, so that the host can
+// reject a resolution the guest cannot display. The tools are optional, a missing one is not a warning.
+func getSupportedModes(ctx context.Context, displayServer string) []*api.ScreenMode {
+	var modes []*api.ScreenMode
+	switch displayServer {
+	case "X11":
+		if output := runModesProbe(ctx, "xrandr"); output != nil {
+			modes = parseXrandrModes(output)
+		}
+	case "Wayland":
+		if output := runModesProbe(ctx, "wlr-randr"); output != nil {
+			modes = parseWlrRandrModes(output)
+		} else if output := runModesProbe(ctx, "swaymsg", "-t", "get_outputs"); output != nil {
+			modes = parseSwayModes(output)
+		} else if output := runModesProbe(ctx, "kscreen-doctor", "-j"); output != nil {
+			modes = parseKscreenDoctorModes(output)
+		}
+	}
+	return sortModes(modes)
+}
+
+// runModesProbe runs a command listing the modes, logging failures instead of recording warnings
+func runModesProbe(ctx context.Context, name string, args ...string) []byte {
+	output, err := runner(ctx, 2*time.Second, name, args...)
+	if err != nil {
+		logrus.WithError(err).Debugf("GUI probe: cannot list the modes with %s", name)
+		return nil
+	}
+	return output
+}
+
+// parseXrandrModes returns the modes listed under the connected outputs of xrandr
+func parseXrandrModes(output []byte) []*api.ScreenMode {
+	var modes []*api.ScreenMode
+	connected := false
+	for line := range strings.Lines(string(output)) {
+		if !strings.HasPrefix(line, " ") {
+			fields := strings.Fields(line)
+			connected = len(fields) > 1 && fields[1] == "connected"
+			continue
+		}
+		if m := modeSize.FindStringSubmatch(line); connected && m != nil {
+			modes = append(modes, &api.ScreenMode{Width: atoi32(m[1]), Height: atoi32(m[2])})
+		}
+	}
+	return modes
+}
+
+// parseWlrRandrModes returns the modes listed in the "Modes:" section of each output of wlr-randr
+func parseWlrRandrModes(output []byte) []*api.ScreenMode {
+	var modes []*api.ScreenMode
+	for line := range strings.Lines(string(output)) {
+		// Only mode lines have a size with "px", the position is "x,y"
+		if m := modeSize.FindStringSubmatch(line); m != nil && strings.Contains(line, " px") {
+			modes = append(modes, &api.ScreenMode{Width: atoi32(m[1]), Height: atoi32(m[2])})
+		}
+	}
+	return modes
+}
+
+// parseSwayModes returns the modes of the outputs of `swaymsg -t get_outputs`
+func parseSwayModes(output []byte) []*api.ScreenMode {
+	var outputs []swayOutput
+	if err := json.Unmarshal(output, &outputs); err != nil {
+		logrus.WithError(err).Debug("GUI probe: swaymsg returned unparseable JSON")
+		return nil
+	}
+	var modes []*api.ScreenMode
+	for _, o := range outputs {
+		for _, m := range o.Modes {
+			modes = append(modes, &api.ScreenMode{Width: int32(m.Width), Height: int32(m.Height)})
+		}
+	}
+	return modes
+}
+
+// parseKscreenDoctorModes returns the modes of the connected outputs of `kscreen-doctor -j`
+func parseKscreenDoctorModes(output []byte) []*api.ScreenMode {
+	var doc struct {
+		Outputs []kscreenOutput `json:"outputs"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		logrus.WithError(err).Debug("GUI probe: kscreen-doctor returned unparseable JSON")
+		return nil
+	}
+	var modes []*api.ScreenMode
+	for _, o := range doc.Outputs {
+		if !o.Connected {
+			continue
+		}
+		for _, m := range o.Modes {
+			modes = append(modes, &api.ScreenMode{Width: int32(m.Size.Width), Height: int32(m.Size.Height)})
+		}
+	}
+	return modes
+}
+
+// sortModes sorts the modes largest first and drops the duplicates, e.g., the same size at several refresh rates
+func sortModes(modes []*api.ScreenMode) []*api.ScreenMode {
+	modes = slices.DeleteFunc(modes, func(m *api.ScreenMode) bool { return m.Width <= 0 || m.Height <= 0 })
+	slices.SortFunc(modes, func(a, b *api.ScreenMode) int {
+		return cmp.Or(cmp.Compare(b.Width, a.Width), cmp.Compare(b.Height, a.Height))
+	})
+	return slices.CompactFunc(modes, func(a, b *api.ScreenMode) bool {
+		return a.Width == b.Width && a.Height == b.Height
+	})
+}
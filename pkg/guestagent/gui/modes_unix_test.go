@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/testing/protocmp"
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+func TestGetSupportedModesXrandr(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"xrandr": `Screen 0: minimum 320 x 200, current 1920 x 1080, maximum 16384 x 16384
+Virtual-1 connected primary 1920x1080+0+0 0mm x 0mm
+   1920x1080     60.00*+  59.96
+   1280x800      59.81
+   1024x768      60.00
+Virtual-2 disconnected
+   640x480       59.94
+`,
+	})
+	assert.DeepEqual(t, []*api.ScreenMode{
+		{Width: 1920, Height: 1080},
+		{Width: 1280, Height: 800},
+		{Width: 1024, Height: 768},
+	}, getSupportedModes(t.Context(), "X11"), protocmp.Transform())
+}
+
+func TestGetSupportedModesWayland(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"wlr-randr": `Virtual-1 "Red Hat, Inc. QEMU Monitor (Virtual-1)"
+  Enabled: yes
+  Modes:
+    1024x768 px, 60.000000 Hz
+    1920x1080 px, 60.000000 Hz (preferred, current)
+    1920x1080 px, 50.000000 Hz
+  Position: 0,0
+  Scale: 1.000000
+`,
+	})
+	assert.DeepEqual(t, []*api.ScreenMode{
+		{Width: 1920, Height: 1080},
+		{Width: 1024, Height: 768},
+	}, getSupportedModes(t.Context(), "Wayland"), protocmp.Transform())
+
+	// Without wlr-randr, sway lists the modes of its outputs
+	fakeRunner(t, map[string]string{
+		"swaymsg -t get_outputs": `[{"name":"Virtual-1","active":true,"modes":[{"width":1280,"height":800,"refresh":60000},{"width":2560,"height":1600,"refresh":60000}]}]`,
+	})
+	assert.DeepEqual(t, []*api.ScreenMode{
+		{Width: 2560, Height: 1600},
+		{Width: 1280, Height: 800},
+	}, getSupportedModes(t.Context(), "Wayland"), protocmp.Transform())
+
+	// No tool is not a warning
+	fakeRunner(t, map[string]string{})
+	ctx, warnings := withWarnings(t.Context())
+	assert.Assert(t, getSupportedModes(ctx, "Wayland") == nil)
+	assert.Equal(t, 0, len(*warnings))
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// screenshotTimeout bounds the screenshot tools, which can take a while on large displays
+const screenshotTimeout = 15 * time.Second
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// screenshotTool is a command that writes a PNG screenshot of the whole session to its standard output
+type screenshotTool struct {
+	args []string
+	// pkg is the package to install for the command
+	pkg string
+}
+
+// screenshotTools returns the screenshot tools of the session, in order of preference:
+// grim on Wayland, scrot or ImageMagick import on X11
+func screenshotTools(ctx context.Context) []screenshotTool {
+	if detectWayland(ctx) {
+		// grim uses the wlroots screencopy protocol, GNOME and KDE do not implement it
+		return []screenshotTool{{args: []string{"grim", "-t", "png", "-"}, pkg: "grim"}}
+	}
+	return []screenshotTool{
+		{args: []string{"scrot", "-"}, pkg: "scrot"},
+		{args: []string{"import", "-window", "root", "png:-"}, pkg: "ImageMagick"},
+	}
+}
+
+// Screenshot returns a PNG screenshot of the session, taken inside the guest.
+// It is used when the driver cannot take a screenshot of the display of the VM.
+func Screenshot(ctx context.Context) ([]byte, error) {
+	var missing []string
+	for _, tool := range screenshotTools(ctx) {
+		name := tool.args[0]
+		output, err := runner(ctx, screenshotTimeout, name, tool.args[1:]...)
+		if errors.Is(err, exec.ErrNotFound) {
+			missing = append(missing, tool.pkg)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.HasPrefix(output, pngSignature) {
+			return nil, fmt.Errorf("%s did not write a PNG image", name)
+		}
+		return output, nil
+	}
+	return nil, fmt.Errorf("no screenshot tool is installed in the guest, install %s", strings.Join(missing, " or "))
+}
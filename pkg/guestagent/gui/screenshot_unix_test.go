@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestScreenshotX11FallsBackToImport(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	png := string(pngSignature) + "image data"
+	fakeRunner(t, map[string]string{
+		"import -window root png:-": png,
+	})
+	data, err := Screenshot(t.Context())
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), png)
+}
+
+func TestScreenshotNotPNG(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-1")
+	fakeRunner(t, map[string]string{
+		"grim -t png -": "",
+	})
+	_, err := Screenshot(t.Context())
+	assert.ErrorContains(t, err, "grim did not write a PNG image")
+}
+
+func TestScreenshotNotInstalled(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	fakeRunner(t, nil)
+	_, err := Screenshot(t.Context())
+	assert.ErrorContains(t, err, "no screenshot tool is installed in the guest, install scrot or ImageMagick")
+}
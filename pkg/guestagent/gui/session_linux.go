@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionEnvVars are the variables taken from the environment of the graphical session of another user.
+// HOME is left out, so that the probes never write as root into the home directory of the user.
+var sessionEnvVars = []string{
+	"DISPLAY", "WAYLAND_DISPLAY", "XAUTHORITY", "XDG_RUNTIME_DIR", "XDG_SESSION_TYPE",
+	"DBUS_SESSION_BUS_ADDRESS", "AT_SPI_BUS_ADDRESS", "SWAYSOCK",
+}
+
+// procDir is replaced in tests
+var procDir = "/proc"
+
+// graphicalSession is a graphical login session, as listed by loginctl
+type graphicalSession struct {
+	ID     string
+	User   string
+	Leader string // PID of the session leader
+	Type   string // "x11" or "wayland"
+	Class  string // "user", or "greeter" for the login screen
+	Active bool
+}
+
+// rank orders the sessions to probe: user sessions before greeters, then active sessions first
+func (s *graphicalSession) rank() int {
+	r := 0
+	if s.Class == "user" {
+		r += 2
+	}
+	if s.Active {
+		r++
+	}
+	return r
+}
+
+// withGraphicalSession returns a context for probing the graphical session, and the owner of the session.
+// The guest agent usually runs as root, without the DISPLAY and WAYLAND_DISPLAY of the session of the user;
+// their values are then read from the processes of the session found with loginctl.
+func withGraphicalSession(ctx context.Context) (context.Context, string) {
+	if os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		if u, err := user.Current(); err == nil {
+			return ctx, u.Username
+		}
+		return ctx, ""
+	}
+	if os.Geteuid() != 0 {
+		// The processes of the other users cannot be inspected
+		return ctx, ""
+	}
+	session := findGraphicalSession(ctx)
+	if session == nil {
+		return ctx, ""
+	}
+	env := sessionEnviron(session)
+	if env == nil {
+		addWarning(ctx, "cannot find the environment of the %s session of %s", session.Type, session.User)
+		return ctx, session.User
+	}
+	return withSessionEnv(ctx, env), session.User
+}
+
+// WithGraphicalSession returns a context for running commands in the graphical session of the logged in user,
+// as DetectGUIInfo does, for the requests that act on the session instead of probing it
+func WithGraphicalSession(ctx context.Context) context.Context {
+	ctx, _ = withGraphicalSession(ctx)
+	return ctx
+}
+
+// findGraphicalSession returns the X11 or Wayland session to probe, or nil
+func findGraphicalSession(ctx context.Context) *graphicalSession {
+	output := runProbe(ctx, 2*time.Second, "loginctl", "list-sessions", "--no-legend")
+	if output == nil {
+		return nil
+	}
+	var best *graphicalSession
+	for line := range strings.Lines(string(output)) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		props := runProbe(ctx, 2*time.Second, "loginctl", "show-session", fields[0],
+			"-p", "Name", "-p", "Leader", "-p", "Type", "-p", "Class", "-p", "Active")
+		s := parseSessionProperties(fields[0], props)
+		if s.Type != "x11" && s.Type != "wayland" {
+			continue
+		}
+		if best == nil || s.rank() > best.rank() {
+			best = s
+		}
+	}
+	return best
+}
+
+// parseSessionProperties parses the "Key=value" lines printed by `loginctl show-session`
+func parseSessionProperties(id string, output []byte) *graphicalSession {
+	s := &graphicalSession{ID: id}
+	for line := range strings.Lines(string(output)) {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "Name":
+			s.User = v
+		case "Leader":
+			s.Leader = v
+		case "Type":
+			s.Type = v
+		case "Class":
+			s.Class = v
+		case "Active":
+			s.Active = v == "yes"
+		}
+	}
+	return s
+}
+
+// sessionEnviron returns the session variables of the graphical session, or nil if not found.
+// Display managers start the session leader before the display server, so its environment
+// often lacks the display; the other processes of the session are searched then.
+func sessionEnviron(s *graphicalSession) []string {
+	if env := readEnviron(s.Leader); hasDisplayEnv(env) {
+		return filterSessionEnv(env)
+	}
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil || entry.Name() == s.Leader {
+			continue
+		}
+		env := readEnviron(entry.Name())
+		if slices.Contains(env, "XDG_SESSION_ID="+s.ID) && hasDisplayEnv(env) {
+			return filterSessionEnv(env)
+		}
+	}
+	return nil
+}
+
+// readEnviron reads the environment of a process, or returns nil
+func readEnviron(pid string) []string {
+	if pid == "" {
+		return nil
+	}
+	b, err := os.ReadFile(filepath.Join(procDir, pid, "environ"))
+	if err != nil {
+		return nil
+	}
+	var env []string
+	for kv := range bytes.SplitSeq(b, []byte{0}) {
+		if len(kv) > 0 {
+			env = append(env, string(kv))
+		}
+	}
+	return env
+}
+
+// hasDisplayEnv checks if the environment names an X11 or Wayland display
+func hasDisplayEnv(env []string) bool {
+	return slices.ContainsFunc(env, func(kv string) bool {
+		return strings.HasPrefix(kv, "DISPLAY=") || strings.HasPrefix(kv, "WAYLAND_DISPLAY=")
+	})
+}
+
+// filterSessionEnv keeps the sessionEnvVars of the environment
+func filterSessionEnv(env []string) []string {
+	var filtered []string
+	for _, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && slices.Contains(sessionEnvVars, k) {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFindGraphicalSession(t *testing.T) {
+	fakeRunner(t, map[string]string{
+		"loginctl list-sessions --no-legend": "c1 120 gdm seat0 1100 greeter tty1 no -\n" +
+			"2 1000 alice seat0 1234 user tty2 no -\n" +
+			"3 1001 bob - 2345 user - no -\n",
+		"loginctl show-session c1 -p Name -p Leader -p Type -p Class -p Active": "Name=gdm\nLeader=1100\nType=wayland\nClass=greeter\nActive=no\n",
+		"loginctl show-session 2 -p Name -p Leader -p Type -p Class -p Active":  "Name=alice\nLeader=1234\nType=wayland\nClass=user\nActive=yes\n",
+		"loginctl show-session 3 -p Name -p Leader -p Type -p Class -p Active":  "Name=bob\nLeader=2345\nType=tty\nClass=user\nActive=no\n",
+	})
+	session := findGraphicalSession(t.Context())
+	assert.DeepEqual(t, session, &graphicalSession{ID: "2", User: "alice", Leader: "1234", Type: "wayland", Class: "user", Active: true})
+
+	// Without systemd
+	fakeRunner(t, nil)
+	assert.Assert(t, findGraphicalSession(t.Context()) == nil)
+}
+
+func TestSessionEnviron(t *testing.T) {
+	procDir = t.TempDir()
+	t.Cleanup(func() { procDir = "/proc" })
+	writeEnviron := func(pid string, env ...string) {
+		assert.NilError(t, os.MkdirAll(filepath.Join(procDir, pid), 0o755))
+		assert.NilError(t, os.WriteFile(filepath.Join(procDir, pid, "environ"), []byte(strings.Join(env, "\x00")+"\x00"), 0o644))
+	}
+	// The session leader was started before the display server
+	writeEnviron("1234", "HOME=/home/alice", "XDG_SESSION_ID=2")
+	writeEnviron("1300", "HOME=/home/bob", "XDG_SESSION_ID=3", "DISPLAY=:1")
+	writeEnviron("1301", "HOME=/home/alice", "XDG_SESSION_ID=2", "WAYLAND_DISPLAY=wayland-0",
+		"XDG_RUNTIME_DIR=/run/user/1000", "DISPLAY=:0")
+
+	env := sessionEnviron(&graphicalSession{ID: "2", Leader: "1234"})
+	assert.DeepEqual(t, env, []string{"WAYLAND_DISPLAY=wayland-0", "XDG_RUNTIME_DIR=/run/user/1000", "DISPLAY=:0"})
+
+	ctx := withSessionEnv(t.Context(), env)
+	assert.Equal(t, getenv(ctx, "WAYLAND_DISPLAY"), "wayland-0")
+	assert.Equal(t, getenv(ctx, "HOME"), "")
+	assert.Assert(t, detectWayland(ctx))
+
+	assert.Assert(t, sessionEnviron(&graphicalSession{ID: "4", Leader: "4000"}) == nil)
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/spiceservice"
+)
+
+// SpiceAgentRetry limits the automatic installation of the SPICE agent by DetectGUIInfo,
+// which would otherwise run the package manager on every poll while the clipboard is not ready.
+type SpiceAgentRetry struct {
+	Interval    time.Duration // Minimum delay after a failed attempt before the next one
+	MaxFailures int           // Consecutive failures after which no more attempts are made, 0 for no limit
+}
+
+// DefaultSpiceAgentRetry is used until SetSpiceAgentRetry is called
+var DefaultSpiceAgentRetry = SpiceAgentRetry{
+	Interval:    5 * time.Minute,
+	MaxFailures: 5,
+}
+
+// SpiceAgentAutoEnableEnv is the environment variable of the guest agent that turns off the
+// SPICE agent auto-enable when set to a false value, e.g., "0" or "false"
+const SpiceAgentAutoEnableEnv = "LIMA_AUTO_ENABLE_SPICE"
+
+// spiceAgentAttempts tracks the failed attempts to enable the SPICE agent
+var spiceAgentAttempts = struct {
+	sync.Mutex
+	disabled    bool
+	retry       SpiceAgentRetry
+	failures    int
+	lastFailure time.Time
+	lastErr     error
+}{retry: DefaultSpiceAgentRetry}
+
+// ensureSpiceAgentFunc is replaced in tests
+var ensureSpiceAgentFunc = spiceservice.EnsureSpiceAgent
+
+// SetSpiceAgentRetry sets the retry policy of the SPICE agent auto-enable, and forgets the previous failures.
+func SetSpiceAgentRetry(retry SpiceAgentRetry) {
+	spiceAgentAttempts.Lock()
+	defer spiceAgentAttempts.Unlock()
+	spiceAgentAttempts.retry = retry
+	spiceAgentAttempts.failures = 0
+	spiceAgentAttempts.lastErr = nil
+}
+
+// SetSpiceAgentAutoEnable sets whether DetectGUIInfo installs and starts the SPICE agent when the
+// clipboard is not ready. When disabled, DetectGUIInfo only reports the status of the agent.
+func SetSpiceAgentAutoEnable(enabled bool) {
+	spiceAgentAttempts.Lock()
+	defer spiceAgentAttempts.Unlock()
+	spiceAgentAttempts.disabled = !enabled
+}
+
+// ensureSpiceAgent enables the SPICE agent, unless it is disabled or the retry policy holds back another attempt.
+// skipped explains why no attempt was made; err is the result of the attempt otherwise.
+func ensureSpiceAgent(ctx context.Context, now time.Time) (skipped string, err error) {
+	a := &spiceAgentAttempts
+	a.Lock()
+	defer a.Unlock()
+	if a.disabled {
+		return "SPICE agent auto-enable is disabled, install and start spice-vdagent in the guest", nil
+	}
+	if a.failures > 0 {
+		if a.retry.MaxFailures > 0 && a.failures >= a.retry.MaxFailures {
+			return fmt.Sprintf("SPICE agent auto-enable stopped after %d consecutive failures: %v", a.failures, a.lastErr), nil
+		}
+		if next := a.lastFailure.Add(a.retry.Interval); now.Before(next) {
+			return fmt.Sprintf("failed to auto-enable SPICE agent, retrying in %v: %v", next.Sub(now).Round(time.Second), a.lastErr), nil
+		}
+	}
+
+	logrus.Info("SPICE virtio port detected, attempting to enable clipboard sharing...")
+	if err := ensureSpiceAgentFunc(ctx); err != nil {
+		a.failures++
+		a.lastFailure = now
+		a.lastErr = err
+		return "", err
+	}
+	a.failures = 0
+	a.lastErr = nil
+	return "", nil
+}
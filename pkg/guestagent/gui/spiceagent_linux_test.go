@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEnsureSpiceAgentRetry(t *testing.T) {
+	orig := ensureSpiceAgentFunc
+	t.Cleanup(func() {
+		ensureSpiceAgentFunc = orig
+		SetSpiceAgentRetry(DefaultSpiceAgentRetry)
+	})
+	calls := 0
+	ensureErr := errors.New("apt-get failed")
+	ensureSpiceAgentFunc = func(context.Context) error {
+		calls++
+		return ensureErr
+	}
+	SetSpiceAgentRetry(SpiceAgentRetry{Interval: time.Minute, MaxFailures: 2})
+
+	now := time.Now()
+	skipped, err := ensureSpiceAgent(t.Context(), now)
+	assert.Equal(t, skipped, "")
+	assert.ErrorIs(t, err, ensureErr)
+
+	// Held back until the interval has passed
+	skipped, err = ensureSpiceAgent(t.Context(), now.Add(30*time.Second))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(skipped, "retrying in 30s"), skipped)
+	assert.Equal(t, calls, 1)
+
+	_, err = ensureSpiceAgent(t.Context(), now.Add(time.Minute))
+	assert.ErrorIs(t, err, ensureErr)
+	assert.Equal(t, calls, 2)
+
+	// Given up after MaxFailures
+	skipped, _ = ensureSpiceAgent(t.Context(), now.Add(time.Hour))
+	assert.Assert(t, strings.Contains(skipped, "stopped after 2 consecutive failures: apt-get failed"), skipped)
+	assert.Equal(t, calls, 2)
+
+	// A success resets the failures
+	SetSpiceAgentRetry(SpiceAgentRetry{Interval: time.Minute})
+	ensureSpiceAgentFunc = func(context.Context) error { return nil }
+	skipped, err = ensureSpiceAgent(t.Context(), now)
+	assert.Equal(t, skipped, "")
+	assert.NilError(t, err)
+}
+
+func TestEnsureSpiceAgentDisabled(t *testing.T) {
+	orig := ensureSpiceAgentFunc
+	t.Cleanup(func() {
+		ensureSpiceAgentFunc = orig
+		SetSpiceAgentAutoEnable(true)
+	})
+	calls := 0
+	ensureSpiceAgentFunc = func(context.Context) error {
+		calls++
+		return nil
+	}
+
+	SetSpiceAgentAutoEnable(false)
+	skipped, err := ensureSpiceAgent(t.Context(), time.Now())
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(skipped, "auto-enable is disabled"), skipped)
+	assert.Equal(t, calls, 0)
+
+	SetSpiceAgentAutoEnable(true)
+	skipped, err = ensureSpiceAgent(t.Context(), time.Now())
+	assert.NilError(t, err)
+	assert.Equal(t, skipped, "")
+	assert.Equal(t, calls, 1)
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// portalColorSchemeRegexp matches the value of org.freedesktop.appearance color-scheme in the reply of dbus-send,
+// e.g., "   variant       variant          uint32 1"
+var portalColorSchemeRegexp = regexp.MustCompile(`uint32 (\d)`)
+
+// getColorScheme returns the color scheme of the session: "dark", "light", or "unknown".
+// No preference is reported as "light", which is what GTK and Qt applications then use.
+func getColorScheme(ctx context.Context) string {
+	// The settings portal answers for every desktop, from the settings of the session user.
+	// gsettings reads the dconf database of the user running it, so it is only a fallback
+	// for a guest agent running in the session.
+	// Both are expected to fail on some desktops, so only the last probe reports a warning.
+	output, _ := runner(ctx, 2*time.Second, "dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.portal.Desktop",
+		"/org/freedesktop/portal/desktop", "org.freedesktop.portal.Settings.Read",
+		"string:org.freedesktop.appearance", "string:color-scheme")
+	if m := portalColorSchemeRegexp.FindSubmatch(output); m != nil {
+		// 0: no preference, 1: prefer dark, 2: prefer light
+		if string(m[1]) == "1" {
+			return "dark"
+		}
+		return "light"
+	}
+
+	// GNOME 42 and later
+	if output, err := runner(ctx, 2*time.Second, "gsettings", "get", "org.gnome.desktop.interface", "color-scheme"); err == nil {
+		if strings.Trim(strings.TrimSpace(string(output)), "'") == "prefer-dark" {
+			return "dark"
+		}
+		return "light"
+	}
+	// Older GNOME only has dark variants of the themes, e.g., "Adwaita-dark"
+	if output := runProbe(ctx, 2*time.Second, "gsettings", "get", "org.gnome.desktop.interface", "gtk-theme"); output != nil {
+		if strings.HasSuffix(strings.ToLower(strings.Trim(strings.TrimSpace(string(output)), "'")), "-dark") {
+			return "dark"
+		}
+		return "light"
+	}
+	return "unknown"
+}
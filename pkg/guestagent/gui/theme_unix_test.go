@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const portalColorSchemeCommand = "dbus-send --session --print-reply --dest=org.freedesktop.portal.Desktop /org/freedesktop/portal/desktop " +
+	"org.freedesktop.portal.Settings.Read string:org.freedesktop.appearance string:color-scheme"
+
+func TestGetColorScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs map[string]string
+		want    string
+	}{
+		{
+			name: "portal prefers dark",
+			outputs: map[string]string{
+				portalColorSchemeCommand: "method return time=1700000000.1 sender=:1.20 -> destination=:1.90 serial=30 reply_serial=2\n" +
+					"   variant       variant          uint32 1\n",
+				"gsettings get org.gnome.desktop.interface color-scheme": "'default'\n",
+			},
+			want: "dark",
+		},
+		{
+			name: "portal without preference",
+			outputs: map[string]string{
+				portalColorSchemeCommand: "   variant       variant          uint32 0\n",
+			},
+			want: "light",
+		},
+		{
+			name: "gsettings color-scheme",
+			outputs: map[string]string{
+				"gsettings get org.gnome.desktop.interface color-scheme": "'prefer-dark'\n",
+			},
+			want: "dark",
+		},
+		{
+			name: "gsettings gtk-theme of older GNOME",
+			outputs: map[string]string{
+				"gsettings get org.gnome.desktop.interface gtk-theme": "'Adwaita-dark'\n",
+			},
+			want: "dark",
+		},
+		{
+			name: "nothing to ask",
+			want: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRunner(t, tt.outputs)
+			assert.Equal(t, getColorScheme(t.Context()), tt.want)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// DiffGUIInfo returns the changes from prev to cur of the fields reported by WatchGUIInfo.
+// The idle time is only reported when it crosses idleThreshold, as "active" or "idle".
+func DiffGUIInfo(prev, cur *api.GUIInfo, idleThreshold time.Duration) []*api.GUIFieldChange {
+	var changes []*api.GUIFieldChange
+	diff := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &api.GUIFieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	diff("display_server", prev.DisplayServer, cur.DisplayServer)
+	diff("session_active", strconv.FormatBool(prev.SessionActive), strconv.FormatBool(cur.SessionActive))
+	diff("resolution", prev.Resolution, cur.Resolution)
+	diff("clipboard_ready", strconv.FormatBool(prev.GetSpice().GetClipboardReady()), strconv.FormatBool(cur.GetSpice().GetClipboardReady()))
+	diff("idle", idleState(prev, idleThreshold), idleState(cur, idleThreshold))
+	return changes
+}
+
+// idleState is "idle" when the session has been idle for at least the threshold, "active" otherwise
+func idleState(info *api.GUIInfo, idleThreshold time.Duration) string {
+	if info.SessionActive && time.Duration(info.IdleTimeMs)*time.Millisecond >= idleThreshold {
+		return "idle"
+	}
+	return "active"
+}
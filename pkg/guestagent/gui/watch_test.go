@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gui
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+func TestDiffGUIInfo(t *testing.T) {
+	prev := &api.GUIInfo{
+		DisplayServer: "X11",
+		SessionActive: true,
+		Resolution:    "1024x768",
+		IdleTimeMs:    1000,
+	}
+	cur := &api.GUIInfo{
+		DisplayServer: "X11",
+		SessionActive: true,
+		Resolution:    "1920x1200",
+		IdleTimeMs:    90000,
+		Spice:         &api.SpiceAgentInfo{ClipboardReady: true},
+	}
+	changes := DiffGUIInfo(prev, cur, time.Minute)
+	assert.Equal(t, len(changes), 3)
+	assert.DeepEqual(t, [][3]string{
+		{changes[0].Field, changes[0].OldValue, changes[0].NewValue},
+		{changes[1].Field, changes[1].OldValue, changes[1].NewValue},
+		{changes[2].Field, changes[2].OldValue, changes[2].NewValue},
+	}, [][3]string{
+		{"resolution", "1024x768", "1920x1200"},
+		{"clipboard_ready", "false", "true"},
+		{"idle", "active", "idle"},
+	})
+
+	// The idle time changes on every probe, only crossing the threshold is a change
+	prev.IdleTimeMs, cur.IdleTimeMs = 1000, 2000
+	prev.Resolution, prev.Spice = cur.Resolution, cur.Spice
+	assert.Equal(t, len(DiffGUIInfo(prev, cur, time.Minute)), 0)
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// ListWindows returns the top-level windows of the session: from the sway tree on Wayland,
+// and from the window manager with wmctrl on X11
+func ListWindows(ctx context.Context) ([]*api.Window, error) {
+	name, args := "wmctrl", []string{"-l", "-G", "-p", "-x"}
+	parse := parseWmctrlWindows
+	if detectWayland(ctx) {
+		// Other compositors do not list the windows of their clients
+		name, args = "swaymsg", []string{"-t", "get_tree", "--raw"}
+		parse = parseSwayTree
+	}
+	output, err := runner(ctx, 5*time.Second, name, args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%s is not installed in the guest", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(output)
+}
+
+// parseWmctrlWindows parses `wmctrl -l -G -p -x`, one window per line:
+// "ID DESKTOP PID X Y WIDTH HEIGHT WM_CLASS HOST TITLE...".
+func parseWmctrlWindows(output []byte) ([]*api.Window, error) {
+	var windows []*api.Window
+	for line := range strings.Lines(string(output)) {
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			continue
+		}
+		var geom [5]int
+		for i := range geom {
+			v, err := strconv.Atoi(fields[2+i])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected wmctrl output %q", strings.TrimSpace(line))
+			}
+			geom[i] = v
+		}
+		w := &api.Window{
+			AppId:  fields[7],
+			Pid:    int32(geom[0]),
+			X:      int32(geom[1]),
+			Y:      int32(geom[2]),
+			Width:  int32(geom[3]),
+			Height: int32(geom[4]),
+		}
+		// -1 is a sticky window, shown on every desktop
+		if fields[1] != "-1" {
+			w.Workspace = fields[1]
+		}
+		if len(fields) > 9 {
+			w.Title = strings.Join(fields[9:], " ")
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// swayNode is the subset of a node of the swaymsg get_tree output used for listing windows
+type swayNode struct {
+	Type             string `json:"type"`
+	Name             string `json:"name"`
+	AppID            string `json:"app_id"`
+	PID              int    `json:"pid"`
+	WindowProperties *struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+	Rect struct {
+		X      int `json:"x"`
+		Y      int `json:"y"`
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"rect"`
+	Nodes         []*swayNode `json:"nodes"`
+	FloatingNodes []*swayNode `json:"floating_nodes"`
+}
+
+// parseSwayTree returns the views of the sway tree; XWayland views have a class instead of an app id
+func parseSwayTree(output []byte) ([]*api.Window, error) {
+	var root swayNode
+	if err := json.Unmarshal(output, &root); err != nil {
+		return nil, fmt.Errorf("swaymsg returned unparseable JSON: %w", err)
+	}
+	var windows []*api.Window
+	var walk func(n *swayNode, workspace string)
+	walk = func(n *swayNode, workspace string) {
+		if n.Type == "workspace" {
+			workspace = n.Name
+		}
+		if n.PID != 0 && (n.Type == "con" || n.Type == "floating_con") {
+			w := &api.Window{
+				Title:     n.Name,
+				AppId:     n.AppID,
+				Pid:       int32(n.PID),
+				X:         int32(n.Rect.X),
+				Y:         int32(n.Rect.Y),
+				Width:     int32(n.Rect.Width),
+				Height:    int32(n.Rect.Height),
+				Workspace: workspace,
+			}
+			if w.AppId == "" && n.WindowProperties != nil {
+				w.AppId = n.WindowProperties.Class
+			}
+			windows = append(windows, w)
+		}
+		for _, c := range n.Nodes {
+			walk(c, workspace)
+		}
+		for _, c := range n.FloatingNodes {
+			walk(c, workspace)
+		}
+	}
+	walk(&root, "")
+	return windows, nil
+}
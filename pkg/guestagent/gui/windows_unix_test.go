@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestListWindowsX11(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	fakeRunner(t, map[string]string{
+		"wmctrl -l -G -p -x": `0x01800003 -1 1021   0    0    1920 32   xfce4-panel.Xfce4-panel  lima-vm xfce4-panel
+0x03a00004  0 2345   100  80   800  600  gnome-terminal-server.Gnome-terminal  lima-vm user@lima-vm: ~/src
+0x04000001  1 2400   0    0    640  480  xeyes.XEyes  lima-vm
+`,
+	})
+	windows, err := ListWindows(t.Context())
+	assert.NilError(t, err)
+	assert.Equal(t, len(windows), 3)
+	assert.Equal(t, windows[0].Workspace, "")
+	w := windows[1]
+	assert.Equal(t, w.Title, "user@lima-vm: ~/src")
+	assert.Equal(t, w.AppId, "gnome-terminal-server.Gnome-terminal")
+	assert.Equal(t, w.Pid, int32(2345))
+	assert.Equal(t, w.Workspace, "0")
+	assert.DeepEqual(t, []int32{w.X, w.Y, w.Width, w.Height}, []int32{100, 80, 800, 600})
+	assert.Equal(t, windows[2].Title, "")
+}
+
+func TestListWindowsSway(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-1")
+	fakeRunner(t, map[string]string{
+		"swaymsg -t get_tree --raw": `{"type": "root", "name": "root", "rect": {"x": 0, "y": 0, "width": 1920, "height": 1080}, "nodes": [
+  {"type": "output", "name": "Virtual-1", "nodes": [
+    {"type": "workspace", "name": "1", "nodes": [
+      {"type": "con", "name": "foot", "app_id": "foot", "pid": 812, "rect": {"x": 0, "y": 0, "width": 960, "height": 1080}, "nodes": []},
+      {"type": "con", "name": null, "nodes": [
+        {"type": "con", "name": "xterm", "app_id": null, "pid": 900, "window_properties": {"class": "XTerm"},
+         "rect": {"x": 960, "y": 0, "width": 960, "height": 1080}, "nodes": []}
+      ]}
+    ], "floating_nodes": [
+      {"type": "floating_con", "name": "Calculator", "app_id": "org.gnome.Calculator", "pid": 950,
+       "rect": {"x": 600, "y": 300, "width": 360, "height": 480}, "nodes": []}
+    ]}
+  ]}
+]}`,
+	})
+	windows, err := ListWindows(t.Context())
+	assert.NilError(t, err)
+	assert.Equal(t, len(windows), 3)
+	assert.Equal(t, windows[0].AppId, "foot")
+	assert.Equal(t, windows[1].AppId, "XTerm")
+	assert.Equal(t, windows[1].X, int32(960))
+	assert.Equal(t, windows[2].Title, "Calculator")
+	assert.Equal(t, windows[2].Workspace, "1")
+}
+
+func TestListWindowsNotInstalled(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "x11")
+	fakeRunner(t, nil)
+	_, err := ListWindows(t.Context())
+	assert.ErrorContains(t, err, "wmctrl is not installed in the guest")
+}
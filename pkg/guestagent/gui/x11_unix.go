@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minimal X11 protocol client, for the queries that no common command-line tool exposes.
+// See https://www.x.org/releases/X11R7.7/doc/xproto/x11protocol.html
+
+const (
+	x11OpInternAtom        = 16
+	x11OpGetSelectionOwner = 23
+	x11CookieName          = "MIT-MAGIC-COOKIE-1"
+	x11Timeout             = 2 * time.Second
+)
+
+// x11SocketDir holds the sockets of the local X11 displays; replaced in tests
+var x11SocketDir = "/tmp/.X11-unix"
+
+// x11Display splits a local display name such as ":0" or ":0.1" into its display and screen numbers
+func x11Display(display string) (num, screen string, err error) {
+	rest, ok := strings.CutPrefix(display, ":")
+	if !ok {
+		return "", "", fmt.Errorf("only local X11 displays are supported, got %q", display)
+	}
+	num, screen, _ = strings.Cut(rest, ".")
+	if screen == "" {
+		screen = "0"
+	}
+	if _, err := strconv.Atoi(num); err != nil {
+		return "", "", fmt.Errorf("invalid X11 display %q", display)
+	}
+	return num, screen, nil
+}
+
+// x11SelectionOwned reports whether the selection (e.g., "_NET_WM_CM_S0") has an owner on the display
+func x11SelectionOwned(ctx context.Context, display, selection string) (bool, error) {
+	num, _, err := x11Display(display)
+	if err != nil {
+		return false, err
+	}
+	cookie, err := x11Cookie(ctx, num)
+	if err != nil {
+		return false, err
+	}
+	return x11QuerySelectionOwned(filepath.Join(x11SocketDir, "X"+num), cookie, selection)
+}
+
+// x11QuerySelectionOwned connects to the X server socket and checks the owner of the selection
+func x11QuerySelectionOwned(socketPath string, cookie []byte, selection string) (bool, error) {
+	conn, err := net.DialTimeout("unix", socketPath, x11Timeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(x11Timeout)); err != nil {
+		return false, err
+	}
+	if _, err := x11Setup(conn, cookie); err != nil {
+		return false, err
+	}
+	owner, err := x11GetSelectionOwner(conn, selection)
+	if err != nil {
+		return false, err
+	}
+	return owner != 0, nil
+}
+
+// x11GetSelectionOwner returns the window owning the selection, or 0 if it has no owner
+func x11GetSelectionOwner(conn io.ReadWriter, selection string) (uint32, error) {
+	// No atom means nobody ever owned the selection
+	atom, err := x11InternAtom(conn, selection)
+	if err != nil || atom == 0 {
+		return 0, err
+	}
+
+	req := make([]byte, 8)
+	req[0] = x11OpGetSelectionOwner
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], atom)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("GetSelectionOwner %s: %w", selection, err)
+	}
+	return binary.LittleEndian.Uint32(reply[8:]), nil
+}
+
+// x11InternAtom returns the atom of the name with InternAtom only-if-exists, or 0 if it does not exist
+func x11InternAtom(conn io.ReadWriter, name string) (uint32, error) {
+	padded := x11Pad([]byte(name))
+	req := make([]byte, 8, 8+len(padded))
+	req[0] = x11OpInternAtom
+	req[1] = 1
+	binary.LittleEndian.PutUint16(req[2:], uint16((8+len(padded))/4))
+	binary.LittleEndian.PutUint16(req[4:], uint16(len(name)))
+	req = append(req, padded...)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("InternAtom %s: %w", name, err)
+	}
+	return binary.LittleEndian.Uint32(reply[8:]), nil
+}
+
+// x11Setup sends the connection setup and checks that the server accepted it.
+// It returns the setup data that follows the 8-byte reply header.
+func x11Setup(conn io.ReadWriter, cookie []byte) ([]byte, error) {
+	var authName, authData []byte
+	if cookie != nil {
+		authName, authData = []byte(x11CookieName), cookie
+	}
+	req := make([]byte, 12)
+	req[0] = 'l' // little endian
+	binary.LittleEndian.PutUint16(req[2:], 11)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(authName)))
+	binary.LittleEndian.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, x11Pad(authName)...)
+	req = append(req, x11Pad(authData)...)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return nil, fmt.Errorf("failed to read X11 setup reply: %w", err)
+	}
+	body := make([]byte, 4*int(binary.LittleEndian.Uint16(head[6:])))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, fmt.Errorf("failed to read X11 setup reply: %w", err)
+	}
+	if head[0] != 1 {
+		reason := body
+		if head[0] == 0 {
+			reason = body[:min(int(head[1]), len(body))]
+		}
+		return nil, fmt.Errorf("X11 connection refused: %s", strings.TrimSpace(string(bytes.TrimRight(reason, "\x00"))))
+	}
+	return body, nil
+}
+
+// x11Request sends a request and reads its reply: 32 bytes followed by any additional reply data
+func x11Request(conn io.ReadWriter, req []byte) ([]byte, error) {
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 32)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	if reply[0] == 0 {
+		return nil, fmt.Errorf("X11 error code %d", reply[1])
+	}
+	if extra := binary.LittleEndian.Uint32(reply[4:]); extra > 0 {
+		reply = append(reply, make([]byte, 4*int(extra))...)
+		if _, err := io.ReadFull(conn, reply[32:]); err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// x11Pad pads b to a multiple of 4 bytes
+func x11Pad(b []byte) []byte {
+	return append(b, make([]byte, (4-len(b)%4)%4)...)
+}
+
+// x11Cookie returns the MIT-MAGIC-COOKIE-1 for the display number from the Xauthority file, or nil
+func x11Cookie(ctx context.Context, num string) ([]byte, error) {
+	path := getenv(ctx, "XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parseXauthority(f, num)
+}
+
+// parseXauthority finds the cookie for the display number in an Xauthority file.
+// Each entry is a big-endian family followed by the counted address, number, name and data.
+func parseXauthority(r io.Reader, num string) ([]byte, error) {
+	br := bufio.NewReader(r)
+	readCounted := func() ([]byte, error) {
+		var n uint16
+		if err := binary.Read(br, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		_, err := io.ReadFull(br, b)
+		return b, err
+	}
+	for {
+		var family uint16
+		if err := binary.Read(br, binary.BigEndian, &family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		var fields [4][]byte
+		for i := range fields {
+			b, err := readCounted()
+			if err != nil {
+				return nil, fmt.Errorf("malformed Xauthority entry: %w", err)
+			}
+			fields[i] = b
+		}
+		number, name, data := string(fields[1]), string(fields[2]), fields[3]
+		if (number == num || number == "") && name == x11CookieName {
+			return data, nil
+		}
+	}
+}
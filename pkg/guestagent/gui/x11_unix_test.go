@@ -0,0 +1,365 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/testing/protocmp"
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// fakeX11 configures the replies of the server started by startFakeX11
+type fakeX11 struct {
+	atom, owner   uint32 // InternAtom and GetSelectionOwner
+	width, height uint16 // root window of screen 0
+	randr         bool
+	monitors      []fakeX11Monitor
+	ownerPID      uint32 // X-Resource is implemented when set
+}
+
+type fakeX11Monitor struct {
+	name          string
+	primary       bool
+	x, y          int16
+	width, height uint16
+	outputs       int
+	// Reported as the CONNECTOR_ID property of the first output when set
+	connectorID uint32
+	// The mode of the CRTC driving the first output, reported when outputs > 0 and dotClock is set
+	dotClock       uint32
+	hTotal, vTotal uint16
+}
+
+const (
+	fakeX11Root         = 0x1e0
+	fakeX11RandROpcode  = 140
+	fakeX11MonitorAtoms = 500
+	fakeX11XResOpcode   = 141
+	// The output, CRTC and mode of monitor i are numbered from these
+	fakeX11Outputs = 100
+	fakeX11Crtcs   = 200
+	fakeX11Modes   = 300
+)
+
+// fakeX11Setup returns setup data with a vendor, a pixmap format, and one screen with one depth and visual
+func fakeX11Setup(width, height uint16) []byte {
+	setup := make([]byte, 32)
+	binary.LittleEndian.PutUint16(setup[16:], 4)
+	setup[20], setup[21] = 1, 1
+	setup = append(setup, "lima"...)
+	setup = append(setup, make([]byte, 8)...)
+	screen := make([]byte, 40)
+	binary.LittleEndian.PutUint32(screen, fakeX11Root)
+	binary.LittleEndian.PutUint16(screen[20:], width)
+	binary.LittleEndian.PutUint16(screen[22:], height)
+	screen[39] = 1
+	depth := make([]byte, 8+24)
+	depth[0] = 24
+	binary.LittleEndian.PutUint16(depth[2:], 1)
+	return append(append(setup, screen...), depth...)
+}
+
+// startFakeX11 serves one X11 connection with the replies configured in fake
+func startFakeX11(t *testing.T, fake fakeX11) (socketPath string, gotCookie chan []byte) {
+	t.Helper()
+	socketPath = filepath.Join(t.TempDir(), "X0")
+	l, err := net.Listen("unix", socketPath)
+	assert.NilError(t, err)
+	t.Cleanup(func() { l.Close() })
+	gotCookie = make(chan []byte, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		setup := make([]byte, 12)
+		if _, err := io.ReadFull(conn, setup); err != nil {
+			return
+		}
+		nameLen := int(binary.LittleEndian.Uint16(setup[6:]))
+		dataLen := int(binary.LittleEndian.Uint16(setup[8:]))
+		auth := make([]byte, len(x11Pad(make([]byte, nameLen)))+len(x11Pad(make([]byte, dataLen))))
+		if _, err := io.ReadFull(conn, auth); err != nil {
+			return
+		}
+		gotCookie <- auth[len(x11Pad(make([]byte, nameLen))):][:dataLen]
+		data := fakeX11Setup(fake.width, fake.height)
+		reply := make([]byte, 8)
+		reply[0] = 1
+		binary.LittleEndian.PutUint16(reply[6:], uint16(len(data)/4))
+		_, _ = conn.Write(append(reply, data...))
+
+		for {
+			head := make([]byte, 4)
+			if _, err := io.ReadFull(conn, head); err != nil {
+				return
+			}
+			rest := make([]byte, 4*int(binary.LittleEndian.Uint16(head[2:]))-4)
+			if _, err := io.ReadFull(conn, rest); err != nil {
+				return
+			}
+			reply := make([]byte, 32)
+			reply[0] = 1
+			switch {
+			case head[0] == x11OpInternAtom:
+				binary.LittleEndian.PutUint32(reply[8:], fake.atom)
+			case head[0] == x11OpGetSelectionOwner:
+				binary.LittleEndian.PutUint32(reply[8:], fake.owner)
+			case head[0] == x11OpQueryExtension:
+				switch string(rest[4:][:binary.LittleEndian.Uint16(rest)]) {
+				case randrExtensionName:
+					if fake.randr {
+						reply[8], reply[9] = 1, fakeX11RandROpcode
+					}
+				case xresExtensionName:
+					if fake.ownerPID != 0 {
+						reply[8], reply[9] = 1, fakeX11XResOpcode
+					}
+				}
+			case head[0] == x11OpGetAtomName:
+				name := fake.monitors[binary.LittleEndian.Uint32(rest)-fakeX11MonitorAtoms].name
+				binary.LittleEndian.PutUint16(reply[8:], uint16(len(name)))
+				reply = append(reply, x11Pad([]byte(name))...)
+			case head[0] == fakeX11RandROpcode && head[1] == randrOpQueryVersion:
+				binary.LittleEndian.PutUint32(reply[8:], 1)
+				binary.LittleEndian.PutUint32(reply[12:], 6)
+			case head[0] == fakeX11RandROpcode && head[1] == randrOpGetMonitors && binary.LittleEndian.Uint32(rest) == fakeX11Root:
+				binary.LittleEndian.PutUint32(reply[12:], uint32(len(fake.monitors)))
+				for i, m := range fake.monitors {
+					b := make([]byte, 24+4*m.outputs)
+					binary.LittleEndian.PutUint32(b, fakeX11MonitorAtoms+uint32(i))
+					if m.primary {
+						b[4] = 1
+					}
+					binary.LittleEndian.PutUint16(b[6:], uint16(m.outputs))
+					binary.LittleEndian.PutUint16(b[8:], uint16(m.x))
+					binary.LittleEndian.PutUint16(b[10:], uint16(m.y))
+					binary.LittleEndian.PutUint16(b[12:], m.width)
+					binary.LittleEndian.PutUint16(b[14:], m.height)
+					for j := range m.outputs {
+						binary.LittleEndian.PutUint32(b[24+4*j:], fakeX11Outputs+uint32(i))
+					}
+					reply = append(reply, b...)
+				}
+			case head[0] == fakeX11RandROpcode && head[1] == randrOpGetScreenResourcesCurrent && binary.LittleEndian.Uint32(rest) == fakeX11Root:
+				n := len(fake.monitors)
+				binary.LittleEndian.PutUint16(reply[16:], uint16(n))
+				binary.LittleEndian.PutUint16(reply[18:], uint16(n))
+				binary.LittleEndian.PutUint16(reply[20:], uint16(n))
+				for i := range n {
+					reply = binary.LittleEndian.AppendUint32(reply, fakeX11Crtcs+uint32(i))
+				}
+				for i := range n {
+					reply = binary.LittleEndian.AppendUint32(reply, fakeX11Outputs+uint32(i))
+				}
+				for i, m := range fake.monitors {
+					mode := make([]byte, randrModeInfoLength)
+					binary.LittleEndian.PutUint32(mode, fakeX11Modes+uint32(i))
+					binary.LittleEndian.PutUint32(mode[8:], m.dotClock)
+					binary.LittleEndian.PutUint16(mode[16:], m.hTotal)
+					binary.LittleEndian.PutUint16(mode[24:], m.vTotal)
+					reply = append(reply, mode...)
+				}
+			case head[0] == fakeX11RandROpcode && head[1] == randrOpGetOutputProperty && binary.LittleEndian.Uint32(rest[4:]) == fake.atom:
+				if id := fake.monitors[binary.LittleEndian.Uint32(rest)-fakeX11Outputs].connectorID; id != 0 {
+					reply[1] = 32
+					binary.LittleEndian.PutUint32(reply[8:], x11AtomInteger)
+					binary.LittleEndian.PutUint32(reply[16:], 1)
+					reply = binary.LittleEndian.AppendUint32(reply, id)
+				}
+			case head[0] == fakeX11RandROpcode && head[1] == randrOpGetCrtcInfo:
+				i := binary.LittleEndian.Uint32(rest) - fakeX11Crtcs
+				if m := fake.monitors[i]; m.outputs > 0 && m.dotClock != 0 {
+					binary.LittleEndian.PutUint32(reply[20:], fakeX11Modes+i)
+					binary.LittleEndian.PutUint16(reply[28:], 1)
+					reply = binary.LittleEndian.AppendUint32(reply, fakeX11Outputs+i)
+				}
+			case head[0] == fakeX11XResOpcode && head[1] == xresOpQueryClientIDs && binary.LittleEndian.Uint32(rest[4:]) == fake.owner:
+				binary.LittleEndian.PutUint32(reply[8:], 1)
+				v := make([]byte, 16)
+				binary.LittleEndian.PutUint32(v, fake.owner)
+				binary.LittleEndian.PutUint32(v[4:], xresClientIDLocalClientPID)
+				binary.LittleEndian.PutUint32(v[8:], 4)
+				binary.LittleEndian.PutUint32(v[12:], fake.ownerPID)
+				reply = append(reply, v...)
+			default:
+				reply[0], reply[1] = 0, 1 // BadRequest
+			}
+			binary.LittleEndian.PutUint32(reply[4:], uint32((len(reply)-32)/4))
+			_, _ = conn.Write(reply)
+		}
+	}()
+	return socketPath, gotCookie
+}
+
+func TestX11QuerySelectionOwned(t *testing.T) {
+	sock, gotCookie := startFakeX11(t, fakeX11{atom: 300, owner: 0x200001})
+	owned, err := x11QuerySelectionOwned(sock, []byte("0123456789abcdef"), "_NET_WM_CM_S0")
+	assert.NilError(t, err)
+	assert.Assert(t, owned)
+	assert.DeepEqual(t, []byte("0123456789abcdef"), <-gotCookie)
+
+	sock, _ = startFakeX11(t, fakeX11{atom: 300})
+	owned, err = x11QuerySelectionOwned(sock, nil, "_NET_WM_CM_S0")
+	assert.NilError(t, err)
+	assert.Assert(t, !owned)
+
+	// The atom does not exist, so the selection was never owned
+	sock, _ = startFakeX11(t, fakeX11{owner: 0x200001})
+	owned, err = x11QuerySelectionOwned(sock, nil, "_NET_WM_CM_S0")
+	assert.NilError(t, err)
+	assert.Assert(t, !owned)
+}
+
+func TestX11QuerySelectionOwnerPID(t *testing.T) {
+	sock, _ := startFakeX11(t, fakeX11{atom: 300, owner: 0x200001, ownerPID: 1234})
+	pid, err := x11QuerySelectionOwnerPID(sock, nil, "CLIPBOARD")
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(1234), pid)
+
+	// No owner, X-Resource is not queried
+	sock, _ = startFakeX11(t, fakeX11{atom: 300})
+	pid, err = x11QuerySelectionOwnerPID(sock, nil, "CLIPBOARD")
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(0), pid)
+
+	sock, _ = startFakeX11(t, fakeX11{atom: 300, owner: 0x200001})
+	_, err = x11QuerySelectionOwnerPID(sock, nil, "CLIPBOARD")
+	assert.ErrorContains(t, err, "X-Resource")
+}
+
+func TestDetectClipboardOwner(t *testing.T) {
+	orig := x11SocketDir
+	t.Cleanup(func() { x11SocketDir = orig })
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "Xauthority"))
+	for _, tc := range []struct {
+		process string
+		owned   bool
+	}{
+		{process: "spice-vdagent", owned: true},
+		{process: "copyq"},
+	} {
+		sock, _ := startFakeX11(t, fakeX11{atom: 300, owner: 0x200001, ownerPID: 1234})
+		x11SocketDir = filepath.Dir(sock)
+		fakeRunner(t, map[string]string{"ps -o comm= -p 1234": tc.process + "\n"})
+		info := &api.GUIInfo{DisplayServer: "X11", Displays: []string{":0"}, Spice: &api.SpiceAgentInfo{}}
+		detectClipboardOwner(t.Context(), info)
+		assert.Equal(t, tc.process, info.Spice.ClipboardSelectionOwner)
+		assert.Equal(t, tc.owned, info.Spice.ClipboardSelectionOwned)
+	}
+}
+
+func TestX11ReadScreen(t *testing.T) {
+	sock, _ := startFakeX11(t, fakeX11{
+		atom: 300, width: 3200, height: 1080, randr: true,
+		monitors: []fakeX11Monitor{
+			// 148.5 MHz / (2200 x 1125) = 60 Hz
+			{name: "Virtual-1", width: 1920, height: 1080, outputs: 1, connectorID: 35, dotClock: 148500000, hTotal: 2200, vTotal: 1125},
+			{name: "Virtual-2", primary: true, x: 1920, width: 1280, height: 800, outputs: 1},
+		},
+	})
+	screen, err := x11ReadScreen(sock, nil, 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []*api.DisplayMode{
+		{Name: "Virtual-1", Width: 1920, Height: 1080, RefreshRate: 60},
+		{Name: "Virtual-2", Primary: true, X: 1920, Width: 1280, Height: 800},
+	}, screen.Monitors, protocmp.Transform())
+	assert.DeepEqual(t, map[string]uint32{"Virtual-1": 35}, screen.ConnectorIDs)
+	assert.Equal(t, "1280x800", screen.Resolution())
+
+	// Without RandR, the resolution is the size of the root window
+	sock, _ = startFakeX11(t, fakeX11{width: 1024, height: 768})
+	screen, err = x11ReadScreen(sock, nil, 0)
+	assert.NilError(t, err)
+	assert.Assert(t, screen.Monitors == nil)
+	assert.Equal(t, "1024x768", screen.Resolution())
+
+	sock, _ = startFakeX11(t, fakeX11{width: 1024, height: 768})
+	_, err = x11ReadScreen(sock, nil, 1)
+	assert.ErrorContains(t, err, "screen 1 does not exist")
+}
+
+func TestX11ModeRate(t *testing.T) {
+	mode := func(dotClock uint32, hTotal, vTotal uint16, flags uint32) []byte {
+		m := make([]byte, randrModeInfoLength)
+		binary.LittleEndian.PutUint32(m[8:], dotClock)
+		binary.LittleEndian.PutUint16(m[16:], hTotal)
+		binary.LittleEndian.PutUint16(m[24:], vTotal)
+		binary.LittleEndian.PutUint32(m[28:], flags)
+		return m
+	}
+	assert.Equal(t, x11ModeRate(mode(148500000, 2200, 1125, 0)), 60.0)
+	// 1920x1080i, 60 fields per second
+	assert.Equal(t, x11ModeRate(mode(74250000, 2200, 1125, randrModeInterlace)), 60.0)
+	assert.Equal(t, x11ModeRate(mode(50000000, 1000, 500, randrModeDoubleScan)), 50.0)
+	assert.Equal(t, x11ModeRate(mode(0, 0, 0, 0)), 0.0)
+}
+
+func TestGetX11ResolutionReadsScreen(t *testing.T) {
+	sock, _ := startFakeX11(t, fakeX11{
+		width: 1920, height: 1080, randr: true,
+		monitors: []fakeX11Monitor{{name: "Virtual-1", primary: true, width: 1920, height: 1080}},
+	})
+	orig := x11SocketDir
+	t.Cleanup(func() { x11SocketDir = orig })
+	x11SocketDir = filepath.Dir(sock)
+	// xrandr would report a different resolution
+	fakeRunner(t, map[string]string{"xrandr": "Virtual-1 connected primary 800x600+0+0\n   800x600     60.00*+\n"})
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "Xauthority"))
+
+	ctx := context.WithValue(t.Context(), displayKey{}, ":0")
+	assert.Equal(t, "1920x1080", getX11Resolution(ctx))
+}
+
+func xauthEntry(number, name string, data []byte) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, uint16(256)) // FamilyLocal
+	for _, field := range [][]byte{[]byte("lima"), []byte(number), []byte(name), data} {
+		_ = binary.Write(&b, binary.BigEndian, uint16(len(field)))
+		b.Write(field)
+	}
+	return b.Bytes()
+}
+
+func TestParseXauthority(t *testing.T) {
+	file := append(xauthEntry("0", x11CookieName, []byte("cookie0")), xauthEntry("1", x11CookieName, []byte("cookie1"))...)
+
+	cookie, err := parseXauthority(bytes.NewReader(file), "1")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []byte("cookie1"), cookie)
+
+	cookie, err = parseXauthority(bytes.NewReader(file), "2")
+	assert.NilError(t, err)
+	assert.Assert(t, cookie == nil)
+
+	_, err = parseXauthority(bytes.NewReader(file[:len(file)-3]), "1")
+	assert.ErrorContains(t, err, "malformed")
+}
+
+func TestX11Display(t *testing.T) {
+	num, screen, err := x11Display(":1.2")
+	assert.NilError(t, err)
+	assert.Equal(t, num+"."+screen, "1.2")
+
+	num, screen, err = x11Display(":0")
+	assert.NilError(t, err)
+	assert.Equal(t, num+"."+screen, "0.0")
+
+	_, _, err = x11Display("localhost:0")
+	assert.ErrorContains(t, err, "only local")
+}
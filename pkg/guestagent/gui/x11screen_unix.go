@@ -0,0 +1,365 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package gui
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/api"
+)
+
+// Reading the screen size and the RandR monitors over the X11 protocol, so that
+// neither xrandr nor xdpyinfo need to be installed in the guest.
+// See https://gitlab.freedesktop.org/xorg/proto/xorgproto/-/blob/master/randrproto.txt
+
+const (
+	x11OpGetAtomName                 = 17
+	x11OpQueryExtension              = 98
+	x11AtomInteger                   = 19
+	randrOpQueryVersion              = 0
+	randrOpGetOutputProperty         = 15
+	randrOpGetCrtcInfo               = 20
+	randrOpGetScreenResourcesCurrent = 25
+	randrOpGetMonitors               = 42
+	randrExtensionName               = "RANDR"
+	randrConnectorIDProperty         = "CONNECTOR_ID" // set by the modesetting driver
+	randrModeInterlace               = 0x10
+	randrModeDoubleScan              = 0x20
+	x11SetupHeaderLength             = 32 // fixed part of the setup data, before the vendor string
+	x11ScreenLength                  = 40 // fixed part of a SCREEN, before its depths
+	randrModeInfoLength              = 32
+)
+
+// x11Screen is the geometry of an X11 screen
+type x11Screen struct {
+	Width, Height int32
+	// Monitors are the active RandR monitors, nil when the server does not implement RandR 1.5
+	Monitors []*api.DisplayMode
+	// ConnectorIDs are the DRM connector IDs of the monitors by name, for the outputs that report one
+	ConnectorIDs map[string]uint32
+}
+
+// Resolution returns the size of the primary monitor, or of the whole screen without RandR monitors.
+// xrandr reports the current mode of the first output, which RandR lists first when it is primary.
+func (s *x11Screen) Resolution() string {
+	w, h := s.Width, s.Height
+	for i, m := range s.Monitors {
+		if i == 0 || m.Primary {
+			w, h = m.Width, m.Height
+		}
+		if m.Primary {
+			break
+		}
+	}
+	return fmt.Sprintf("%dx%d", w, h)
+}
+
+// x11QueryScreen connects to the X11 display of the session and reads the geometry of its screen
+func x11QueryScreen(ctx context.Context) (*x11Screen, error) {
+	display := requestedDisplay(ctx)
+	if display == "" {
+		display = getenv(ctx, "DISPLAY")
+	}
+	if display == "" {
+		return nil, errors.New("DISPLAY is not set")
+	}
+	num, screen, err := x11Display(display)
+	if err != nil {
+		return nil, err
+	}
+	screenNum, err := strconv.Atoi(screen)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X11 display %q", display)
+	}
+	cookie, err := x11Cookie(ctx, num)
+	if err != nil {
+		return nil, err
+	}
+	return x11ReadScreen(filepath.Join(x11SocketDir, "X"+num), cookie, screenNum)
+}
+
+// x11ReadScreen connects to the X server socket, reads the size of the screen from the
+// connection setup, and its monitors with RandR
+func x11ReadScreen(socketPath string, cookie []byte, screen int) (*x11Screen, error) {
+	conn, err := net.DialTimeout("unix", socketPath, x11Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(x11Timeout)); err != nil {
+		return nil, err
+	}
+	setup, err := x11Setup(conn, cookie)
+	if err != nil {
+		return nil, err
+	}
+	root, width, height, err := x11SetupScreen(setup, screen)
+	if err != nil {
+		return nil, err
+	}
+	res := &x11Screen{Width: int32(width), Height: int32(height)}
+
+	major, err := x11QueryExtension(conn, randrExtensionName)
+	if err != nil {
+		return nil, err
+	}
+	if major == 0 {
+		return res, nil
+	}
+	// RRGetMonitors requires RandR 1.5
+	req := make([]byte, 12)
+	req[0], req[1] = major, randrOpQueryVersion
+	binary.LittleEndian.PutUint16(req[2:], 3)
+	binary.LittleEndian.PutUint32(req[4:], 1)
+	binary.LittleEndian.PutUint32(req[8:], 5)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return nil, fmt.Errorf("RRQueryVersion: %w", err)
+	}
+	serverMajor, serverMinor := binary.LittleEndian.Uint32(reply[8:]), binary.LittleEndian.Uint32(reply[12:])
+	if serverMajor < 1 || (serverMajor == 1 && serverMinor < 5) {
+		return res, nil
+	}
+	monitors, outputs, err := x11RandRMonitors(conn, major, root)
+	if err != nil {
+		return nil, err
+	}
+	res.Monitors = monitors
+	// The refresh rates are best effort, the monitors are reported without them otherwise
+	if rates, err := x11RandRRefreshRates(conn, major, root); err == nil {
+		for i, m := range monitors {
+			m.RefreshRate = rates[outputs[i]]
+		}
+	}
+	// So are the DRM connectors, matched by name otherwise
+	if ids, err := x11RandRConnectorIDs(conn, major, outputs); err == nil {
+		res.ConnectorIDs = make(map[string]uint32)
+		for i, m := range monitors {
+			if ids[i] != 0 {
+				res.ConnectorIDs[m.Name] = ids[i]
+			}
+		}
+	}
+	return res, nil
+}
+
+// x11SetupScreen returns the root window and the size in pixels of the screen from the setup data
+func x11SetupScreen(setup []byte, screen int) (root uint32, width, height uint16, err error) {
+	if len(setup) < x11SetupHeaderLength {
+		return 0, 0, 0, errors.New("short X11 setup data")
+	}
+	vendorLen := int(binary.LittleEndian.Uint16(setup[16:]))
+	numScreens, numFormats := int(setup[20]), int(setup[21])
+	if screen >= numScreens {
+		return 0, 0, 0, fmt.Errorf("X11 screen %d does not exist, the display has %d screens", screen, numScreens)
+	}
+	off := x11SetupHeaderLength + len(x11Pad(make([]byte, vendorLen))) + 8*numFormats
+	for i := 0; ; i++ {
+		if off+x11ScreenLength > len(setup) {
+			return 0, 0, 0, errors.New("short X11 setup data")
+		}
+		s := setup[off:]
+		if i == screen {
+			return binary.LittleEndian.Uint32(s), binary.LittleEndian.Uint16(s[20:]), binary.LittleEndian.Uint16(s[22:]), nil
+		}
+		// Skip the depths, each followed by its 24-byte visual types
+		off += x11ScreenLength
+		for range int(s[39]) {
+			if off+8 > len(setup) {
+				return 0, 0, 0, errors.New("short X11 setup data")
+			}
+			off += 8 + 24*int(binary.LittleEndian.Uint16(setup[off+2:]))
+		}
+	}
+}
+
+// x11QueryExtension returns the major opcode of the extension, or 0 if the server does not implement it
+func x11QueryExtension(conn net.Conn, name string) (uint8, error) {
+	padded := x11Pad([]byte(name))
+	req := make([]byte, 8, 8+len(padded))
+	req[0] = x11OpQueryExtension
+	binary.LittleEndian.PutUint16(req[2:], uint16((8+len(padded))/4))
+	binary.LittleEndian.PutUint16(req[4:], uint16(len(name)))
+	req = append(req, padded...)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("QueryExtension %s: %w", name, err)
+	}
+	if reply[8] == 0 {
+		return 0, nil
+	}
+	return reply[9], nil
+}
+
+// x11RandRMonitors lists the active monitors of the root window with RRGetMonitors,
+// and the first output of each monitor, 0 for a monitor without outputs.
+// Monitors created by the server for each output are named after the output, e.g., "Virtual-1".
+func x11RandRMonitors(conn net.Conn, major uint8, root uint32) ([]*api.DisplayMode, []uint32, error) {
+	req := make([]byte, 12)
+	req[0], req[1] = major, randrOpGetMonitors
+	binary.LittleEndian.PutUint16(req[2:], 3)
+	binary.LittleEndian.PutUint32(req[4:], root)
+	req[8] = 1 // get_active
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("RRGetMonitors: %w", err)
+	}
+	n := int(binary.LittleEndian.Uint32(reply[12:]))
+	monitors := make([]*api.DisplayMode, 0, n)
+	outputs := make([]uint32, 0, n)
+	off := 32
+	for range n {
+		if off+24 > len(reply) {
+			return nil, nil, errors.New("short RRGetMonitors reply")
+		}
+		m := reply[off:]
+		numOutputs := int(binary.LittleEndian.Uint16(m[6:]))
+		if off+24+4*numOutputs > len(reply) {
+			return nil, nil, errors.New("short RRGetMonitors reply")
+		}
+		name, err := x11AtomName(conn, binary.LittleEndian.Uint32(m))
+		if err != nil {
+			return nil, nil, err
+		}
+		var output uint32
+		if numOutputs > 0 {
+			output = binary.LittleEndian.Uint32(m[24:])
+		}
+		outputs = append(outputs, output)
+		monitors = append(monitors, &api.DisplayMode{
+			Name:    name,
+			Primary: m[4] != 0,
+			X:       int32(int16(binary.LittleEndian.Uint16(m[8:]))),
+			Y:       int32(int16(binary.LittleEndian.Uint16(m[10:]))),
+			Width:   int32(binary.LittleEndian.Uint16(m[12:])),
+			Height:  int32(binary.LittleEndian.Uint16(m[14:])),
+		})
+		off += 24 + 4*numOutputs
+	}
+	return monitors, outputs, nil
+}
+
+// x11RandRRefreshRates returns the refresh rate in Hz of the outputs driven by a CRTC,
+// from the mode of the CRTC, with RRGetScreenResourcesCurrent and RRGetCrtcInfo
+func x11RandRRefreshRates(conn net.Conn, major uint8, root uint32) (map[uint32]float64, error) {
+	req := make([]byte, 8)
+	req[0], req[1] = major, randrOpGetScreenResourcesCurrent
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], root)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return nil, fmt.Errorf("RRGetScreenResourcesCurrent: %w", err)
+	}
+	configTimestamp := binary.LittleEndian.Uint32(reply[12:])
+	numCrtcs := int(binary.LittleEndian.Uint16(reply[16:]))
+	numOutputs := int(binary.LittleEndian.Uint16(reply[18:]))
+	numModes := int(binary.LittleEndian.Uint16(reply[20:]))
+	modesOff := 32 + 4*numCrtcs + 4*numOutputs
+	if modesOff+randrModeInfoLength*numModes > len(reply) {
+		return nil, errors.New("short RRGetScreenResourcesCurrent reply")
+	}
+	modeRates := make(map[uint32]float64, numModes)
+	for i := range numModes {
+		m := reply[modesOff+randrModeInfoLength*i:]
+		modeRates[binary.LittleEndian.Uint32(m)] = x11ModeRate(m)
+	}
+
+	rates := make(map[uint32]float64)
+	for i := range numCrtcs {
+		req := make([]byte, 12)
+		req[0], req[1] = major, randrOpGetCrtcInfo
+		binary.LittleEndian.PutUint16(req[2:], 3)
+		binary.LittleEndian.PutUint32(req[4:], binary.LittleEndian.Uint32(reply[32+4*i:]))
+		binary.LittleEndian.PutUint32(req[8:], configTimestamp)
+		info, err := x11Request(conn, req)
+		if err != nil {
+			return nil, fmt.Errorf("RRGetCrtcInfo: %w", err)
+		}
+		n := int(binary.LittleEndian.Uint16(info[28:]))
+		if 32+4*n > len(info) {
+			return nil, errors.New("short RRGetCrtcInfo reply")
+		}
+		// A disabled CRTC has mode 0 and no outputs
+		rate := modeRates[binary.LittleEndian.Uint32(info[20:])]
+		for j := range n {
+			rates[binary.LittleEndian.Uint32(info[32+4*j:])] = rate
+		}
+	}
+	return rates, nil
+}
+
+// x11RandRConnectorIDs returns the DRM connector ID of each output from its CONNECTOR_ID property,
+// with RRGetOutputProperty; 0 for a missing output, or an output without the property
+func x11RandRConnectorIDs(conn net.Conn, major uint8, outputs []uint32) ([]uint32, error) {
+	ids := make([]uint32, len(outputs))
+	atom, err := x11InternAtom(conn, randrConnectorIDProperty)
+	if err != nil || atom == 0 {
+		return ids, err
+	}
+	for i, output := range outputs {
+		if output == 0 {
+			continue
+		}
+		req := make([]byte, 28)
+		req[0], req[1] = major, randrOpGetOutputProperty
+		binary.LittleEndian.PutUint16(req[2:], 7)
+		binary.LittleEndian.PutUint32(req[4:], output)
+		binary.LittleEndian.PutUint32(req[8:], atom)
+		binary.LittleEndian.PutUint32(req[20:], 1) // long-length
+		reply, err := x11Request(conn, req)
+		if err != nil {
+			return nil, fmt.Errorf("RRGetOutputProperty: %w", err)
+		}
+		// format 32, type INTEGER, and one item
+		if reply[1] == 32 && binary.LittleEndian.Uint32(reply[8:]) == x11AtomInteger &&
+			binary.LittleEndian.Uint32(reply[16:]) == 1 && len(reply) >= 36 {
+			ids[i] = binary.LittleEndian.Uint32(reply[32:])
+		}
+	}
+	return ids, nil
+}
+
+// x11ModeRate returns the refresh rate in Hz of a RandR MODEINFO, as computed by xrandr
+func x11ModeRate(m []byte) float64 {
+	dotClock := float64(binary.LittleEndian.Uint32(m[8:]))
+	hTotal := float64(binary.LittleEndian.Uint16(m[16:]))
+	vTotal := float64(binary.LittleEndian.Uint16(m[24:]))
+	flags := binary.LittleEndian.Uint32(m[28:])
+	if flags&randrModeDoubleScan != 0 {
+		vTotal *= 2
+	}
+	if flags&randrModeInterlace != 0 {
+		vTotal /= 2
+	}
+	if hTotal == 0 || vTotal == 0 {
+		return 0
+	}
+	return dotClock / (hTotal * vTotal)
+}
+
+// x11AtomName returns the name of the atom with GetAtomName
+func x11AtomName(conn net.Conn, atom uint32) (string, error) {
+	req := make([]byte, 8)
+	req[0] = x11OpGetAtomName
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], atom)
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return "", fmt.Errorf("GetAtomName %d: %w", atom, err)
+	}
+	n := int(binary.LittleEndian.Uint16(reply[8:]))
+	if 32+n > len(reply) {
+		return "", errors.New("short GetAtomName reply")
+	}
+	return string(reply[32 : 32+n]), nil
+}
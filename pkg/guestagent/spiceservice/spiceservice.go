@@ -1,31 +1,48 @@
 // SPDX-FileCopyrightText: Copyright The Lima Authors
 // SPDX-License-Identifier: Apache-2.0
 
-//go:build !linux
+//go:build !linux && !freebsd
 
 package spiceservice
 
 import (
 	"context"
+	"errors"
 )
 
 // SpiceStatus represents the status of SPICE-related services
 type SpiceStatus struct {
-	AgentInstalled bool
-	AgentRunning   bool
-	VPortExists    bool
-	ClipboardReady bool
-	ErrorMessage   string
+	AgentInstalled      bool
+	AgentRunning        bool
+	AgentAutostart      bool
+	SessionAgentRunning bool
+	VPortExists         bool
+	ClipboardReady      bool
+	ErrorMessage        string
+	MaxClipboardBytes   int64
+	ClipboardMimeTypes  []string
+	ClipboardMechanism  string
 }
 
-// DetectSpiceStatus returns a stub status for non-Linux platforms
+// DetectSpiceStatus returns a stub status for platforms other than Linux and FreeBSD
 func DetectSpiceStatus(ctx context.Context) *SpiceStatus {
 	return &SpiceStatus{
-		ErrorMessage: "SPICE agent only available on Linux guests",
+		ErrorMessage: "SPICE agent only available on Linux and FreeBSD guests",
 	}
 }
 
-// EnsureSpiceAgent is a no-op on non-Linux platforms
+// AgentOptions are the spice-vdagentd settings written by ConfigureAgent
+type AgentOptions struct {
+	DisableFileTransfer *bool
+	UseSessionCopyPaste *bool
+}
+
+// ConfigureAgent is not supported on platforms other than Linux and FreeBSD
+func ConfigureAgent(ctx context.Context, opts AgentOptions) error {
+	return errors.New("SPICE agent only available on Linux and FreeBSD guests")
+}
+
+// EnsureSpiceAgent is a no-op on platforms other than Linux and FreeBSD
 func EnsureSpiceAgent(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package spiceservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AgentOptions are the spice-vdagentd settings written by ConfigureAgent.
+// A nil field leaves the setting unchanged.
+type AgentOptions struct {
+	DisableFileTransfer *bool // "disable-filexfer"
+	UseSessionCopyPaste *bool // "use-session-copy-paste"
+}
+
+// settings returns the configuration keys to set, in a stable order
+func (o AgentOptions) settings() [][2]string {
+	var kv [][2]string
+	if o.DisableFileTransfer != nil {
+		kv = append(kv, [2]string{"disable-filexfer", strconv.FormatBool(*o.DisableFileTransfer)})
+	}
+	if o.UseSessionCopyPaste != nil {
+		kv = append(kv, [2]string{"use-session-copy-paste", strconv.FormatBool(*o.UseSessionCopyPaste)})
+	}
+	return kv
+}
+
+// ConfigureAgent sets the options in the spice-vdagentd configuration file and restarts the daemon.
+// The other lines of the file are preserved, and nothing is written or restarted when the options are already set.
+func ConfigureAgent(ctx context.Context, opts AgentOptions) error {
+	old, err := os.ReadFile(agentConfigFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	updated, changed := setAgentConfig(string(old), opts.settings())
+	if !changed {
+		logrus.Debugf("%s is up to date", agentConfigFile)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(agentConfigFile), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(agentConfigFile, []byte(updated), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", agentConfigFile, err)
+	}
+	logrus.Infof("Updated %s, restarting the SPICE agent", agentConfigFile)
+	return restartSpiceService(ctx)
+}
+
+// setAgentConfig sets the "key=value" settings in the content of the configuration file.
+// Existing keys are updated in place, comments are left alone, and missing keys are appended.
+func setAgentConfig(content string, settings [][2]string) (string, bool) {
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+	changed := false
+	for _, kv := range settings {
+		key, value := kv[0], kv[1]
+		found := false
+		for i, line := range lines {
+			k, v, ok := strings.Cut(line, "=")
+			if !ok || strings.HasPrefix(strings.TrimSpace(k), "#") || strings.TrimSpace(k) != key {
+				continue
+			}
+			found = true
+			if strings.TrimSpace(v) != value {
+				lines[i] = key + "=" + value
+				changed = true
+			}
+		}
+		if !found {
+			lines = append(lines, key+"="+value)
+			changed = true
+		}
+	}
+	if !changed {
+		return content, false
+	}
+	return strings.Join(lines, "\n") + "\n", true
+}
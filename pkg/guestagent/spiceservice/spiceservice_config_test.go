@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package spiceservice
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSetAgentConfig(t *testing.T) {
+	yes, no := true, false
+	opts := AgentOptions{DisableFileTransfer: &yes, UseSessionCopyPaste: &no}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "missing file",
+			content:  "",
+			expected: "disable-filexfer=true\nuse-session-copy-paste=false\n",
+			changed:  true,
+		},
+		{
+			name:     "keeps other lines",
+			content:  "# spice-vdagentd settings\n#disable-filexfer=false\nport=/dev/vport0p1\ndisable-filexfer = false\n",
+			expected: "# spice-vdagentd settings\n#disable-filexfer=false\nport=/dev/vport0p1\ndisable-filexfer=true\nuse-session-copy-paste=false\n",
+			changed:  true,
+		},
+		{
+			name:     "already set",
+			content:  "use-session-copy-paste = false\ndisable-filexfer=true",
+			expected: "use-session-copy-paste = false\ndisable-filexfer=true",
+			changed:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, changed := setAgentConfig(tt.content, opts.settings())
+			assert.Equal(t, actual, tt.expected)
+			assert.Equal(t, changed, tt.changed)
+		})
+	}
+
+	// Applying the result again is a no-op
+	once, _ := setAgentConfig("", opts.settings())
+	twice, changed := setAgentConfig(once, opts.settings())
+	assert.Equal(t, twice, once)
+	assert.Assert(t, !changed)
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// agentAutostartFile is the XDG autostart entry installed by the sysutils/spice-vdagent port
+const agentAutostartFile = "/usr/local/etc/xdg/autostart/spice-vdagent.desktop"
+
+// agentConfigFile is the spice-vdagentd configuration edited by ConfigureAgent
+const agentConfigFile = "/usr/local/etc/spice/vdagentd.conf"
+
+// DetectSpiceStatus checks the current SPICE configuration
+func DetectSpiceStatus(ctx context.Context) *SpiceStatus {
+	status := &SpiceStatus{}
+
+	status.VPortExists = checkVirtioPort()
+	status.AgentInstalled = checkSpiceInstalled(ctx)
+	if status.AgentInstalled {
+		status.AgentRunning = checkSpiceRunning(ctx)
+		status.AgentAutostart = checkAgentAutostart()
+		status.SessionAgentRunning = checkSessionAgentRunning(ctx)
+	}
+
+	status.ClipboardReady = status.VPortExists && status.AgentInstalled && status.AgentRunning && status.SessionAgentRunning
+	if !status.ClipboardReady {
+		status.ErrorMessage = buildErrorMessage(status)
+	}
+
+	setClipboardLimits(status)
+	setClipboardMechanism(status)
+
+	return status
+}
+
+// EnsureSpiceAgent attempts to install and start spice-vdagent if needed
+func EnsureSpiceAgent(ctx context.Context) error {
+	status := DetectSpiceStatus(ctx)
+	if status.ClipboardReady {
+		logrus.Info("SPICE agent already configured and running")
+		return nil
+	}
+
+	if !status.VPortExists {
+		return errors.New("virtio console port not available (host SPICE not configured)")
+	}
+
+	if !status.AgentInstalled {
+		logrus.Info("Installing spice-vdagent package...")
+		ctx2, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		if output, err := exec.CommandContext(ctx2, "pkg", "install", "-y", "spice-vdagent").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to install spice-vdagent: %w (output: %s)", err, string(output))
+		}
+		logrus.Info("spice-vdagent package installed successfully")
+	}
+
+	if !status.AgentRunning {
+		logrus.Info("Starting spice_vdagentd service...")
+		if err := startSpiceService(ctx); err != nil {
+			return fmt.Errorf("failed to start spice_vdagentd: %w", err)
+		}
+		logrus.Info("spice_vdagentd service started successfully")
+	}
+
+	// The session client waits for a user to log in, DetectSpiceStatus reports it
+	if !checkSessionAgentRunning(ctx) {
+		logrus.Debug("spice-vdagent session client is not running yet")
+	}
+
+	return nil
+}
+
+// checkVirtioPort checks if the virtio console port of the SPICE agent exists.
+// virtio_console(4) creates /dev/vtcon/<name> for named ports; the /dev/ttyV* devices of the ports,
+// e.g., of the port of the Lima guest agent, do not tell their name.
+func checkVirtioPort() bool {
+	_, err := os.Stat(filepath.Join("/dev/vtcon", spicePortName))
+	return err == nil
+}
+
+// checkSpiceInstalled checks if the spice-vdagent package is installed
+func checkSpiceInstalled(ctx context.Context) bool {
+	if _, err := exec.LookPath("spice-vdagentd"); err == nil {
+		return true
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx2, "pkg", "info", "-e", "spice-vdagent").Run() == nil
+}
+
+// checkSpiceRunning checks if the spice_vdagentd service is running
+func checkSpiceRunning(ctx context.Context) bool {
+	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if exec.CommandContext(ctx2, "service", "spice_vdagentd", "status").Run() == nil {
+		return true
+	}
+
+	// Fallback: Check if process is running
+	return exec.CommandContext(ctx2, "pgrep", "-x", "spice-vdagentd").Run() == nil
+}
+
+// startSpiceService enables spice_vdagentd in rc.conf and starts it
+func startSpiceService(ctx context.Context) error {
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx2, "sysrc", "spice_vdagentd_enable=YES").CombinedOutput(); err != nil {
+		logrus.Warnf("Failed to enable spice_vdagentd: %v (output: %s)", err, string(output))
+	}
+
+	if output, err := exec.CommandContext(ctx2, "service", "spice_vdagentd", "start").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to start spice_vdagentd: %w (output: %s)", err, string(output))
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	if !checkSpiceRunning(ctx) {
+		return errors.New("service started but not running")
+	}
+
+	return nil
+}
+
+// restartSpiceService restarts spice_vdagentd, so that it reads its configuration again
+func restartSpiceService(ctx context.Context) error {
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx2, "service", "spice_vdagentd", "restart").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restart spice_vdagentd: %w (output: %s)", err, string(output))
+	}
+	return nil
+}
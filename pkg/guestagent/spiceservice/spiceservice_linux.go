@@ -8,20 +8,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
 )
 
-// SpiceStatus represents the status of SPICE-related services
-type SpiceStatus struct {
-	AgentInstalled bool   // Whether spice-vdagent package is installed
-	AgentRunning   bool   // Whether spice-vdagentd service is running
-	VPortExists    bool   // Whether /dev/vport* exists (virtio console)
-	ClipboardReady bool   // Whether clipboard sharing is functional
-	ErrorMessage   string // Any error encountered
-}
+// agentAutostartFile is the XDG autostart entry that starts the spice-vdagent session client
+const agentAutostartFile = "/etc/xdg/autostart/spice-vdagent.desktop"
+
+// agentConfigFile is the spice-vdagentd configuration edited by ConfigureAgent
+const agentConfigFile = "/etc/spice/vdagentd.conf"
 
 // DetectSpiceStatus checks the current SPICE configuration
 func DetectSpiceStatus(ctx context.Context) *SpiceStatus {
@@ -36,16 +36,22 @@ func DetectSpiceStatus(ctx context.Context) *SpiceStatus {
 	// Check if spice-vdagentd service is running
 	if status.AgentInstalled {
 		status.AgentRunning = checkSpiceRunning(ctx)
+		status.AgentAutostart = checkAgentAutostart()
+		status.SessionAgentRunning = checkSessionAgentRunning(ctx)
 	}
 
-	// Clipboard is ready if all components are present
-	status.ClipboardReady = status.VPortExists && status.AgentInstalled && status.AgentRunning
+	// Clipboard is ready if all components are present.
+	// Both the daemon and the per-session client are needed.
+	status.ClipboardReady = status.VPortExists && status.AgentInstalled && status.AgentRunning && status.SessionAgentRunning
 
 	// Generate error message if not ready
 	if !status.ClipboardReady {
 		status.ErrorMessage = buildErrorMessage(status)
 	}
 
+	setClipboardLimits(status)
+	setClipboardMechanism(status)
+
 	return status
 }
 
@@ -84,31 +90,48 @@ func EnsureSpiceAgent(ctx context.Context) error {
 		logrus.Info("spice-vdagentd service started successfully")
 	}
 
+	// The session client runs as the desktop user and cannot be started from here; until a user logs in,
+	// DetectSpiceStatus reports the clipboard as not ready
+	if !checkSessionAgentRunning(ctx) {
+		logrus.Debug("spice-vdagent session client is not running yet")
+	}
+
 	return nil
 }
 
-// checkVirtioPort checks if virtio console port device exists
+// Replaced in tests
+var (
+	sysVirtioPortsDir = "/sys/class/virtio-ports"
+	devDir            = "/dev"
+)
+
+// checkVirtioPort checks if the virtio console port of the SPICE agent exists
 func checkVirtioPort() bool {
-	// Check for /dev/vport* devices
-	matches, err := os.ReadDir("/dev")
-	if err != nil {
+	// sysfs names each port, e.g., /sys/class/virtio-ports/vport0p1/name, so /dev need not be scanned
+	if entries, err := os.ReadDir(sysVirtioPortsDir); err == nil {
+		for _, entry := range entries {
+			name, err := os.ReadFile(filepath.Join(sysVirtioPortsDir, entry.Name(), "name"))
+			if err == nil && strings.TrimSpace(string(name)) == spicePortName {
+				return true
+			}
+		}
 		return false
 	}
 
+	// Without /sys, look for the links created by udev, then for any /dev/vport* device
+	if _, err := os.Stat(filepath.Join(devDir, "virtio-ports")); err == nil {
+		_, err := os.Stat(filepath.Join(devDir, "virtio-ports", spicePortName))
+		return err == nil
+	}
+	matches, err := os.ReadDir(devDir)
+	if err != nil {
+		return false
+	}
 	for _, entry := range matches {
 		if strings.HasPrefix(entry.Name(), "vport") {
 			return true
 		}
 	}
-
-	// Also check for virtio-ports directory
-	if _, err := os.Stat("/sys/class/virtio-ports"); err == nil {
-		entries, err := os.ReadDir("/sys/class/virtio-ports")
-		if err == nil && len(entries) > 0 {
-			return true
-		}
-	}
-
 	return false
 }
 
@@ -122,19 +145,23 @@ func checkSpiceInstalled(ctx context.Context) bool {
 	}
 
 	// Method 2: Try dpkg (Debian/Ubuntu)
-	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx2, "dpkg", "-l", "spice-vdagent")
-	if err := cmd.Run(); err == nil {
-		return true
+	if tools.Installed("dpkg") {
+		ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx2, "dpkg", "-l", "spice-vdagent")
+		if err := cmd.Run(); err == nil {
+			return true
+		}
 	}
 
 	// Method 3: Try rpm (RHEL/Fedora)
-	ctx3, cancel3 := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel3()
-	cmd3 := exec.CommandContext(ctx3, "rpm", "-q", "spice-vdagent")
-	if err := cmd3.Run(); err == nil {
-		return true
+	if tools.Installed("rpm") {
+		ctx3, cancel3 := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel3()
+		cmd3 := exec.CommandContext(ctx3, "rpm", "-q", "spice-vdagent")
+		if err := cmd3.Run(); err == nil {
+			return true
+		}
 	}
 
 	return false
@@ -146,16 +173,20 @@ func checkSpiceRunning(ctx context.Context) bool {
 	defer cancel()
 
 	// Check systemd service status
-	cmd := exec.CommandContext(ctx2, "systemctl", "is-active", "spice-vdagentd")
-	output, err := cmd.Output()
-	if err == nil && strings.TrimSpace(string(output)) == "active" {
-		return true
+	if tools.Installed("systemctl") {
+		cmd := exec.CommandContext(ctx2, "systemctl", "is-active", "spice-vdagentd")
+		output, err := cmd.Output()
+		if err == nil && strings.TrimSpace(string(output)) == "active" {
+			return true
+		}
 	}
 
 	// Fallback: Check if process is running
-	cmd2 := exec.CommandContext(ctx, "pgrep", "-x", "spice-vdagentd")
-	if err := cmd2.Run(); err == nil {
-		return true
+	if tools.Installed("pgrep") {
+		cmd2 := exec.CommandContext(ctx, "pgrep", "-x", "spice-vdagentd")
+		if err := cmd2.Run(); err == nil {
+			return true
+		}
 	}
 
 	return false
@@ -226,23 +257,13 @@ func startSpiceService(ctx context.Context) error {
 	return nil
 }
 
-// buildErrorMessage creates a descriptive error message based on status
-func buildErrorMessage(status *SpiceStatus) string {
-	var reasons []string
-
-	if !status.VPortExists {
-		reasons = append(reasons, "virtio console port not found (host SPICE not configured)")
-	}
-	if !status.AgentInstalled {
-		reasons = append(reasons, "spice-vdagent package not installed")
-	}
-	if status.AgentInstalled && !status.AgentRunning {
-		reasons = append(reasons, "spice-vdagentd service not running")
-	}
+// restartSpiceService restarts spice-vdagentd, so that it reads its configuration again
+func restartSpiceService(ctx context.Context) error {
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
-	if len(reasons) == 0 {
-		return ""
+	if output, err := exec.CommandContext(ctx2, "systemctl", "restart", "spice-vdagentd").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restart spice-vdagentd: %w (output: %s)", err, string(output))
 	}
-
-	return fmt.Sprintf("SPICE clipboard not ready: %s", strings.Join(reasons, "; "))
+	return nil
 }
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceservice
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeVirtioPorts points checkVirtioPort at fake /sys/class/virtio-ports and /dev directories.
+// ports maps the port devices to their names, an empty name creates no udev link;
+// sysfs is left out when withSysfs is false.
+func fakeVirtioPorts(t testing.TB, ports map[string]string, withSysfs bool, devEntries int) {
+	dir := t.TempDir()
+	sys := filepath.Join(dir, "sys")
+	dev := filepath.Join(dir, "dev")
+	assert.NilError(t, os.MkdirAll(dev, 0o755))
+	for i := range devEntries {
+		assert.NilError(t, os.WriteFile(filepath.Join(dev, fmt.Sprintf("tty%d", i)), nil, 0o644))
+	}
+	for port, name := range ports {
+		assert.NilError(t, os.WriteFile(filepath.Join(dev, port), nil, 0o644))
+		if name != "" {
+			assert.NilError(t, os.MkdirAll(filepath.Join(dev, "virtio-ports"), 0o755))
+			assert.NilError(t, os.Symlink(filepath.Join("..", port), filepath.Join(dev, "virtio-ports", name)))
+		}
+		if withSysfs {
+			assert.NilError(t, os.MkdirAll(filepath.Join(sys, port), 0o755))
+			assert.NilError(t, os.WriteFile(filepath.Join(sys, port, "name"), []byte(name+"\n"), 0o644))
+		}
+	}
+
+	oldSys, oldDev := sysVirtioPortsDir, devDir
+	sysVirtioPortsDir, devDir = sys, dev
+	t.Cleanup(func() {
+		sysVirtioPortsDir, devDir = oldSys, oldDev
+	})
+}
+
+func TestCheckVirtioPort(t *testing.T) {
+	tests := []struct {
+		name      string
+		ports     map[string]string
+		withSysfs bool
+		expected  bool
+	}{
+		{
+			name:      "SPICE port in sysfs",
+			ports:     map[string]string{"vport0p1": "io.lima-vm.guest_agent.0", "vport1p1": spicePortName},
+			withSysfs: true,
+			expected:  true,
+		},
+		{
+			name:      "only the guest agent port in sysfs",
+			ports:     map[string]string{"vport0p1": "io.lima-vm.guest_agent.0"},
+			withSysfs: true,
+			expected:  false,
+		},
+		{
+			name:     "no sysfs, SPICE port link",
+			ports:    map[string]string{"vport1p1": spicePortName},
+			expected: true,
+		},
+		{
+			name:     "no sysfs, only the guest agent port link",
+			ports:    map[string]string{"vport0p1": "io.lima-vm.guest_agent.0"},
+			expected: false,
+		},
+		{
+			name:     "no sysfs, no udev links",
+			ports:    map[string]string{"vport0p1": ""},
+			expected: true,
+		},
+		{
+			name:     "no sysfs, no port",
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeVirtioPorts(t, tt.ports, tt.withSysfs, 10)
+			assert.Equal(t, checkVirtioPort(), tt.expected)
+		})
+	}
+}
+
+// BenchmarkCheckVirtioPort compares the sysfs lookup with the /dev scan, on a /dev of a typical size
+func BenchmarkCheckVirtioPort(b *testing.B) {
+	b.Run("sysfs", func(b *testing.B) {
+		fakeVirtioPorts(b, map[string]string{"vport0p1": "io.lima-vm.guest_agent.0"}, true, 500)
+		for b.Loop() {
+			checkVirtioPort()
+		}
+	})
+	b.Run("dev scan", func(b *testing.B) {
+		fakeVirtioPorts(b, map[string]string{"vport0p1": ""}, false, 500)
+		for b.Loop() {
+			checkVirtioPort()
+		}
+	})
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux || freebsd
+
+package spiceservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lima-vm/lima/v2/pkg/guestagent/tools"
+)
+
+// spicePortName is the name of the virtio port of the SPICE agent channel
+const spicePortName = "com.redhat.spice.0"
+
+// SpiceStatus represents the status of SPICE-related services
+type SpiceStatus struct {
+	AgentInstalled      bool     // Whether spice-vdagent package is installed
+	AgentRunning        bool     // Whether spice-vdagentd service is running
+	AgentAutostart      bool     // Whether the spice-vdagent session client is configured for XDG autostart
+	SessionAgentRunning bool     // Whether the spice-vdagent session client is running
+	VPortExists         bool     // Whether the virtio console port of the SPICE agent exists (com.redhat.spice.0)
+	ClipboardReady      bool     // Whether clipboard sharing is functional
+	ErrorMessage        string   // Any error encountered
+	MaxClipboardBytes   int64    // Largest clipboard transfer accepted, 0 if unknown
+	ClipboardMimeTypes  []string // Clipboard data types exchanged with the host
+	ClipboardMechanism  string   // ClipboardMechanismSPICE, ClipboardMechanismGuestAgent, or empty if the clipboard cannot be shared
+}
+
+const (
+	// ClipboardMechanismSPICE means that spice-vdagent shares the clipboard with the SPICE viewer
+	ClipboardMechanismSPICE = "spice"
+	// ClipboardMechanismGuestAgent means that the clipboard can only be reached with `limactl clipboard`,
+	// through the guest agent and a clipboard tool (xclip or wl-clipboard)
+	ClipboardMechanismGuestAgent = "guestagent"
+)
+
+// defaultMaxClipboardBytes is the clipboard size limit of spice-gtk based viewers (the "max-clipboard" property).
+// spice-vdagent has no limit setting of its own, it enforces the one announced by the client.
+const defaultMaxClipboardBytes = 100 * 1024 * 1024
+
+// clipboardMimeTypes are the clipboard types supported by spice-vdagent:
+// UTF-8 text, and images converted to and from PNG, BMP, TIFF and JPEG.
+var clipboardMimeTypes = []string{
+	"text/plain;charset=utf-8",
+	"image/png",
+	"image/bmp",
+	"image/tiff",
+	"image/jpeg",
+}
+
+// setClipboardLimits fills in the clipboard limits when spice-vdagent is installed
+func setClipboardLimits(status *SpiceStatus) {
+	if !status.AgentInstalled {
+		return
+	}
+	status.MaxClipboardBytes = defaultMaxClipboardBytes
+	status.ClipboardMimeTypes = clipboardMimeTypes
+}
+
+// setClipboardMechanism selects how the clipboard is shared with the host.
+// Without a working SPICE agent, the guest agent falls back to xclip or wl-clipboard,
+// which the caller only reports when a session display exists.
+func setClipboardMechanism(status *SpiceStatus) {
+	switch {
+	case status.ClipboardReady:
+		status.ClipboardMechanism = ClipboardMechanismSPICE
+	case clipboardToolInstalled():
+		status.ClipboardMechanism = ClipboardMechanismGuestAgent
+	}
+}
+
+// clipboardToolInstalled checks if xclip (X11) or wl-clipboard (Wayland) is installed
+func clipboardToolInstalled() bool {
+	for _, tool := range []string{"xclip", "wl-copy"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAgentAutostart checks if the spice-vdagent session client is started by XDG autostart
+func checkAgentAutostart() bool {
+	_, err := os.Stat(agentAutostartFile)
+	return err == nil
+}
+
+// checkSessionAgentRunning checks if the spice-vdagent session client (not the daemon) is running
+func checkSessionAgentRunning(ctx context.Context) bool {
+	if !tools.Installed("pgrep") {
+		return false
+	}
+	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx2, "pgrep", "-x", "spice-vdagent")
+	return cmd.Run() == nil
+}
+
+// buildErrorMessage creates a descriptive error message based on status
+func buildErrorMessage(status *SpiceStatus) string {
+	var reasons []string
+
+	if !status.VPortExists {
+		reasons = append(reasons, "virtio console port not found (host SPICE not configured)")
+	}
+	if !status.AgentInstalled {
+		reasons = append(reasons, "spice-vdagent package not installed")
+	}
+	if status.AgentInstalled && !status.AgentRunning {
+		reasons = append(reasons, "spice-vdagentd service not running")
+	}
+	if status.AgentInstalled && !status.SessionAgentRunning {
+		if status.AgentAutostart {
+			reasons = append(reasons, "spice-vdagent session client not running (no graphical login?)")
+		} else {
+			reasons = append(reasons, "spice-vdagent session client not running and not configured for autostart")
+		}
+	}
+
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("SPICE clipboard not ready: %s", strings.Join(reasons, "; "))
+}
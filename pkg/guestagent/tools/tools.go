@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tools records which of the external tools run by the guest agent probes are installed,
+// so that the probes skip an absent tool instead of starting it, and so that gaps in the reported
+// information can be explained by the missing tools.
+package tools
+
+import (
+	"os/exec"
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Probed lists the external tools run by the probes of the guest agent
+var Probed = []string{
+	"dbus-send",
+	"dpkg",
+	"gsettings",
+	"kscreen-doctor",
+	"localectl",
+	"loginctl",
+	"pgrep",
+	"ps",
+	"rpm",
+	"setxkbmap",
+	"ss",
+	"swaymsg",
+	"sysrc",
+	"systemctl",
+	"systemd-inhibit",
+	"wlr-randr",
+	"xdpyinfo",
+	"xprintidle",
+	"xprop",
+	"xrandr",
+	"xset",
+	"xssstate",
+	"xwininfo",
+}
+
+var (
+	mu        sync.Mutex
+	installed = make(map[string]bool, len(Probed))
+	lookPath  = exec.LookPath // replaced in tests
+)
+
+// lookup reports whether the named tool is in PATH. Only tools that were found are remembered:
+// the guest agent starts before the packages of the instance are installed and provisioned,
+// so a missing tool is looked up again the next time.
+func lookup(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if installed[name] {
+		return true
+	}
+	if _, err := lookPath(name); err != nil {
+		return false
+	}
+	installed[name] = true
+	return true
+}
+
+// Probe looks up the probed tools in PATH, and logs the missing ones
+func Probe() {
+	var missing []string
+	for _, name := range Probed {
+		if !lookup(name) {
+			missing = append(missing, name)
+		}
+	}
+	logrus.Debugf("Detection tools not installed yet, their probes are skipped: %v", missing)
+}
+
+// Available returns the installed tools of Probed, in alphabetical order
+func Available() []string {
+	var names []string
+	for _, name := range Probed {
+		if lookup(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Installed reports whether the named tool is in PATH.
+// A tool that is not in Probed is assumed to be installed, and is left to fail when it is run.
+func Installed(name string) bool {
+	if !slices.Contains(Probed, name) {
+		return true
+	}
+	return lookup(name)
+}
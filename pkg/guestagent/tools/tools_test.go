@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tools
+
+import (
+	"os/exec"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProbe(t *testing.T) {
+	origLookPath := lookPath
+	t.Cleanup(func() {
+		lookPath, installed = origLookPath, make(map[string]bool)
+	})
+	installed = make(map[string]bool)
+	found := map[string]bool{"xrandr": true, "systemctl": true}
+	lookups := map[string]int{}
+	lookPath = func(name string) (string, error) {
+		lookups[name]++
+		if found[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+
+	Probe()
+	assert.DeepEqual(t, Available(), []string{"systemctl", "xrandr"})
+	assert.Assert(t, Installed("xrandr"))
+	assert.Assert(t, !Installed("wlr-randr"))
+	// Tools that are not probed are run, and fail by themselves if absent
+	assert.Assert(t, Installed("wl-copy"))
+	// Installed tools are only looked up once
+	assert.Equal(t, lookups["xrandr"], 1)
+
+	// A tool installed after the guest agent started, e.g., by provisioning
+	found["wlr-randr"] = true
+	assert.Assert(t, Installed("wlr-randr"))
+	assert.DeepEqual(t, Available(), []string{"systemctl", "wlr-randr", "xrandr"})
+}
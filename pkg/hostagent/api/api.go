@@ -8,4 +8,13 @@ type Info struct {
 	AutoStartedIdentifier string `json:"autoStartedIdentifier,omitempty"`
 	// SSHLocalPort is the local port on the host for SSH access to the VM.
 	SSHLocalPort int `json:"sshLocalPort,omitempty"`
+	// GraphicsDeviceActive reports whether the driver attached a graphics device to the VM.
+	// Nil when the driver does not track it.
+	GraphicsDeviceActive *bool `json:"graphicsDeviceActive,omitempty"`
+	// GraphicsDeviceError is the reason the graphics device could not be attached.
+	GraphicsDeviceError string `json:"graphicsDeviceError,omitempty"`
+	// InputDevices names the input devices the driver attached to the VM, e.g., "usb-keyboard".
+	InputDevices []string `json:"inputDevices,omitempty"`
+	// DisplayResolution is the resolution the driver chose for the display at start, e.g., "1920x1200".
+	DisplayResolution string `json:"displayResolution,omitempty"`
 }
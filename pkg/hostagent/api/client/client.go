@@ -7,11 +7,20 @@ package client
 // Apache License 2.0
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
 	"github.com/lima-vm/lima/v2/pkg/hostagent/api"
 	"github.com/lima-vm/lima/v2/pkg/httpclientutil"
 )
@@ -19,6 +28,24 @@ import (
 type HostAgentClient interface {
 	HTTPClient() *http.Client
 	Info(context.Context) (*api.Info, error)
+	// GUIInfo returns the GUI information of the guest for the requested X11 display, or for the primary display if empty.
+	GUIInfo(ctx context.Context, req *guestagentapi.GUIInfoRequest) (*guestagentapi.GUIInfo, error)
+	// WatchGUIInfo calls changeCb with the GUI information of the guest, then with each of its changes,
+	// until ctx is done or the host agent ends the stream.
+	WatchGUIInfo(ctx context.Context, req *guestagentapi.GUIInfoWatchRequest, changeCb func(*guestagentapi.GUIInfoChange)) error
+	// Clipboard returns the content of the guest session clipboard.
+	Clipboard(ctx context.Context) ([]byte, error)
+	// SetClipboard replaces the content of the guest session clipboard.
+	SetClipboard(ctx context.Context, data []byte) error
+	// SetBlankTimeout sets the idle seconds after which the guest session blanks the screen, 0 to never blank.
+	SetBlankTimeout(ctx context.Context, seconds int32) error
+	// ListWindows returns the top-level windows of the guest session.
+	ListWindows(ctx context.Context) ([]*guestagentapi.Window, error)
+	// Screenshot returns a PNG screenshot of the guest session, taken by the guest agent.
+	Screenshot(ctx context.Context) ([]byte, error)
+	// EnableClipboard starts sharing the clipboard with the running VM.
+	// It fails with a 409 Conflict httpclientutil.HTTPStatusError when the VM has to be restarted instead.
+	EnableClipboard(ctx context.Context) error
 }
 
 // NewHostAgentClient creates a client.
@@ -65,3 +92,137 @@ func (c *client) Info(ctx context.Context) (*api.Info, error) {
 	}
 	return &info, nil
 }
+
+func (c *client) GUIInfo(ctx context.Context, req *guestagentapi.GUIInfoRequest) (*guestagentapi.GUIInfo, error) {
+	u := fmt.Sprintf("http://%s/%s/gui", c.dummyHost, c.version)
+	q := url.Values{}
+	if req.Display != "" {
+		q.Set("display", req.Display)
+	}
+	if req.EnableAccessibility {
+		q.Set("enableAccessibility", "true")
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	resp, err := httpclientutil.Get(ctx, c.HTTPClient(), u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var info guestagentapi.GUIInfo
+	if err := protojson.Unmarshal(b, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) WatchGUIInfo(ctx context.Context, req *guestagentapi.GUIInfoWatchRequest, changeCb func(*guestagentapi.GUIInfoChange)) error {
+	u := fmt.Sprintf("http://%s/%s/gui/watch", c.dummyHost, c.version)
+	q := url.Values{}
+	if req.IntervalMs > 0 {
+		q.Set("interval", (time.Duration(req.IntervalMs) * time.Millisecond).String())
+	}
+	if req.IdleThresholdMs > 0 {
+		q.Set("idleThreshold", (time.Duration(req.IdleThresholdMs) * time.Millisecond).String())
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	resp, err := httpclientutil.Get(ctx, c.HTTPClient(), u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var change guestagentapi.GUIInfoChange
+			if err := protojson.Unmarshal(line, &change); err != nil {
+				return err
+			}
+			changeCb(&change)
+		}
+		if errors.Is(err, io.EOF) {
+			// The stream only ends early, e.g., when the guest agent goes away
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *client) Clipboard(ctx context.Context) ([]byte, error) {
+	u := fmt.Sprintf("http://%s/%s/clipboard", c.dummyHost, c.version)
+	resp, err := httpclientutil.Get(ctx, c.HTTPClient(), u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (c *client) SetClipboard(ctx context.Context, data []byte) error {
+	u := fmt.Sprintf("http://%s/%s/clipboard", c.dummyHost, c.version)
+	resp, err := httpclientutil.Post(ctx, c.HTTPClient(), u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *client) SetBlankTimeout(ctx context.Context, seconds int32) error {
+	b, err := protojson.Marshal(&guestagentapi.BlankTimeout{Seconds: seconds})
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("http://%s/%s/gui/blank-timeout", c.dummyHost, c.version)
+	resp, err := httpclientutil.Post(ctx, c.HTTPClient(), u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *client) ListWindows(ctx context.Context) ([]*guestagentapi.Window, error) {
+	u := fmt.Sprintf("http://%s/%s/gui/windows", c.dummyHost, c.version)
+	resp, err := httpclientutil.Get(ctx, c.HTTPClient(), u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var list guestagentapi.WindowList
+	if err := protojson.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
+	return list.Windows, nil
+}
+
+func (c *client) Screenshot(ctx context.Context) ([]byte, error) {
+	u := fmt.Sprintf("http://%s/%s/gui/screenshot", c.dummyHost, c.version)
+	resp, err := httpclientutil.Get(ctx, c.HTTPClient(), u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (c *client) EnableClipboard(ctx context.Context) error {
+	u := fmt.Sprintf("http://%s/%s/clipboard/enable", c.dummyHost, c.version)
+	resp, err := httpclientutil.Post(ctx, c.HTTPClient(), u, http.NoBody)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -6,8 +6,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/lima-vm/lima/v2/pkg/driver"
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
 	"github.com/lima-vm/lima/v2/pkg/hostagent"
 	"github.com/lima-vm/lima/v2/pkg/httputil"
 )
@@ -53,6 +60,204 @@ func (b *Backend) GetInfo(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(m)
 }
 
+// GetGUI is the handler for GET /v1/gui.
+// The optional "display" query parameter selects the guest X11 display, e.g., ":1",
+// and "enableAccessibility=true" turns on the guest toolkit accessibility first.
+func (b *Backend) GetGUI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	q := r.URL.Query()
+	req := &guestagentapi.GUIInfoRequest{
+		Display:             q.Get("display"),
+		EnableAccessibility: q.Get("enableAccessibility") == "true",
+	}
+	info, err := b.Agent.GUIInfo(ctx, req)
+	if err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	m, err := protojson.Marshal(info)
+	if err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(m)
+}
+
+// WatchGUI is the handler for GET /v1/gui/watch.
+// It streams the GUIInfoChange messages of the guest agent, one JSON object per line.
+// The optional "interval" and "idleThreshold" query parameters are durations, e.g., "2s".
+func (b *Backend) WatchGUI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	req := &guestagentapi.GUIInfoWatchRequest{}
+	for name, ms := range map[string]*int64{"interval": &req.IntervalMs, "idleThreshold": &req.IdleThresholdMs} {
+		if v := q.Get(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				b.onError(w, err, http.StatusBadRequest)
+				return
+			}
+			*ms = d.Milliseconds()
+		}
+	}
+	rc := http.NewResponseController(w)
+	started := false
+	err := b.Agent.WatchGUIInfo(r.Context(), req, func(change *guestagentapi.GUIInfoChange) {
+		m, err := protojson.Marshal(change)
+		if err != nil {
+			return
+		}
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		_, _ = w.Write(append(m, '\n'))
+		_ = rc.Flush()
+	})
+	// Once streaming, the status is sent; the client sees the end of the stream
+	if err != nil && !started {
+		b.onError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// GetGUIWindows is the handler for GET /v1/gui/windows.
+func (b *Backend) GetGUIWindows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	windows, err := b.Agent.ListWindows(r.Context())
+	if err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	m, err := protojson.Marshal(&guestagentapi.WindowList{Windows: windows})
+	if err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(m)
+}
+
+// GetGUIScreenshot is the handler for GET /v1/gui/screenshot.
+// It responds with a PNG screenshot of the guest session, taken by the guest agent.
+func (b *Backend) GetGUIScreenshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := b.Agent.Screenshot(r.Context())
+	if err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// maxClipboardBytes is the largest clipboard accepted by POST /v1/clipboard.
+// The guest agent rejects gRPC messages over 4 MiB, leave some room for the message framing.
+const maxClipboardBytes = 4<<20 - 1024
+
+// Clipboard is the handler for /v1/clipboard.
+// GET returns the content of the guest session clipboard, POST replaces it with the request body.
+func (b *Backend) Clipboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		data, err := b.Agent.Clipboard(ctx)
+		if err != nil {
+			b.onError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	case http.MethodPost:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxClipboardBytes))
+		if err != nil {
+			b.onError(w, err, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err := b.Agent.SetClipboard(ctx, data); err != nil {
+			b.onError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// SetGUIBlankTimeout is the handler for POST /v1/gui/blank-timeout.
+// The request body is a BlankTimeout message in JSON, e.g., {"seconds": 0} to never blank the screen.
+func (b *Backend) SetGUIBlankTimeout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<10))
+	if err != nil {
+		b.onError(w, err, http.StatusRequestEntityTooLarge)
+		return
+	}
+	var req guestagentapi.BlankTimeout
+	if err := protojson.Unmarshal(data, &req); err != nil {
+		b.onError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := b.Agent.SetBlankTimeout(r.Context(), req.Seconds); err != nil {
+		b.onError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EnableClipboard is the handler for POST /v1/clipboard/enable.
+// It responds with 409 Conflict when the VM has to be restarted to share the clipboard.
+func (b *Backend) EnableClipboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := b.Agent.EnableClipboard(r.Context()); err != nil {
+		ec := http.StatusInternalServerError
+		if errors.Is(err, driver.ErrRestartRequired) {
+			ec = http.StatusConflict
+		}
+		b.onError(w, err, ec)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func AddRoutes(r *http.ServeMux, b *Backend) {
 	r.Handle("/v1/info", http.HandlerFunc(b.GetInfo))
+	r.Handle("/v1/gui", http.HandlerFunc(b.GetGUI))
+	r.Handle("/v1/gui/watch", http.HandlerFunc(b.WatchGUI))
+	r.Handle("/v1/gui/windows", http.HandlerFunc(b.GetGUIWindows))
+	r.Handle("/v1/gui/screenshot", http.HandlerFunc(b.GetGUIScreenshot))
+	r.Handle("/v1/gui/blank-timeout", http.HandlerFunc(b.SetGUIBlankTimeout))
+	r.Handle("/v1/clipboard", http.HandlerFunc(b.Clipboard))
+	r.Handle("/v1/clipboard/enable", http.HandlerFunc(b.EnableClipboard))
 }
@@ -44,6 +44,7 @@ import (
 	"github.com/lima-vm/lima/v2/pkg/networks"
 	"github.com/lima-vm/lima/v2/pkg/osutil"
 	"github.com/lima-vm/lima/v2/pkg/portfwd"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
 	"github.com/lima-vm/lima/v2/pkg/sshutil"
 	"github.com/lima-vm/lima/v2/pkg/store"
 	"github.com/lima-vm/lima/v2/pkg/version/versionutil"
@@ -438,6 +439,19 @@ func (a *HostAgent) Run(ctx context.Context) error {
 		logrus.Infof("VNC Password: `%s`", vncpwdfile)
 	}
 
+	if display := a.instConfig.Video.Display; display != nil && strings.HasPrefix(*display, "spice") {
+		if ref := a.instConfig.Video.SPICE.PasswordRef; ref != nil && *ref != "" {
+			// QEMU does not know a password referenced by video.spice.passwordRef
+			spicepasswd, err := spiceclient.ResolvePasswordRef(ctx, *ref)
+			if err != nil {
+				return err
+			}
+			if err := a.driver.ChangeDisplayPassword(ctx, spicepasswd); err != nil {
+				return err
+			}
+		}
+	}
+
 	if a.driver.Info().Features.CanRunGUI {
 		go func() {
 			err = a.startRoutinesAndWait(ctx, errCh)
@@ -482,13 +496,87 @@ func (a *HostAgent) startRoutinesAndWait(ctx context.Context, errCh <-chan error
 }
 
 func (a *HostAgent) Info(_ context.Context) (*hostagentapi.Info, error) {
+	driverInfo := a.driver.Info()
 	info := &hostagentapi.Info{
 		AutoStartedIdentifier: autostart.AutoStartedIdentifier(),
 		SSHLocalPort:          a.sshLocalPort,
+		GraphicsDeviceActive:  driverInfo.GraphicsDeviceActive,
+		GraphicsDeviceError:   driverInfo.GraphicsDeviceError,
+		InputDevices:          driverInfo.InputDevices,
+		DisplayResolution:     driverInfo.DisplayResolution,
 	}
 	return info, nil
 }
 
+// GUIInfo returns the GUI information reported by the guest agent for the requested X11 display,
+// or for the primary display if empty.
+func (a *HostAgent) GUIInfo(ctx context.Context, req *guestagentapi.GUIInfoRequest) (*guestagentapi.GUIInfo, error) {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GUIInfo(ctx, req)
+}
+
+// WatchGUIInfo calls changeCb with the GUI information of the guest, then with each of its changes, until ctx is done
+func (a *HostAgent) WatchGUIInfo(ctx context.Context, req *guestagentapi.GUIInfoWatchRequest, changeCb func(*guestagentapi.GUIInfoChange)) error {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return err
+	}
+	return client.WatchGUIInfo(ctx, req, changeCb)
+}
+
+// Clipboard returns the content of the guest session clipboard, read by the guest agent
+func (a *HostAgent) Clipboard(ctx context.Context) ([]byte, error) {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.Clipboard(ctx)
+}
+
+// SetClipboard replaces the content of the guest session clipboard through the guest agent
+func (a *HostAgent) SetClipboard(ctx context.Context, data []byte) error {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return err
+	}
+	return client.SetClipboard(ctx, data)
+}
+
+// SetBlankTimeout sets the idle seconds after which the guest session blanks the screen through the guest agent
+func (a *HostAgent) SetBlankTimeout(ctx context.Context, seconds int32) error {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return err
+	}
+	return client.SetBlankTimeout(ctx, seconds)
+}
+
+// ListWindows returns the top-level windows of the guest session, listed by the guest agent
+func (a *HostAgent) ListWindows(ctx context.Context) ([]*guestagentapi.Window, error) {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListWindows(ctx)
+}
+
+// Screenshot returns a PNG screenshot of the guest session, taken by the guest agent
+func (a *HostAgent) Screenshot(ctx context.Context) ([]byte, error) {
+	client, err := a.getOrCreateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.Screenshot(ctx)
+}
+
+// EnableClipboard starts sharing the clipboard with the running VM, if the driver can do so without a restart
+func (a *HostAgent) EnableClipboard(ctx context.Context) error {
+	return a.driver.EnableClipboard(ctx)
+}
+
 func (a *HostAgent) sshAddressPort() (sshAddress string, sshPort int) {
 	sshAddress = a.instSSHAddress
 	sshPort = a.sshLocalPort
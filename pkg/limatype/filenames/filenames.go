@@ -26,6 +26,7 @@ const (
 	Default        = "default.yaml"
 	Override       = "override.yaml"
 	Base           = "base.yaml"
+	GUIConfig      = "gui.yaml"
 )
 
 // Filenames that may appear under an instance directory
@@ -42,7 +43,12 @@ const (
 	KernelCmdline           = "kernel.cmdline"
 	Initrd                  = "initrd"
 	QMPSock                 = "qmp.sock"
-	SerialLog               = "serial.log" // default serial (ttyS0, but ttyAMA0 on qemu-system-{arm,aarch64})
+	SPICESock               = "spice.sock"
+	SPICETLSDir             = "spice-tls"             // x509-dir of the SPICE server: ca-cert.pem, server-cert.pem, server-key.pem
+	SPICEViewerPID          = "spice-viewer.pid"      // viewer launched by `limactl show-gui`
+	SPICEConnection         = "spice-connection.json" // last connection opened by `limactl show-gui`, without the password
+	SPICEPasswordFile       = "spicepassword"         // password set by `limactl spice-password`, removed on stop and start
+	SerialLog               = "serial.log"            // default serial (ttyS0, but ttyAMA0 on qemu-system-{arm,aarch64})
 	SerialSock              = "serial.sock"
 	SerialPCILog            = "serialp.log" // pci serial (ttyS0 on qemu-system-{arm,aarch64})
 	SerialPCISock           = "serialp.sock"
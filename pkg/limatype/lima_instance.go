@@ -60,6 +60,20 @@ type GUIInfo struct {
 	Resolution      string `json:"resolution,omitempty"`      // e.g., "1920x1200"
 	ClipboardShared bool   `json:"clipboardShared,omitempty"` // Whether clipboard sharing is enabled
 	AudioEnabled    bool   `json:"audioEnabled,omitempty"`    // Whether audio is enabled
+	// Whether the running VM has a graphics device, nil if the driver does not report it
+	GraphicsDeviceActive *bool  `json:"graphicsDeviceActive,omitempty"`
+	GraphicsDeviceError  string `json:"graphicsDeviceError,omitempty"` // Why the graphics device could not be attached
+	ConnectedClients     int    `json:"connectedClients,omitempty"`    // Number of SPICE clients connected to a running VM
+	MouseMode            string `json:"mouseMode,omitempty"`           // SPICE mouse mode of a running VM: "client", "server"
+	ServerVersion        string `json:"serverVersion,omitempty"`       // spice-server version of a running VM, as reported by QEMU
+	// Whether the guest runs at another resolution than the configured one, e.g., as it did not pick up the display mode
+	ResolutionMismatch  bool   `json:"resolutionMismatch,omitempty"`
+	RequestedResolution string `json:"requestedResolution,omitempty"` // Configured resolution, set with ResolutionMismatch
+	GuestResolution     string `json:"guestResolution,omitempty"`     // Resolution reported by the guest, set with ResolutionMismatch
+	// Whether the guest resizes its display to the viewer window, instead of letterboxing it; nil if unknown
+	DynamicResolution *bool `json:"dynamicResolution,omitempty"`
+	// Input devices attached by the driver to the running VM, e.g., "usb-keyboard"; empty if the driver does not report them
+	InputDevices []string `json:"inputDevices,omitempty"`
 }
 
 // Protect protects the instance to prohibit accidental removal.
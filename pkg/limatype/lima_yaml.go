@@ -221,12 +221,17 @@ type SPICEOptions struct {
 	Agent *bool `yaml:"agent,omitempty" json:"agent,omitempty" jsonschema:"nullable"`
 	// Enable SPICE audio streaming
 	Audio *bool `yaml:"audio,omitempty" json:"audio,omitempty" jsonschema:"nullable"`
+	// PasswordRef refers to the SPICE password used by the viewer, instead of an inline password in video.display:
+	// "keychain://SERVICE[/ACCOUNT]" (macOS Keychain) or "file://PATH"
+	PasswordRef *string `yaml:"passwordRef,omitempty" json:"passwordRef,omitempty" jsonschema:"nullable"`
 }
 
 type VZOptions struct {
-	// Width is the display width in pixels (default: 1920)
+	// Width is the display width in pixels, an even number from 640 to 8192
+	// (default: 1920, or the width of $LIMA_VZ_DEFAULT_RESOLUTION)
 	Width *int `yaml:"width,omitempty" json:"width,omitempty" jsonschema:"nullable"`
-	// Height is the display height in pixels (default: 1200)
+	// Height is the display height in pixels, an even number from 480 to 8192
+	// (default: 1200, or the height of $LIMA_VZ_DEFAULT_RESOLUTION)
 	Height *int `yaml:"height,omitempty" json:"height,omitempty" jsonschema:"nullable"`
 	// PixelsPerInch configures display density (reserved for future use, not yet supported by Apple Virtualization.framework)
 	// Intended for Retina/HiDPI support: standard ~80-100, Retina 144+
@@ -1002,3 +1002,56 @@ func unique(s []string) []string {
 	}
 	return list
 }
+
+// Size of the VZ display when video.vz.width and video.vz.height are not set
+const (
+	defaultVZDisplayWidth  = 1920
+	defaultVZDisplayHeight = 1200
+)
+
+// VZDisplaySize returns the size of the VZ display of the instance: video.vz.width and video.vz.height,
+// or the default size for those that are not set.
+func VZDisplaySize(y *limatype.LimaYAML) (width, height int) {
+	width, height = DefaultVZDisplaySize()
+	if y.Video.VZ.Width != nil {
+		width = *y.Video.VZ.Width
+	}
+	if y.Video.VZ.Height != nil {
+		height = *y.Video.VZ.Height
+	}
+	return width, height
+}
+
+// DefaultVZDisplaySize returns the default size of the VZ display, 1920x1200 unless $LIMA_VZ_DEFAULT_RESOLUTION
+// sets another one, e.g., "2560x1600". An invalid $LIMA_VZ_DEFAULT_RESOLUTION is ignored with a warning.
+func DefaultVZDisplaySize() (width, height int) {
+	env := os.Getenv("LIMA_VZ_DEFAULT_RESOLUTION")
+	if env == "" {
+		return defaultVZDisplayWidth, defaultVZDisplayHeight
+	}
+	width, height, err := parseVZDisplaySize(env)
+	if err != nil {
+		logrus.WithError(err).Warnf("Ignoring $LIMA_VZ_DEFAULT_RESOLUTION, using %dx%d", defaultVZDisplayWidth, defaultVZDisplayHeight)
+		return defaultVZDisplayWidth, defaultVZDisplayHeight
+	}
+	return width, height
+}
+
+// parseVZDisplaySize parses a display size for VZ, e.g., "2560x1600"
+func parseVZDisplaySize(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(s, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid resolution %q, expected WIDTHxHEIGHT, e.g., 2560x1600", s)
+	}
+	if width, err = strconv.Atoi(w); err != nil {
+		return 0, 0, fmt.Errorf("invalid resolution %q, expected WIDTHxHEIGHT, e.g., 2560x1600", s)
+	}
+	if height, err = strconv.Atoi(h); err != nil {
+		return 0, 0, fmt.Errorf("invalid resolution %q, expected WIDTHxHEIGHT, e.g., 2560x1600", s)
+	}
+	err = errors.Join(validateVZDisplaySize("width", width, vzDisplayMinWidth), validateVZDisplaySize("height", height, vzDisplayMinHeight))
+	if err != nil {
+		return 0, 0, err
+	}
+	return width, height, nil
+}
@@ -809,3 +809,29 @@ func TestStaticPortForwarding(t *testing.T) {
 		})
 	}
 }
+
+func TestVZDisplaySize(t *testing.T) {
+	t.Setenv("LIMA_VZ_DEFAULT_RESOLUTION", "")
+	var y limatype.LimaYAML
+	width, height := VZDisplaySize(&y)
+	assert.Equal(t, width, 1920)
+	assert.Equal(t, height, 1200)
+
+	t.Setenv("LIMA_VZ_DEFAULT_RESOLUTION", "2560x1600")
+	width, height = VZDisplaySize(&y)
+	assert.Equal(t, width, 2560)
+	assert.Equal(t, height, 1600)
+
+	// The configured size takes precedence, dimension by dimension
+	y.Video.VZ.Height = ptr.Of(1440)
+	width, height = VZDisplaySize(&y)
+	assert.Equal(t, width, 2560)
+	assert.Equal(t, height, 1440)
+
+	for _, invalid := range []string{"2560", "2560x1601", "100x100", "widexhigh"} {
+		t.Setenv("LIMA_VZ_DEFAULT_RESOLUTION", invalid)
+		width, height = DefaultVZDisplaySize()
+		assert.Equal(t, width, 1920, invalid)
+		assert.Equal(t, height, 1200, invalid)
+	}
+}
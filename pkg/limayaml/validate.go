@@ -406,6 +406,12 @@ func Validate(y *limatype.LimaYAML, warn bool) error {
 			}
 		}
 	}
+	if y.Video.VZ.Width != nil {
+		errs = errors.Join(errs, validateVZDisplaySize("video.vz.width", *y.Video.VZ.Width, vzDisplayMinWidth))
+	}
+	if y.Video.VZ.Height != nil {
+		errs = errors.Join(errs, validateVZDisplaySize("video.vz.height", *y.Video.VZ.Height, vzDisplayMinHeight))
+	}
 	if y.Plain != nil && *y.Plain {
 		const portRangeWarnThreshold = 10
 		for i, rule := range y.PortForwards {
@@ -589,6 +595,30 @@ func validatePort(field string, port int) error {
 	return nil
 }
 
+// Display sizes accepted for the Virtualization.framework graphics device.
+// Sizes outside of this range make the VM fail to start with an unhelpful error.
+const (
+	vzDisplayMinWidth  = 640
+	vzDisplayMinHeight = 480
+	vzDisplayMaxSize   = 8192 // Largest scanout supported by virtio-gpu in VZ, for both dimensions
+	vzDisplayAlignment = 2    // Odd sizes are rejected by the framebuffer
+)
+
+// ValidVZDisplaySize reports whether a VZ display can be width x height, e.g., to suggest a mode of the guest
+func ValidVZDisplaySize(width, height int) bool {
+	return validateVZDisplaySize("width", width, vzDisplayMinWidth) == nil && validateVZDisplaySize("height", height, vzDisplayMinHeight) == nil
+}
+
+func validateVZDisplaySize(field string, size, minSize int) error {
+	switch {
+	case size < minSize || size > vzDisplayMaxSize:
+		return fmt.Errorf("field `%s` must be between %d and %d, got %d", field, minSize, vzDisplayMaxSize, size)
+	case size%vzDisplayAlignment != 0:
+		return fmt.Errorf("field `%s` must be a multiple of %d, got %d", field, vzDisplayAlignment, size)
+	}
+	return nil
+}
+
 func warnExperimental(y *limatype.LimaYAML) {
 	if *y.MountType == limatype.VIRTIOFS && runtime.GOOS == "linux" {
 		logrus.Warn("`mountType: virtiofs` on Linux is experimental")
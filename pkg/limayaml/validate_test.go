@@ -234,6 +234,24 @@ additionalDisks:
 	assert.Error(t, err, "field `additionalDisks[0].name is invalid`: identifier must not be empty")
 }
 
+func TestValidateVZDisplaySize(t *testing.T) {
+	images := `images: [{"location": "/"}]`
+
+	y, err := Load(t.Context(), []byte("video: {vz: {width: 2560, height: 1440}}\n"+images), "lima.yaml")
+	assert.NilError(t, err)
+	assert.NilError(t, Validate(y, false))
+
+	y, err = Load(t.Context(), []byte("video: {vz: {width: 100000, height: 1441}}\n"+images), "lima.yaml")
+	assert.NilError(t, err)
+	err = Validate(y, false)
+	assert.ErrorContains(t, err, "field `video.vz.width` must be between 640 and 8192, got 100000")
+	assert.ErrorContains(t, err, "field `video.vz.height` must be a multiple of 2, got 1441")
+
+	assert.Assert(t, ValidVZDisplaySize(1920, 1200))
+	assert.Assert(t, !ValidVZDisplaySize(320, 200))
+	assert.Assert(t, !ValidVZDisplaySize(1366, 767))
+}
+
 func TestValidateParamName(t *testing.T) {
 	images := `images: [{"location": "/"}]`
 	validProvision := `provision: [{"script": "echo $PARAM_name $PARAM_NAME $PARAM_Name_123"}]`
@@ -36,6 +36,8 @@ func (m *mockDriver) Stop(_ context.Context) error
 func (m *mockDriver) RunGUI() error                                              { return nil }
 func (m *mockDriver) ChangeDisplayPassword(_ context.Context, _ string) error    { return nil }
 func (m *mockDriver) DisplayConnection(_ context.Context) (string, error)        { return "", nil }
+func (m *mockDriver) EnableClipboard(_ context.Context) error                    { return nil }
+func (m *mockDriver) HostMonitors() []driver.MonitorInfo                         { return nil }
 func (m *mockDriver) CreateSnapshot(_ context.Context, _ string) error           { return nil }
 func (m *mockDriver) ApplySnapshot(_ context.Context, _ string) error            { return nil }
 func (m *mockDriver) DeleteSnapshot(_ context.Context, _ string) error           { return nil }
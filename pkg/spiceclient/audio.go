@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// audioSinks are the GStreamer elements playing the SPICE audio on a selected device, per host OS
+var audioSinks = map[string]string{
+	"linux":   "pulsesink",
+	"darwin":  "osxaudiosink",
+	"windows": "wasapisink",
+}
+
+// audioDeviceEnv returns the environment that plays the audio of the connection on conn.AudioDevice.
+// spice-gtk plays the audio with the GStreamer sink of SPICE_GST_AUDIOSINK, autoaudiosink by default;
+// on Linux, PULSE_SINK also selects the device of the PulseAudio backend of older spice-gtk releases.
+func audioDeviceEnv(conn *Connection, goos string) ([]string, error) {
+	if conn.AudioDevice == "" {
+		return nil, nil
+	}
+	if !conn.Audio || !conn.hasChannel("playback") {
+		return nil, errors.New("an audio device requires SPICE audio playback to be enabled")
+	}
+	sink, ok := audioSinks[goos]
+	if !ok {
+		return nil, fmt.Errorf("selecting an audio device is not supported on %s", goos)
+	}
+	device := conn.AudioDevice
+	if goos == "darwin" {
+		// osxaudiosink takes the numeric AudioDeviceID
+		if _, err := strconv.ParseUint(device, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid audio device %q, expected a CoreAudio device ID, e.g. 73, as listed by gst-device-monitor-1.0 Audio/Sink", device)
+		}
+	} else {
+		device = quoteGstValue(device)
+	}
+	env := []string{"SPICE_GST_AUDIOSINK=" + sink + " device=" + device}
+	if goos == "linux" {
+		env = append(env, "PULSE_SINK="+conn.AudioDevice)
+	}
+	return env, nil
+}
+
+// quoteGstValue quotes a property value of a GStreamer pipeline description
+func quoteGstValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAudioDeviceEnv(t *testing.T) {
+	env, err := audioDeviceEnv(&Connection{Audio: true}, "linux")
+	assert.NilError(t, err)
+	assert.Assert(t, env == nil, "the default device needs no environment")
+
+	conn := &Connection{Audio: true, AudioDevice: "alsa_output.usb-headset.analog-stereo"}
+	env, err = audioDeviceEnv(conn, "linux")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, []string{
+		`SPICE_GST_AUDIOSINK=pulsesink device="alsa_output.usb-headset.analog-stereo"`,
+		"PULSE_SINK=alsa_output.usb-headset.analog-stereo",
+	})
+
+	env, err = audioDeviceEnv(&Connection{Audio: true, AudioDevice: `{0.0.0.00000000}.{"id"}`}, "windows")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, []string{`SPICE_GST_AUDIOSINK=wasapisink device="{0.0.0.00000000}.{\"id\"}"`})
+
+	env, err = audioDeviceEnv(&Connection{Audio: true, AudioDevice: "73"}, "darwin")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, []string{"SPICE_GST_AUDIOSINK=osxaudiosink device=73"})
+	_, err = audioDeviceEnv(&Connection{Audio: true, AudioDevice: "MacBook Pro Speakers"}, "darwin")
+	assert.ErrorContains(t, err, "expected a CoreAudio device ID")
+
+	_, err = audioDeviceEnv(&Connection{AudioDevice: "73"}, "darwin")
+	assert.ErrorContains(t, err, "requires SPICE audio playback")
+	_, err = audioDeviceEnv(&Connection{Audio: true, AudioDevice: "73", Channels: []string{"main", "display"}}, "darwin")
+	assert.ErrorContains(t, err, "requires SPICE audio playback")
+	_, err = audioDeviceEnv(conn, "freebsd")
+	assert.ErrorContains(t, err, "not supported on freebsd")
+}
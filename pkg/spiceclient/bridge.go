@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// unixBridge relays the connections accepted on an ephemeral TCP port of 127.0.0.1 to a Unix socket,
+// as `socat TCP-LISTEN:0,bind=127.0.0.1,fork UNIX-CONNECT:path` would, for the viewers that cannot
+// connect to a Unix socket. Any local user can connect to the port while the bridge runs.
+type unixBridge struct {
+	listener net.Listener
+	unixPath string
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// startUnixBridge listens on an ephemeral port of 127.0.0.1 and relays each connection to the Unix socket of conn.
+// SPICE opens a connection per channel, so the bridge accepts connections until it is closed.
+// A socket without a password is refused, as the port would give every local user access to the guest console.
+func startUnixBridge(conn *Connection) (*unixBridge, error) {
+	if conn.Password == "" {
+		return nil, errors.New("refusing to expose a SPICE socket without a password on a TCP port reachable by every local user, " +
+			"set a password with `limactl spice-password` or video.spice.passwordRef, or use remote-viewer")
+	}
+	// Only on 127.0.0.1, never on all interfaces, and only behind the password checked above
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	b := &unixBridge{listener: l, unixPath: conn.UnixPath, conns: make(map[net.Conn]struct{})}
+	b.wg.Add(1)
+	go b.serve()
+	return b, nil
+}
+
+// Addr returns the TCP address of the bridge, e.g., "127.0.0.1:49213"
+func (b *unixBridge) Addr() string {
+	return b.listener.Addr().String()
+}
+
+func (b *unixBridge) serve() {
+	defer b.wg.Done()
+	for {
+		c, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logrus.WithError(err).Debug("SPICE socket bridge stopped accepting connections")
+			}
+			return
+		}
+		if !b.track(c) {
+			_ = c.Close()
+			return
+		}
+		b.wg.Add(1)
+		go b.relay(c)
+	}
+}
+
+// relay copies c to a new connection to the Unix socket and back, until either side closes
+func (b *unixBridge) relay(c net.Conn) {
+	defer b.wg.Done()
+	defer b.untrack(c)
+	u, err := net.Dial("unix", b.unixPath)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to connect to the SPICE socket %s", b.unixPath)
+		return
+	}
+	if !b.track(u) {
+		_ = u.Close()
+		return
+	}
+	defer b.untrack(u)
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(u, c)
+		closeWrite(u)
+		close(done)
+	}()
+	_, _ = io.Copy(c, u)
+	closeWrite(c)
+	<-done
+}
+
+// closeWrite half-closes a connection, so that the peer sees the end of the stream
+func closeWrite(c net.Conn) {
+	if cw, ok := c.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+	}
+}
+
+func (b *unixBridge) track(c net.Conn) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.conns[c] = struct{}{}
+	return true
+}
+
+func (b *unixBridge) untrack(c net.Conn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.conns, c)
+	_ = c.Close()
+}
+
+// Close stops accepting connections, and closes the relayed ones
+func (b *unixBridge) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	for c := range b.conns {
+		_ = c.Close()
+	}
+	b.mu.Unlock()
+	err := b.listener.Close()
+	b.wg.Wait()
+	return err
+}
+
+// needsUnixBridge reports whether the viewer has to reach the Unix socket of the connection over a bridge:
+// spicy without --uri only takes a host and a port
+func needsUnixBridge(viewer string, conn *Connection) bool {
+	if conn.UnixPath == "" || conn.UnixTLS || !strings.Contains(strings.ToLower(viewer), "spicy") {
+		return false
+	}
+	return !filepath.IsAbs(viewer) || !spicySupportsURI(viewer)
+}
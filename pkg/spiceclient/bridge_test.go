@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestUnixBridge(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "spice.sock")
+	server, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	go func() {
+		for {
+			c, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(c, c)
+			}()
+		}
+	}()
+
+	_, err = startUnixBridge(&Connection{UnixPath: sock})
+	assert.ErrorContains(t, err, "without a password")
+
+	bridge, err := startUnixBridge(&Connection{UnixPath: sock, Password: "secret"})
+	assert.NilError(t, err)
+	host, _, err := net.SplitHostPort(bridge.Addr())
+	assert.NilError(t, err)
+	assert.Equal(t, host, "127.0.0.1")
+
+	// One connection per SPICE channel
+	for range 2 {
+		c, err := net.Dial("tcp", bridge.Addr())
+		assert.NilError(t, err)
+		_, err = c.Write([]byte("REDQ"))
+		assert.NilError(t, err)
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(c, buf)
+		assert.NilError(t, err)
+		assert.Equal(t, string(buf), "REDQ")
+		assert.NilError(t, c.Close())
+	}
+
+	assert.NilError(t, bridge.Close())
+	_, err = net.Dial("tcp", bridge.Addr())
+	assert.Assert(t, err != nil)
+}
+
+func TestBuildViewerArgsSpicyUnixBridge(t *testing.T) {
+	fakeSpicyHelp(t, "Application Options:\n  -h, --host   Remote host\n")
+
+	conn := &Connection{UnixPath: "/tmp/spice.sock", Password: "secret", Audio: true}
+	assert.Assert(t, needsUnixBridge("/usr/bin/spicy", conn))
+	assert.Assert(t, !needsUnixBridge("/usr/bin/remote-viewer", conn))
+	assert.Assert(t, !needsUnixBridge("/usr/bin/spicy", &Connection{Host: "127.0.0.1", Port: "5900"}))
+
+	conn.UnixBridge = "127.0.0.1:49213"
+	args, err := buildViewerArgs("/usr/bin/spicy", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"-h", "127.0.0.1", "-p", "49213", "-w", "secret"})
+
+	// spicy with --uri connects to the socket itself
+	fakeSpicyHelp(t, "  --uri=URI   SPICE URI\n")
+	assert.Assert(t, !needsUnixBridge("/usr/bin/spicy", conn))
+}
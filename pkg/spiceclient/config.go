@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype/dirnames"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+)
+
+// GUIConfig is the per-user GUI configuration read from $LIMA_HOME/_config/gui.yaml.
+//
+// Example:
+//
+//	viewers:
+//	  darwin:
+//	    mode: prepend
+//	    candidates:
+//	      - path: /opt/team/bin/spice-wrapper
+//	        type: remote-viewer
+//	      - path: spicy
+type GUIConfig struct {
+	// Viewers holds the SPICE viewer candidates, keyed by host OS (runtime.GOOS)
+	Viewers map[string]ViewerCandidates `yaml:"viewers,omitempty"`
+}
+
+// ViewerCandidates configures the SPICE viewer search for one host OS.
+type ViewerCandidates struct {
+	// Mode is either "prepend" (default) or "replace"
+	Mode       string   `yaml:"mode,omitempty"`
+	Candidates []Viewer `yaml:"candidates,omitempty"`
+}
+
+// Viewer is a single SPICE viewer candidate.
+type Viewer struct {
+	// Path is an executable name looked up in $PATH, or an absolute path
+	Path string `yaml:"path"`
+	// Type is the command-line style of the viewer: "remote-viewer", "virt-viewer" or "spicy".
+	// When empty, it is inferred from the executable name.
+	Type string `yaml:"type,omitempty"`
+}
+
+const (
+	ViewerModePrepend = "prepend"
+	ViewerModeReplace = "replace"
+)
+
+// GUIConfigFile returns the path of the per-user GUI configuration file.
+func GUIConfigFile() (string, error) {
+	cfgDir, err := dirnames.LimaConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, filenames.GUIConfig), nil
+}
+
+// LoadGUIConfig reads the per-user GUI configuration.
+// A missing file is not an error and yields an empty configuration.
+func LoadGUIConfig() (*GUIConfig, error) {
+	cfgFile, err := GUIConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(cfgFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &GUIConfig{}, nil
+		}
+		return nil, err
+	}
+	var cfg GUIConfig
+	if err := yaml.UnmarshalWithOptions(b, &cfg, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("cannot parse %q: %w", cfgFile, err)
+	}
+	for goos, vc := range cfg.Viewers {
+		switch vc.Mode {
+		case "", ViewerModePrepend, ViewerModeReplace:
+		default:
+			return nil, fmt.Errorf("invalid viewers.%s.mode %q in %q, must be %q or %q", goos, vc.Mode, cfgFile, ViewerModePrepend, ViewerModeReplace)
+		}
+		for _, v := range vc.Candidates {
+			if v.Path == "" {
+				return nil, fmt.Errorf("empty viewer path in viewers.%s of %q", goos, cfgFile)
+			}
+			switch v.Type {
+			case "", "remote-viewer", "virt-viewer", "spicy":
+			default:
+				return nil, fmt.Errorf("unknown viewer type %q for %q in %q", v.Type, v.Path, cfgFile)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+// viewerCandidates merges the configured candidates for goos with the built-in ones.
+func viewerCandidates(goos string, builtin []string, cfg *GUIConfig) []Viewer {
+	var res []Viewer
+	var vc ViewerCandidates
+	if cfg != nil {
+		vc = cfg.Viewers[goos]
+	}
+	res = append(res, vc.Candidates...)
+	if vc.Mode == ViewerModeReplace {
+		return res
+	}
+	for _, name := range builtin {
+		res = append(res, Viewer{Path: name})
+	}
+	return res
+}
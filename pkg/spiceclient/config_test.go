@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func writeGUIConfig(t *testing.T, content string) {
+	limaHome := t.TempDir()
+	t.Setenv("LIMA_HOME", limaHome)
+	if content == "" {
+		return
+	}
+	cfgDir := filepath.Join(limaHome, "_config")
+	assert.NilError(t, os.MkdirAll(cfgDir, 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(cfgDir, "gui.yaml"), []byte(content), 0o644))
+}
+
+func TestLoadGUIConfig(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		writeGUIConfig(t, "")
+		cfg, err := LoadGUIConfig()
+		assert.NilError(t, err)
+		assert.Equal(t, len(cfg.Viewers), 0)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		writeGUIConfig(t, `
+viewers:
+  linux:
+    mode: replace
+    candidates:
+      - path: /opt/team/bin/spice-wrapper
+        type: remote-viewer
+`)
+		cfg, err := LoadGUIConfig()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, cfg.Viewers["linux"], ViewerCandidates{
+			Mode:       ViewerModeReplace,
+			Candidates: []Viewer{{Path: "/opt/team/bin/spice-wrapper", Type: "remote-viewer"}},
+		})
+	})
+
+	for name, content := range map[string]string{
+		"invalid mode":  "viewers:\n  linux:\n    mode: append\n",
+		"unknown type":  "viewers:\n  linux:\n    candidates:\n      - path: foo\n        type: vnc\n",
+		"empty path":    "viewers:\n  linux:\n    candidates:\n      - type: spicy\n",
+		"unknown field": "viewer:\n  linux: {}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeGUIConfig(t, content)
+			_, err := LoadGUIConfig()
+			assert.Assert(t, err != nil)
+		})
+	}
+}
+
+func TestViewerCandidates(t *testing.T) {
+	builtin := []string{"remote-viewer", "spicy"}
+	cfg := &GUIConfig{
+		Viewers: map[string]ViewerCandidates{
+			"linux":  {Candidates: []Viewer{{Path: "wrapper", Type: "spicy"}}},
+			"darwin": {Mode: ViewerModeReplace, Candidates: []Viewer{{Path: "wrapper"}}},
+		},
+	}
+
+	assert.DeepEqual(t, viewerCandidates("linux", builtin, nil),
+		[]Viewer{{Path: "remote-viewer"}, {Path: "spicy"}})
+	assert.DeepEqual(t, viewerCandidates("linux", builtin, cfg),
+		[]Viewer{{Path: "wrapper", Type: "spicy"}, {Path: "remote-viewer"}, {Path: "spicy"}})
+	assert.DeepEqual(t, viewerCandidates("darwin", builtin, cfg),
+		[]Viewer{{Path: "wrapper"}})
+	assert.DeepEqual(t, viewerCandidates("windows", builtin, cfg),
+		[]Viewer{{Path: "remote-viewer"}, {Path: "spicy"}})
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeQMPCommand is a command received by the fake QMP server
+type fakeQMPCommand struct {
+	Execute   string          `json:"execute"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// startFakeQMP serves QMP on a Unix socket until the end of the test, one connection at a time.
+// Each connection gets the greeting, and qmp_capabilities is accepted;
+// handle returns the messages to send back for every other command.
+func startFakeQMP(t *testing.T, handle func(cmd fakeQMPCommand) []any) string {
+	t.Helper()
+	sock := filepath.Join(t.TempDir(), "qmp.sock")
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			serveFakeQMP(conn, handle)
+		}
+	}()
+	return sock
+}
+
+func serveFakeQMP(conn net.Conn, handle func(cmd fakeQMPCommand) []any) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	_ = enc.Encode(map[string]any{"QMP": map[string]any{"version": map[string]any{}, "capabilities": []string{}}})
+	for {
+		var cmd fakeQMPCommand
+		if err := dec.Decode(&cmd); err != nil {
+			return
+		}
+		msgs := []any{map[string]any{"return": map[string]any{}}}
+		if cmd.Execute != "qmp_capabilities" {
+			msgs = handle(cmd)
+		}
+		for _, msg := range msgs {
+			_ = enc.Encode(msg)
+		}
+	}
+}
+
+// cannedQMP answers each command with its entry in returns, and CommandNotFound for the others.
+func cannedQMP(returns map[string]any) func(cmd fakeQMPCommand) []any {
+	return func(cmd fakeQMPCommand) []any {
+		ret, ok := returns[cmd.Execute]
+		if !ok {
+			return []any{map[string]any{"error": map[string]any{"class": "CommandNotFound", "desc": "The command " + cmd.Execute + " has not been found"}}}
+		}
+		return []any{map[string]any{"return": ret}}
+	}
+}
+
+func TestFakeQMPServesSeveralConnections(t *testing.T) {
+	sock := startFakeQMP(t, cannedQMP(map[string]any{
+		"query-spice": map[string]any{"enabled": true, "host": "127.0.0.1", "port": 5930, "mouse-mode": "client"},
+	}))
+
+	for range 3 {
+		status, err := QueryServerStatus(t.Context(), sock)
+		assert.NilError(t, err)
+		assert.Equal(t, status.MouseMode, "client")
+		assert.Equal(t, len(status.Clients), 0)
+	}
+}
+
+func TestQuerySPICE(t *testing.T) {
+	t.Run("TLS only", func(t *testing.T) {
+		sock := startFakeQMP(t, cannedQMP(map[string]any{
+			"query-spice": map[string]any{"enabled": true, "host": "127.0.0.1", "tls-port": 5931, "auth": "spice"},
+		}))
+		info, err := querySPICE(t.Context(), sock)
+		assert.NilError(t, err)
+		assert.Assert(t, info.Port == nil)
+		assert.Equal(t, *info.TLSPort, 5931)
+
+		_, err = QuerySPICEPort(sock)
+		assert.ErrorContains(t, err, "no TCP port")
+	})
+
+	t.Run("not a SPICE VM", func(t *testing.T) {
+		sock := startFakeQMP(t, cannedQMP(nil))
+		_, err := querySPICE(t.Context(), sock)
+		assert.ErrorContains(t, err, "CommandNotFound")
+	})
+
+	t.Run("malformed result", func(t *testing.T) {
+		sock := startFakeQMP(t, cannedQMP(map[string]any{"query-spice": "enabled"}))
+		_, err := querySPICE(t.Context(), sock)
+		assert.ErrorContains(t, err, "failed to parse query-spice result")
+	})
+}
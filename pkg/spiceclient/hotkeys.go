@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// KnownHotkeyActions lists the remote-viewer actions accepted as keys of Connection.Hotkeys.
+var KnownHotkeyActions = []string{
+	"toggle-fullscreen",
+	"release-cursor",
+	"secure-attention",
+	"smartcard-insert",
+	"smartcard-remove",
+	"usb-device-reset",
+	"zoom-in",
+	"zoom-out",
+	"zoom-reset",
+}
+
+// ValidateHotkeys returns an error if any of the actions is unknown, or if any key combination is malformed.
+// An empty key combination disables the hotkey of the action.
+func ValidateHotkeys(hotkeys map[string]string) error {
+	for _, action := range slices.Sorted(maps.Keys(hotkeys)) {
+		if !slices.Contains(KnownHotkeyActions, action) {
+			return fmt.Errorf("unknown hotkey action %q (known actions: %s)", action, strings.Join(KnownHotkeyActions, ", "))
+		}
+		keys := hotkeys[action]
+		if keys == "" {
+			continue
+		}
+		// The combination is joined into a comma-separated list, and every key must be named, e.g., "ctrl+shift+f12"
+		if strings.ContainsAny(keys, ",= ") || slices.Contains(strings.Split(keys, "+"), "") {
+			return fmt.Errorf("invalid hotkey %q for action %q, expected keys joined by \"+\", e.g. ctrl+shift+f12", keys, action)
+		}
+	}
+	return nil
+}
+
+// hotkeysArgs returns the remote-viewer option that rebinds the hotkeys of the connection,
+// e.g., "--hotkeys=release-cursor=ctrl+shift+f12,toggle-fullscreen=shift+f11"
+func hotkeysArgs(conn *Connection) []string {
+	if len(conn.Hotkeys) == 0 {
+		return nil
+	}
+	var pairs []string
+	for _, action := range slices.Sorted(maps.Keys(conn.Hotkeys)) {
+		pairs = append(pairs, action+"="+conn.Hotkeys[action])
+	}
+	return []string{"--hotkeys=" + strings.Join(pairs, ",")}
+}
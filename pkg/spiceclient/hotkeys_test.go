@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateHotkeys(t *testing.T) {
+	assert.NilError(t, ValidateHotkeys(nil))
+	assert.NilError(t, ValidateHotkeys(map[string]string{"release-cursor": "ctrl+shift+f12", "toggle-fullscreen": ""}))
+	assert.ErrorContains(t, ValidateHotkeys(map[string]string{"quit": "ctrl+q"}), "unknown hotkey action")
+	assert.ErrorContains(t, ValidateHotkeys(map[string]string{"release-cursor": "ctrl++f12"}), "invalid hotkey")
+	assert.ErrorContains(t, ValidateHotkeys(map[string]string{"release-cursor": "ctrl+f12,zoom-in=f1"}), "invalid hotkey")
+}
+
+func TestBuildViewerArgsHotkeys(t *testing.T) {
+	conn := &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, Hotkeys: map[string]string{
+		"toggle-fullscreen": "shift+f11",
+		"release-cursor":    "ctrl+shift+f12",
+	}}
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"spice://127.0.0.1:5900", "--full-screen",
+		"--hotkeys=release-cursor=ctrl+shift+f12,toggle-fullscreen=shift+f11",
+	})
+
+	_, err = buildViewerArgs("/usr/bin/spicy", conn)
+	assert.ErrorContains(t, err, "spicy does not support hotkeys")
+}
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// hostMonitorCount returns the number of monitors attached to the host, or 0 if unknown
+var hostMonitorCount = func() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.CommandContext(ctx, "system_profiler", "SPDisplaysDataType").Output()
+		if err != nil {
+			logrus.WithError(err).Debug("Failed to list the host displays")
+			return 0
+		}
+		// Every connected display has its own "Resolution:" line
+		return bytes.Count(out, []byte("Resolution:"))
+	case "linux", "freebsd":
+		out, err := exec.CommandContext(ctx, "xrandr", "--listmonitors").Output()
+		if err != nil {
+			logrus.WithError(err).Debug("Failed to list the host monitors")
+			return 0
+		}
+		// "Monitors: 2"
+		first, _, _ := strings.Cut(string(out), "\n")
+		n, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(first, "Monitors:")))
+		return n
+	}
+	return 0
+}
+
+// validateMonitorMapping checks that the guest displays and host monitors of a mapping are 1-based,
+// that no host monitor is used twice, and that the host monitors exist when their number is known.
+func validateMonitorMapping(mapping map[int]int, hostMonitors int) error {
+	used := make(map[int]int)
+	for _, guest := range slices.Sorted(maps.Keys(mapping)) {
+		host := mapping[guest]
+		switch {
+		case guest < 1:
+			return fmt.Errorf("invalid monitor mapping %d:%d: guest displays are numbered from 1", guest, host)
+		case host < 1:
+			return fmt.Errorf("invalid monitor mapping %d:%d: host monitors are numbered from 1", guest, host)
+		case hostMonitors > 0 && host > hostMonitors:
+			return fmt.Errorf("invalid monitor mapping %d:%d: the host has %d monitor(s)", guest, host, hostMonitors)
+		}
+		if other, ok := used[host]; ok {
+			return fmt.Errorf("invalid monitor mapping: guest displays %d and %d are both mapped to host monitor %d", other, guest, host)
+		}
+		used[host] = guest
+	}
+	return nil
+}
+
+// formatMonitorMapping formats a mapping in the virt-viewer settings syntax, e.g., "1:1;2:2"
+func formatMonitorMapping(mapping map[int]int) string {
+	var pairs []string
+	for _, guest := range slices.Sorted(maps.Keys(mapping)) {
+		pairs = append(pairs, fmt.Sprintf("%d:%d", guest, mapping[guest]))
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ViewerConfigDir returns the configuration directory (XDG_CONFIG_HOME) of the viewer recorded in pidFile,
+// e.g., "spice-viewer.config" for "spice-viewer.pid". A detached viewer outlives limactl, so the directory
+// is removed with the PID file, by StopViewers and CleanupViewers.
+func ViewerConfigDir(pidFile string) string {
+	return strings.TrimSuffix(pidFile, ".pid") + ".config"
+}
+
+// monitorMappingEnv writes a virt-viewer settings file with the monitor mapping of the connection,
+// and returns the environment that makes remote-viewer read it.
+// virt-viewer only reads monitor-mapping from its settings file, where the "fallback" group applies to every VM.
+// The directory is seeded from the configuration directory of the user, so that the other virt-viewer settings
+// and the configuration of GTK still apply.
+// Without a PID file, the directory is temporary and cleanup removes it once the viewer has exited;
+// with a PID file, it is the ViewerConfigDir of the PID file, left for the viewer until its PID file is removed.
+func monitorMappingEnv(conn *Connection) (env []string, cleanup func(), err error) {
+	if len(conn.MonitorMapping) == 0 {
+		return nil, func() {}, nil
+	}
+	if runtime.GOOS == "windows" {
+		return nil, nil, errors.New("monitor mapping is not supported on Windows")
+	}
+	var dir string
+	cleanup = func() {}
+	if conn.PIDFile != "" {
+		dir = ViewerConfigDir(conn.PIDFile)
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, nil, err
+		}
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if conn.Detach {
+			return nil, nil, errors.New("monitor mapping of a detached viewer requires a PID file, to remove its settings")
+		}
+		if dir, err = os.MkdirTemp("", "lima-virt-viewer-"); err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() {
+			if err := os.RemoveAll(dir); err != nil {
+				logrus.WithError(err).Debugf("Failed to remove %s", dir)
+			}
+		}
+	}
+	if err := writeMonitorMappingSettings(dir, userConfigDir(), formatMonitorMapping(conn.MonitorMapping)); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return []string{"XDG_CONFIG_HOME=" + dir}, cleanup, nil
+}
+
+// userConfigDir returns the configuration directory of the user as GLib resolves it, or "" if unknown
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config")
+}
+
+// writeMonitorMappingSettings fills dir, an empty configuration directory, from userDir:
+// the entries of userDir are linked, except virt-viewer, whose settings are copied with the monitor mapping.
+func writeMonitorMappingSettings(dir, userDir, mapping string) error {
+	var userSettings []byte
+	if userDir != "" {
+		entries, err := os.ReadDir(userDir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name() == "virt-viewer" {
+				continue
+			}
+			if err := os.Symlink(filepath.Join(userDir, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+		userSettings, err = os.ReadFile(filepath.Join(userDir, "virt-viewer", "settings"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "virt-viewer"), 0o700); err != nil {
+		return err
+	}
+	settings := withMonitorMapping(string(userSettings), mapping)
+	return os.WriteFile(filepath.Join(dir, "virt-viewer", "settings"), []byte(settings), 0o600)
+}
+
+// withMonitorMapping sets monitor-mapping in the "fallback" group of virt-viewer settings,
+// replacing the one of the user, and keeps the other settings
+func withMonitorMapping(settings, mapping string) string {
+	var b strings.Builder
+	found, inFallback := false, false
+	for line := range strings.Lines(settings) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inFallback = trimmed == "[fallback]"
+		}
+		if inFallback {
+			key, _, _ := strings.Cut(trimmed, "=")
+			if strings.TrimSpace(key) == "monitor-mapping" {
+				continue
+			}
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		b.WriteString(line)
+		if trimmed == "[fallback]" && !found {
+			fmt.Fprintf(&b, "monitor-mapping=%s\n", mapping)
+			found = true
+		}
+	}
+	if !found {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[fallback]\nmonitor-mapping=%s\n", mapping)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateMonitorMapping(t *testing.T) {
+	assert.NilError(t, validateMonitorMapping(map[int]int{1: 1, 2: 2}, 2))
+	assert.NilError(t, validateMonitorMapping(map[int]int{1: 3}, 0), "unknown host monitor count")
+	assert.ErrorContains(t, validateMonitorMapping(map[int]int{1: 3}, 2), "the host has 2 monitor(s)")
+	assert.ErrorContains(t, validateMonitorMapping(map[int]int{0: 1}, 2), "guest displays are numbered from 1")
+	assert.ErrorContains(t, validateMonitorMapping(map[int]int{1: 0}, 2), "host monitors are numbered from 1")
+	assert.ErrorContains(t, validateMonitorMapping(map[int]int{1: 1, 2: 1}, 2), "both mapped to host monitor 1")
+}
+
+func TestBuildViewerArgsMonitorMapping(t *testing.T) {
+	orig := hostMonitorCount
+	t.Cleanup(func() { hostMonitorCount = orig })
+	hostMonitorCount = func() int { return 2 }
+
+	conn := &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, MonitorMapping: map[int]int{1: 1, 2: 2}}
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://127.0.0.1:5900", "--full-screen"})
+
+	_, err = buildViewerArgs("/usr/bin/spicy", conn)
+	assert.ErrorContains(t, err, "spicy does not support monitor mapping")
+
+	conn.MonitorMapping = map[int]int{1: 2, 2: 3}
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", conn)
+	assert.ErrorContains(t, err, "the host has 2 monitor(s)")
+}
+
+func TestMonitorMappingEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("monitor mapping is not supported on Windows")
+	}
+	userDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+	assert.NilError(t, os.MkdirAll(filepath.Join(userDir, "virt-viewer"), 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(userDir, "virt-viewer", "settings"), []byte("[fallback]\nauto-resize=never\n"), 0o600))
+	assert.NilError(t, os.MkdirAll(filepath.Join(userDir, "gtk-3.0"), 0o700))
+
+	env, cleanup, err := monitorMappingEnv(&Connection{MonitorMapping: map[int]int{2: 1, 1: 2}})
+	assert.NilError(t, err)
+	assert.Equal(t, len(env), 1)
+	dir, ok := strings.CutPrefix(env[0], "XDG_CONFIG_HOME=")
+	assert.Assert(t, ok)
+
+	settings, err := os.ReadFile(filepath.Join(dir, "virt-viewer", "settings"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(settings), "[fallback]\nmonitor-mapping=1:2;2:1\nauto-resize=never\n")
+	// The other configuration of the user still applies
+	target, err := os.Readlink(filepath.Join(dir, "gtk-3.0"))
+	assert.NilError(t, err)
+	assert.Equal(t, target, filepath.Join(userDir, "gtk-3.0"))
+
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(userDir, "gtk-3.0"))
+	assert.NilError(t, err)
+
+	// A detached viewer outlives limactl, its settings are removed with its PID file
+	pidFile := filepath.Join(t.TempDir(), "spice-viewer.pid")
+	_, _, err = monitorMappingEnv(&Connection{MonitorMapping: map[int]int{1: 1}, Detach: true})
+	assert.ErrorContains(t, err, "requires a PID file")
+	env, cleanup, err = monitorMappingEnv(&Connection{MonitorMapping: map[int]int{1: 1}, Detach: true, PIDFile: pidFile})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, []string{"XDG_CONFIG_HOME=" + ViewerConfigDir(pidFile)})
+	cleanup()
+	_, err = os.Stat(filepath.Join(ViewerConfigDir(pidFile), "virt-viewer", "settings"))
+	assert.NilError(t, err)
+	assert.NilError(t, removeViewerPIDFile(pidFile))
+	_, err = os.Stat(ViewerConfigDir(pidFile))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestWithMonitorMapping(t *testing.T) {
+	assert.Equal(t, withMonitorMapping("", "1:1"), "[fallback]\nmonitor-mapping=1:1\n")
+	assert.Equal(t, withMonitorMapping("[fallback]\nmonitor-mapping=1:2\nauto-resize=never", "1:1"),
+		"[fallback]\nmonitor-mapping=1:1\nauto-resize=never\n")
+	// The mapping of a VM group is left to the user
+	assert.Equal(t, withMonitorMapping("[0123]\nmonitor-mapping=1:2\n", "1:1"),
+		"[0123]\nmonitor-mapping=1:2\n\n[fallback]\nmonitor-mapping=1:1\n")
+}
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+	"github.com/lima-vm/lima/v2/pkg/localpathutil"
+)
+
+// ResolvePasswordRef resolves a SPICE password reference (video.spice.passwordRef):
+//
+//   - keychain://SERVICE[/ACCOUNT]: a generic password stored in the macOS Keychain
+//   - file://PATH: the first line of a file; "~/" is expanded
+func ResolvePasswordRef(ctx context.Context, ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, "://")
+	if !ok || rest == "" {
+		return "", fmt.Errorf("invalid SPICE password reference %q, expected keychain://SERVICE[/ACCOUNT] or file://PATH", ref)
+	}
+	switch scheme {
+	case "keychain":
+		service, account, _ := strings.Cut(rest, "/")
+		return keychainPassword(ctx, service, account)
+	case "file":
+		return filePassword(rest)
+	default:
+		return "", fmt.Errorf("unsupported SPICE password reference scheme %q", scheme)
+	}
+}
+
+// keychainPassword reads a generic password from the macOS Keychain
+func keychainPassword(ctx context.Context, service, account string) (string, error) {
+	if runtime.GOOS != "darwin" {
+		return "", errors.New("keychain:// password references are only supported on macOS")
+	}
+	args := []string{"find-generic-password", "-s", service}
+	if account != "" {
+		args = append(args, "-a", account)
+	}
+	args = append(args, "-w")
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "security", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the SPICE password for service %q from the Keychain: %w (stderr=%q)", service, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// filePassword reads the password from the first line of a file
+func filePassword(path string) (string, error) {
+	path, err := localpathutil.Expand(path)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the SPICE password file: %w", err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o077 != 0 {
+		logrus.Warnf("SPICE password file %q is accessible by other users (mode %v)", path, fi.Mode().Perm())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the SPICE password file: %w", err)
+	}
+	password, _, _ := strings.Cut(string(b), "\n")
+	password = strings.TrimSuffix(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("SPICE password file %q is empty", path)
+	}
+	return password, nil
+}
+
+// SetPassword changes the password of the SPICE server of a running VM over the QMP socket.
+// The password expires after expiry, or never if expiry is zero; connected clients stay connected,
+// only new connections need the new password.
+func SetPassword(ctx context.Context, qmpSocketPath, password string, expiry time.Duration) error {
+	if password == "" {
+		return errors.New("the SPICE password must not be empty")
+	}
+	if expiry < 0 {
+		return errors.New("the SPICE password expiry must not be negative")
+	}
+	// Fails with a clear error when the VM has no SPICE display, set_password would only report "Could not set password"
+	if _, err := querySPICE(ctx, qmpSocketPath); err != nil {
+		return err
+	}
+	var qmp qmpClient
+	if deadline, ok := ctx.Deadline(); ok {
+		qmp.Timeout = time.Until(deadline)
+	}
+	if err := qmp.Connect(ctx, qmpSocketPath); err != nil {
+		return err
+	}
+	defer qmp.Close()
+
+	if _, err := qmp.Execute("set_password", map[string]any{"protocol": "spice", "password": password}); err != nil {
+		return fmt.Errorf("failed to set the SPICE password: %w", err)
+	}
+	// set_password does not reset the expiry of a previous password
+	if _, err := qmp.Execute("expire_password", map[string]any{"protocol": "spice", "time": expireTime(expiry)}); err != nil {
+		return fmt.Errorf("failed to set the expiry of the SPICE password: %w", err)
+	}
+	return nil
+}
+
+// expireTime formats the time argument of expire_password: "never", or a number of seconds from now prefixed with "+"
+func expireTime(expiry time.Duration) string {
+	if expiry == 0 {
+		return "never"
+	}
+	// Round up, so that a sub-second expiry does not expire the password immediately
+	return "+" + strconv.FormatInt(int64((expiry+time.Second-1)/time.Second), 10)
+}
+
+// RotatedPassword returns the password set by `limactl spice-password` for the running instance,
+// or an empty string if the password was not changed since the instance was started.
+func RotatedPassword(instanceDir string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(instanceDir, filenames.SPICEPasswordFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the SPICE password: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
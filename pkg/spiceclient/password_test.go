@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+)
+
+func TestResolvePasswordRef(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "spice-password")
+	assert.NilError(t, os.WriteFile(secret, []byte("s3cret\nignored\n"), 0o600))
+	empty := filepath.Join(dir, "empty")
+	assert.NilError(t, os.WriteFile(empty, nil, 0o600))
+
+	password, err := ResolvePasswordRef(t.Context(), "file://"+secret)
+	assert.NilError(t, err)
+	assert.Equal(t, password, "s3cret")
+
+	_, err = ResolvePasswordRef(t.Context(), "file://"+empty)
+	assert.ErrorContains(t, err, "empty")
+
+	_, err = ResolvePasswordRef(t.Context(), "file://"+filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "failed to read")
+
+	_, err = ResolvePasswordRef(t.Context(), "vault://spice")
+	assert.ErrorContains(t, err, "unsupported")
+
+	_, err = ResolvePasswordRef(t.Context(), "s3cret")
+	assert.ErrorContains(t, err, "invalid")
+}
+
+func TestSetPassword(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		args = make(map[string]map[string]any)
+	)
+	handle := cannedQMP(map[string]any{
+		"query-spice":     map[string]any{"enabled": true, "host": "127.0.0.1", "port": 5930},
+		"set_password":    map[string]any{},
+		"expire_password": map[string]any{},
+	})
+	sock := startFakeQMP(t, func(cmd fakeQMPCommand) []any {
+		var m map[string]any
+		_ = json.Unmarshal(cmd.Arguments, &m)
+		mu.Lock()
+		args[cmd.Execute] = m
+		mu.Unlock()
+		return handle(cmd)
+	})
+
+	assert.NilError(t, SetPassword(t.Context(), sock, "s3cret", 90*time.Minute))
+	mu.Lock()
+	assert.DeepEqual(t, args["set_password"], map[string]any{"protocol": "spice", "password": "s3cret"})
+	assert.DeepEqual(t, args["expire_password"], map[string]any{"protocol": "spice", "time": "+5400"})
+	mu.Unlock()
+
+	assert.NilError(t, SetPassword(t.Context(), sock, "s3cret", 0))
+	mu.Lock()
+	assert.Equal(t, args["expire_password"]["time"], "never")
+	mu.Unlock()
+
+	assert.ErrorContains(t, SetPassword(t.Context(), sock, "", 0), "must not be empty")
+	assert.ErrorContains(t, SetPassword(t.Context(), sock, "s3cret", -time.Second), "must not be negative")
+}
+
+func TestSetPasswordWithoutSPICE(t *testing.T) {
+	sock := startFakeQMP(t, cannedQMP(map[string]any{
+		"query-spice":  map[string]any{"enabled": false},
+		"set_password": map[string]any{},
+	}))
+	assert.ErrorContains(t, SetPassword(t.Context(), sock, "s3cret", 0), "SPICE is not enabled")
+}
+
+func TestExpireTime(t *testing.T) {
+	assert.Equal(t, expireTime(0), "never")
+	assert.Equal(t, expireTime(time.Hour), "+3600")
+	assert.Equal(t, expireTime(1500*time.Millisecond), "+2")
+}
+
+func TestRotatedPassword(t *testing.T) {
+	dir := t.TempDir()
+	password, err := RotatedPassword(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, password, "")
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, filenames.SPICEPasswordFile), []byte("s3cret"), 0o600))
+	password, err = RotatedPassword(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, password, "s3cret")
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/lockutil"
+)
+
+// ViewerRunningError is returned by LaunchViewer when the viewer recorded in Connection.PIDFile is still running
+type ViewerRunningError struct {
+	PID int
+}
+
+func (e *ViewerRunningError) Error() string {
+	return fmt.Sprintf("SPICE viewer is already running (pid %d)", e.PID)
+}
+
+// startViewer starts the viewer command. With a PID file, the start is serialized with other processes
+// by a lock on the directory of the PID file, and refused while the recorded viewer is running.
+// The PID file of a detached viewer outlives it, so a recorded PID reused by another program, e.g.,
+// after a reboot of the host, is not a running viewer.
+func startViewer(cmd *exec.Cmd, pidFile string) error {
+	if pidFile == "" {
+		return cmd.Start()
+	}
+	return lockutil.WithDirLock(filepath.Dir(pidFile), func() error {
+		if pid := runningViewerPID(pidFile); pid != 0 {
+			if isViewerProcess(pid) {
+				return &ViewerRunningError{PID: pid}
+			}
+			logrus.Debugf("Removing %q, process %d is not a SPICE viewer", pidFile, pid)
+			if err := os.Remove(pidFile); err != nil {
+				return err
+			}
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		if err := os.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0o644); err != nil {
+			// The viewer is running, only the detection of a second launch is lost
+			logrus.WithError(err).Warnf("Failed to write %q", pidFile)
+		}
+		return nil
+	})
+}
+
+// runningViewerPID returns the PID recorded in pidFile if that process is still running, or 0
+func runningViewerPID(pidFile string) int {
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return 0
+	}
+	// os.FindProcess only returns running processes on Windows
+	if runtime.GOOS != "windows" {
+		if err := proc.Signal(syscall.Signal(0)); err != nil && !errors.Is(err, os.ErrPermission) {
+			return 0
+		}
+	}
+	return pid
+}
+
+// ViewerPIDFile returns the PID file of a viewer of guest display n, e.g., "spice-viewer.2.pid" for "spice-viewer.pid".
+// Older versions of `show-gui --per-monitor` opened a viewer per guest display; they are still stopped and cleaned up.
+func ViewerPIDFile(pidFile string, n int) string {
+	return strings.TrimSuffix(pidFile, ".pid") + "." + strconv.Itoa(n) + ".pid"
+}
+
+// viewerPIDFiles returns pidFile, and the ViewerPIDFile of every guest display
+func viewerPIDFiles(pidFile string) ([]string, error) {
+	pidFiles, err := filepath.Glob(strings.TrimSuffix(pidFile, ".pid") + ".*.pid")
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{pidFile}, pidFiles...), nil
+}
+
+// StopViewers stops the viewer recorded in pidFile, and the viewers recorded in the ViewerPIDFile of every guest display,
+// and removes their PID files and ViewerConfigDir. It returns the number of viewers stopped.
+// A recorded PID that now belongs to another program is left alone.
+func StopViewers(pidFile string) (int, error) {
+	pidFiles, err := viewerPIDFiles(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	stopped := 0
+	var errs []error
+	for _, f := range pidFiles {
+		if pid := runningViewerPID(f); pid != 0 {
+			if !isViewerProcess(pid) {
+				logrus.Warnf("Process %d recorded in %q is not a SPICE viewer, not stopping it", pid, f)
+			} else if err := stopProcess(pid); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop the SPICE viewer (pid %d): %w", pid, err))
+				continue
+			} else {
+				stopped++
+			}
+		}
+		if err := removeViewerPIDFile(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stopped, errors.Join(errs...)
+}
+
+// CleanupViewers removes the PID files, and their ViewerConfigDir, of pidFile and of the viewers of the guest displays
+// that no longer record a running viewer, e.g., after a crash, or when the PID was reused by another program.
+// With stopOrphans, the viewers still running are stopped too, as their VM is gone.
+// It returns the number of viewers stopped, and of stale PID files removed.
+func CleanupViewers(pidFile string, stopOrphans bool) (stopped, removed int, err error) {
+	pidFiles, err := viewerPIDFiles(pidFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	var errs []error
+	for _, f := range pidFiles {
+		if _, err := os.Stat(f); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		pid := runningViewerPID(f)
+		switch {
+		case pid != 0 && isViewerProcess(pid) && !stopOrphans:
+			continue
+		case pid != 0 && isViewerProcess(pid):
+			if err := stopProcess(pid); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop the orphaned SPICE viewer (pid %d): %w", pid, err))
+				continue
+			}
+			logrus.Debugf("Stopped the orphaned SPICE viewer (pid %d) recorded in %q", pid, f)
+			stopped++
+		default:
+			logrus.Debugf("Removing %q, it does not record a running SPICE viewer", f)
+			removed++
+		}
+		if err := removeViewerPIDFile(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stopped, removed, errors.Join(errs...)
+}
+
+// removeViewerPIDFile removes the PID file of a viewer that is no longer running, and its ViewerConfigDir
+func removeViewerPIDFile(pidFile string) error {
+	if err := os.RemoveAll(ViewerConfigDir(pidFile)); err != nil {
+		return err
+	}
+	if err := os.Remove(pidFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// isViewerProcess checks that the command name of the process looks like a SPICE viewer,
+// as a stale PID file may record a PID reused by another process. It is true when unknown.
+var isViewerProcess = func(pid int) bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return true
+	}
+	comm := strings.ToLower(filepath.Base(strings.TrimSpace(string(out))))
+	return strings.Contains(comm, "viewer") || strings.Contains(comm, "spice") || strings.Contains(comm, "spicy")
+}
+
+// stopProcess asks the process to exit; Windows can only kill it
+func stopProcess(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		return proc.Kill()
+	}
+	return proc.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStartViewerAlreadyRunning(t *testing.T) {
+	orig := isViewerProcess
+	t.Cleanup(func() { isViewerProcess = orig })
+	isViewerProcess = func(int) bool { return true }
+	pidFile := filepath.Join(t.TempDir(), "spice-viewer.pid")
+	assert.NilError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644))
+
+	err := startViewer(exec.Command("false"), pidFile)
+	var runningErr *ViewerRunningError
+	assert.Assert(t, errors.As(err, &runningErr))
+	assert.Equal(t, runningErr.PID, os.Getpid())
+}
+
+func TestStartViewerReusedPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs true(1)")
+	}
+	orig := isViewerProcess
+	t.Cleanup(func() { isViewerProcess = orig })
+	isViewerProcess = func(pid int) bool { return pid != os.Getpid() }
+	pidFile := filepath.Join(t.TempDir(), "spice-viewer.pid")
+	assert.NilError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644))
+
+	// The stale PID file is replaced by the one of the new viewer
+	cmd := exec.Command("true")
+	assert.NilError(t, startViewer(cmd, pidFile))
+	assert.NilError(t, cmd.Wait())
+	b, err := os.ReadFile(pidFile)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), strconv.Itoa(cmd.Process.Pid)+"\n")
+}
+
+func TestRunningViewerPID(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, runningViewerPID(filepath.Join(dir, "missing.pid")), 0)
+
+	garbage := filepath.Join(dir, "garbage.pid")
+	assert.NilError(t, os.WriteFile(garbage, []byte("not a pid\n"), 0o644))
+	assert.Equal(t, runningViewerPID(garbage), 0)
+}
+
+func TestViewerPIDFile(t *testing.T) {
+	assert.Equal(t, ViewerPIDFile("/lima/default/spice-viewer.pid", 2), "/lima/default/spice-viewer.2.pid")
+}
+
+func TestStopViewers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sleep(1)")
+	}
+	orig := isViewerProcess
+	t.Cleanup(func() { isViewerProcess = orig })
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "spice-viewer.pid")
+	var viewers []*exec.Cmd
+	for range 2 {
+		cmd := exec.Command("sleep", "60")
+		assert.NilError(t, cmd.Start())
+		t.Cleanup(func() { _ = cmd.Process.Kill() })
+		viewers = append(viewers, cmd)
+	}
+	writePIDFiles := func() {
+		for i, cmd := range viewers {
+			assert.NilError(t, os.WriteFile(ViewerPIDFile(pidFile, i+1), []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0o644))
+		}
+		// A stale PID file of a viewer that has exited
+		assert.NilError(t, os.WriteFile(pidFile, []byte("999999999\n"), 0o644))
+	}
+
+	// A PID reused by another program is not stopped, but its stale PID file is removed
+	writePIDFiles()
+	isViewerProcess = func(int) bool { return false }
+	stopped, err := StopViewers(pidFile)
+	assert.NilError(t, err)
+	assert.Equal(t, stopped, 0)
+	for _, cmd := range viewers {
+		assert.NilError(t, cmd.Process.Signal(syscall.Signal(0)), "the process must still be running")
+	}
+
+	writePIDFiles()
+	isViewerProcess = func(int) bool { return true }
+	stopped, err = StopViewers(pidFile)
+	assert.NilError(t, err)
+	assert.Equal(t, stopped, 2)
+	for _, cmd := range viewers {
+		assert.ErrorContains(t, cmd.Wait(), "terminated")
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.pid"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 0)
+}
+
+func TestCleanupViewers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sleep(1)")
+	}
+	orig := isViewerProcess
+	t.Cleanup(func() { isViewerProcess = orig })
+	isViewerProcess = func(int) bool { return true }
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "spice-viewer.pid")
+	viewer := exec.Command("sleep", "60")
+	assert.NilError(t, viewer.Start())
+	t.Cleanup(func() { _ = viewer.Process.Kill() })
+	assert.NilError(t, os.WriteFile(ViewerPIDFile(pidFile, 1), []byte(strconv.Itoa(viewer.Process.Pid)+"\n"), 0o644))
+	// Stale PID files of viewers that have exited, with the settings of a monitor mapping
+	assert.NilError(t, os.WriteFile(pidFile, []byte("999999999\n"), 0o644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(ViewerConfigDir(pidFile), "virt-viewer"), 0o700))
+	assert.NilError(t, os.WriteFile(ViewerPIDFile(pidFile, 2), []byte("not a pid\n"), 0o644))
+
+	// The running viewer of a running instance is kept
+	stopped, removed, err := CleanupViewers(pidFile, false)
+	assert.NilError(t, err)
+	assert.Equal(t, stopped, 0)
+	assert.Equal(t, removed, 2)
+	assert.NilError(t, viewer.Process.Signal(syscall.Signal(0)), "the viewer must still be running")
+	matches, err := filepath.Glob(filepath.Join(dir, "*.pid"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, matches, []string{ViewerPIDFile(pidFile, 1)})
+	_, err = os.Stat(ViewerConfigDir(pidFile))
+	assert.Assert(t, os.IsNotExist(err))
+
+	// The viewer of an instance that is no longer running is stopped
+	stopped, removed, err = CleanupViewers(pidFile, true)
+	assert.NilError(t, err)
+	assert.Equal(t, stopped, 1)
+	assert.Equal(t, removed, 0)
+	assert.ErrorContains(t, viewer.Wait(), "terminated")
+	matches, err = filepath.Glob(filepath.Join(dir, "*.pid"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 0)
+}
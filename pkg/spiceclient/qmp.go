@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// defaultQMPTimeout bounds the handshake and each command sent over QMP
+const defaultQMPTimeout = 5 * time.Second
+
+// qmpClient is a minimal QEMU Machine Protocol client.
+// The zero value is ready to Connect.
+type qmpClient struct {
+	// Timeout bounds each command, defaultQMPTimeout if zero
+	Timeout time.Duration
+
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+type qmpCommand struct {
+	Execute   string `json:"execute"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+type qmpError struct {
+	Class string `json:"class"`
+	Desc  string `json:"desc"`
+}
+
+// qmpResponse holds any message received from QEMU: the greeting, an event, or a command result
+type qmpResponse struct {
+	QMP    json.RawMessage `json:"QMP,omitempty"`
+	Event  string          `json:"event,omitempty"`
+	Return json.RawMessage `json:"return,omitempty"`
+	Error  *qmpError       `json:"error,omitempty"`
+}
+
+func (c *qmpClient) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultQMPTimeout
+	}
+	return c.Timeout
+}
+
+// Connect dials the QMP socket, reads the greeting and negotiates the capabilities.
+func (c *qmpClient) Connect(ctx context.Context, socketPath string) error {
+	if c.conn != nil {
+		return errors.New("QMP client is already connected")
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to QMP socket: %w", err)
+	}
+	c.conn = conn
+	c.dec = json.NewDecoder(conn)
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		c.Close()
+		return err
+	}
+	var greeting qmpResponse
+	if err := c.dec.Decode(&greeting); err != nil {
+		c.Close()
+		return fmt.Errorf("failed to read QMP greeting: %w", err)
+	}
+	if greeting.QMP == nil {
+		c.Close()
+		return errors.New("unexpected QMP greeting")
+	}
+	if _, err := c.Execute("qmp_capabilities", nil); err != nil {
+		c.Close()
+		return err
+	}
+	return nil
+}
+
+// Execute sends a command and returns the content of its "return" member.
+// Asynchronous events received in the meantime are discarded.
+func (c *qmpClient) Execute(cmd string, args any) (json.RawMessage, error) {
+	if c.conn == nil {
+		return nil, errors.New("QMP client is not connected")
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout())); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(qmpCommand{Execute: cmd, Arguments: args})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(b); err != nil {
+		return nil, fmt.Errorf("failed to send QMP command %q: %w", cmd, err)
+	}
+	for {
+		var resp qmpResponse
+		if err := c.dec.Decode(&resp); err != nil {
+			return nil, fmt.Errorf("failed to read the response to QMP command %q: %w", cmd, err)
+		}
+		switch {
+		case resp.Error != nil:
+			return nil, fmt.Errorf("QMP command %q failed: %s: %s", cmd, resp.Error.Class, resp.Error.Desc)
+		case resp.Return != nil:
+			return resp.Return, nil
+		}
+	}
+}
+
+// Close closes the connection to QEMU.
+func (c *qmpClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.dec = nil
+	return err
+}
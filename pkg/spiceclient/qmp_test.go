@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestQMPClientExecute(t *testing.T) {
+	sock := startFakeQMP(t, func(cmd fakeQMPCommand) []any {
+		switch cmd.Execute {
+		case "query-status":
+			return []any{
+				map[string]any{"event": "RESUME"},
+				map[string]any{"return": map[string]any{"status": "running"}},
+			}
+		case "set_password":
+			return []any{map[string]any{"return": map[string]any{"arguments": cmd.Arguments}}}
+		default:
+			return []any{map[string]any{"error": map[string]any{"class": "CommandNotFound", "desc": "The command " + cmd.Execute + " has not been found"}}}
+		}
+	})
+
+	var qmp qmpClient
+	assert.NilError(t, qmp.Connect(context.Background(), sock))
+	defer qmp.Close()
+
+	ret, err := qmp.Execute("query-status", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(ret), `{"status":"running"}`)
+
+	ret, err = qmp.Execute("set_password", map[string]string{"protocol": "spice", "password": "secret"})
+	assert.NilError(t, err)
+	assert.Equal(t, string(ret), `{"arguments":{"password":"secret","protocol":"spice"}}`)
+
+	_, err = qmp.Execute("nonexistent", nil)
+	assert.ErrorContains(t, err, "CommandNotFound")
+}
+
+func TestQMPClientTimeout(t *testing.T) {
+	sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+		return nil // never answer
+	})
+
+	qmp := qmpClient{Timeout: 100 * time.Millisecond}
+	assert.NilError(t, qmp.Connect(context.Background(), sock))
+	defer qmp.Close()
+
+	_, err := qmp.Execute("query-spice", nil)
+	assert.ErrorContains(t, err, "timeout")
+}
+
+func TestQuerySPICEPort(t *testing.T) {
+	sock := startFakeQMP(t, func(cmd fakeQMPCommand) []any {
+		if cmd.Execute != "query-spice" {
+			return []any{map[string]any{"error": map[string]any{"class": "CommandNotFound", "desc": cmd.Execute}}}
+		}
+		return []any{map[string]any{"return": map[string]any{
+			"enabled": true, "migrated": false, "host": "127.0.0.1", "port": 5930, "auth": "none",
+		}}}
+	})
+
+	hostPort, err := QuerySPICEPort(sock)
+	assert.NilError(t, err)
+	assert.Equal(t, hostPort, "127.0.0.1:5930")
+
+	// port=0 disables the TCP port
+	sock = startFakeQMP(t, func(fakeQMPCommand) []any {
+		return []any{map[string]any{"return": map[string]any{"enabled": true, "host": "127.0.0.1", "port": 0}}}
+	})
+	_, err = QuerySPICEPort(sock)
+	assert.ErrorIs(t, err, ErrNoPort)
+}
+
+func TestQueryConnectedClients(t *testing.T) {
+	sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+		return []any{map[string]any{"return": map[string]any{
+			"enabled": true, "host": "127.0.0.1", "port": 5930,
+			"channels": []any{
+				map[string]any{"host": "127.0.0.1", "port": "50001", "family": "ipv4", "connection-id": 42, "channel-type": 1, "channel-id": 0, "tls": false},
+				map[string]any{"host": "127.0.0.1", "port": "50002", "family": "ipv4", "connection-id": 42, "channel-type": 2, "channel-id": 0, "tls": false},
+				map[string]any{"host": "::1", "port": "50010", "family": "ipv6", "connection-id": 7, "channel-type": 3, "channel-id": 0, "tls": true},
+				map[string]any{"host": "::1", "port": "50011", "family": "ipv6", "connection-id": 7, "channel-type": 1, "channel-id": 0, "tls": true},
+			},
+		}}}
+	})
+
+	n, clients, err := QueryConnectedClients(t.Context(), sock)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 2)
+	assert.DeepEqual(t, clients, []ClientInfo{
+		{ConnectionID: 42, Host: "127.0.0.1", Port: "50001", Family: "ipv4", Channels: []string{"main", "display"}},
+		{ConnectionID: 7, Host: "::1", Port: "50011", Family: "ipv6", TLS: true, Channels: []string{"inputs", "main"}},
+	})
+}
+
+func TestQueryServerStatus(t *testing.T) {
+	sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+		return []any{map[string]any{"return": map[string]any{
+			"enabled": true, "host": "127.0.0.1", "port": 5930, "mouse-mode": "server", "compiled-version": "0.15.1",
+			"channels": []any{
+				map[string]any{"host": "127.0.0.1", "port": "50001", "family": "ipv4", "connection-id": 42, "channel-type": 1, "channel-id": 0, "tls": false},
+			},
+		}}}
+	})
+
+	status, err := QueryServerStatus(t.Context(), sock)
+	assert.NilError(t, err)
+	assert.Equal(t, status.MouseMode, "server")
+	assert.Equal(t, status.ServerVersion, "0.15.1")
+	assert.Equal(t, len(status.Clients), 1)
+}
+
+func TestDiscoverFromInstance(t *testing.T) {
+	t.Run("QMP", func(t *testing.T) {
+		sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+			return []any{map[string]any{"return": map[string]any{"enabled": true, "host": "0.0.0.0", "port": 5930}}}
+		})
+		conn, err := DiscoverFromInstance(filepath.Dir(sock), sock)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, conn, &Connection{Host: "127.0.0.1", Port: "5930", Detach: true})
+	})
+
+	t.Run("QMP with SPICE disabled", func(t *testing.T) {
+		sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+			return []any{map[string]any{"return": map[string]any{"enabled": false}}}
+		})
+		_, err := DiscoverFromInstance(filepath.Dir(sock), sock)
+		assert.ErrorContains(t, err, "not enabled")
+	})
+
+	t.Run("QMP without a port", func(t *testing.T) {
+		sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+			return []any{map[string]any{"return": map[string]any{"enabled": true, "host": "0.0.0.0"}}}
+		})
+		_, err := DiscoverFromInstance(filepath.Dir(sock), sock)
+		assert.ErrorIs(t, err, ErrNoPort)
+	})
+
+	t.Run("SPICE socket", func(t *testing.T) {
+		instDir := t.TempDir()
+		spiceSock := filepath.Join(instDir, "spice.sock")
+		l, err := net.Listen("unix", spiceSock)
+		assert.NilError(t, err)
+		defer l.Close()
+		conn, err := DiscoverFromInstance(instDir, filepath.Join(instDir, "qmp.sock"))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, conn, &Connection{UnixPath: spiceSock, Detach: true})
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		instDir := t.TempDir()
+		_, err := DiscoverFromInstance(instDir, filepath.Join(instDir, "qmp.sock"))
+		assert.ErrorContains(t, err, "failed to discover")
+	})
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SaveConnection records conn in path, so that LoadConnection can reconnect without discovering the server again.
+// The password is not saved, nor are the PID file and the detach mode, which belong to a single launch.
+func SaveConnection(path string, conn *Connection) error {
+	c := *conn
+	c.Password, c.PIDFile, c.Detach = "", "", false
+	b, err := json.MarshalIndent(&c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// LoadConnection reads a connection recorded by SaveConnection
+func LoadConnection(path string) (*Connection, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var conn Connection
+	if err := json.Unmarshal(b, &conn); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	return &conn, nil
+}
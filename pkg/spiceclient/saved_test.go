@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSaveConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spice-connection.json")
+	conn := &Connection{
+		Host:           "127.0.0.1",
+		TLSPort:        "5931",
+		Password:       "secret",
+		Channels:       []string{"display", "inputs"},
+		MonitorMapping: map[int]int{1: 2},
+		Detach:         true,
+		PIDFile:        "/tmp/spice-viewer.pid",
+	}
+	assert.NilError(t, SaveConnection(path, conn))
+
+	loaded, err := LoadConnection(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, &Connection{
+		Host:           "127.0.0.1",
+		TLSPort:        "5931",
+		Channels:       []string{"display", "inputs"},
+		MonitorMapping: map[int]int{1: 2},
+	})
+	assert.Equal(t, conn.Password, "secret", "the saved connection is a copy")
+
+	_, err = LoadConnection(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Assert(t, os.IsNotExist(err))
+}
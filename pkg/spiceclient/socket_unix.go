@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package spiceclient
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lima-vm/lima/v2/pkg/osutil"
+)
+
+// checkUnixSocket checks that the SPICE server socket exists, and that the user can connect to it,
+// as the viewers report an inaccessible socket with a cryptic error.
+// Abstract sockets have no file, nor permissions, and are not checked.
+func checkUnixSocket(path string) error {
+	if strings.HasPrefix(path, "@") {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("spice socket not accessible: %s does not exist, is the instance running?", path)
+		}
+		return fmt.Errorf("spice socket not accessible: %w", err)
+	}
+	if fi.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("spice socket not accessible: %s is not a socket (%s)", path, describeFileOwner(fi))
+	}
+	// Connecting to a Unix socket requires write permission; access(2) checks it for the real user
+	if err := unix.Access(path, unix.R_OK|unix.W_OK); err != nil {
+		return fmt.Errorf("spice socket not accessible: %s (%s): %w", path, describeFileOwner(fi), err)
+	}
+	return nil
+}
+
+// describeFileOwner formats the mode and the owner of a file, e.g., "srw------- owned by root:wheel"
+func describeFileOwner(fi fs.FileInfo) string {
+	stat, ok := osutil.SysStat(fi)
+	if !ok {
+		return fi.Mode().String()
+	}
+	owner := strconv.FormatUint(uint64(stat.Uid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group := strconv.FormatUint(uint64(stat.Gid), 10)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return fmt.Sprintf("%s owned by %s:%s", fi.Mode(), owner, group)
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package spiceclient
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "spice.sock")
+	l, err := net.Listen("unix", sock)
+	assert.NilError(t, err)
+	t.Cleanup(func() { l.Close() })
+	assert.NilError(t, checkUnixSocket(sock))
+	assert.NilError(t, checkUnixSocket("@lima-spice"))
+
+	err = checkUnixSocket(filepath.Join(dir, "missing.sock"))
+	assert.ErrorContains(t, err, "spice socket not accessible")
+	assert.ErrorContains(t, err, "does not exist")
+
+	file := filepath.Join(dir, "spice.file")
+	assert.NilError(t, os.WriteFile(file, nil, 0o600))
+	assert.ErrorContains(t, checkUnixSocket(file), "is not a socket (-rw------- owned by ")
+
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses the permissions of the socket")
+	}
+	assert.NilError(t, os.Chmod(sock, 0o400))
+	assert.ErrorContains(t, checkUnixSocket(sock), "spice socket not accessible: "+sock+" (Sr--------")
+}
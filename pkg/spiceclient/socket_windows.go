@@ -0,0 +1,9 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+// checkUnixSocket is a no-op on Windows, where Unix sockets are protected by ACLs instead of modes
+func checkUnixSocket(_ string) error {
+	return nil
+}
@@ -9,13 +9,24 @@ package spiceclient
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/executil"
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
 )
 
 // Connection represents a SPICE connection configuration
@@ -23,33 +34,248 @@ type Connection struct {
 	Host     string
 	Port     string
 	Password string
-	UnixPath string // For Unix socket connections
+	// Username is sent along with the password to SPICE gateways that require one, in the userinfo of the URI
+	Username string
+	UnixPath string // For Unix socket connections; "@name" is an abstract socket (Linux only)
 	Audio    bool   // Enable audio streaming
+	// UnixBridge is the 127.0.0.1 address of a TCP bridge to UnixPath, e.g., "127.0.0.1:49213", used by the viewers
+	// that cannot connect to a Unix socket (spicy without --uri). LaunchViewer sets it on the connection it launches.
+	UnixBridge string
+	// AudioDevice plays the audio on a host output device instead of the default one: a PulseAudio sink on Linux,
+	// e.g., "alsa_output.usb-headset.analog-stereo", a CoreAudio device ID on macOS, or a WASAPI device ID on Windows
+	AudioDevice string
+	Channels    []string // SPICE channels to enable; empty means all channels
+	Detach      bool     // Run the viewer in its own session so that it survives limactl exiting
+	// SharedDir is a host directory shared with the guest over SPICE WebDAV (requires spice-webdavd in the guest)
+	SharedDir         string
+	SharedDirReadOnly bool
+	// TLSPort is the TLS port of the SPICE server, used instead of Port when set
+	TLSPort string
+	// UnixTLS is set when the server speaks TLS on its Unix socket (spice+tls-unix://)
+	UnixTLS bool
+	// CACertFile verifies the certificate of the SPICE server, the system trust store is used if empty
+	CACertFile string
+	// HostSubject is the expected subject of the server certificate, e.g., "C=US,O=Lima,CN=lima-default";
+	// the host name is verified if empty
+	HostSubject string
+	// MonitorMapping maps guest displays to host monitors in full-screen mode, both numbered from 1
+	// (remote-viewer and virt-viewer only)
+	MonitorMapping map[int]int
+	// WindowSize opens the viewer in a window instead of full screen, e.g., "1920x1080".
+	// Neither remote-viewer nor spicy accept a window size; both size their window to the guest display
+	// (remote-viewer at 100% zoom), so the window has this size when the guest display has this resolution.
+	WindowSize string
+	// ZoomLevel opens the viewer in a window with a guest pixel mapped to ZoomLevel percent of a host pixel,
+	// e.g., 100 for pixel-accurate screenshots; the scale factor of HiDPI host monitors is accounted for.
+	// 0 keeps the default of the viewer (remote-viewer and virt-viewer only).
+	ZoomLevel int
+	// Hotkeys rebinds viewer actions to key combinations, e.g., "release-cursor" to "ctrl+shift+f12";
+	// an empty combination disables the hotkey (remote-viewer and virt-viewer only)
+	Hotkeys map[string]string
+	// HostDisplayEnv selects the host display the viewer is shown on, as NAME=VALUE variables of
+	// hostDisplayVars, e.g., "DISPLAY=:0", for callers without a display of their own.
+	// The DISPLAY and WAYLAND_DISPLAY of the caller are not inherited when it is set.
+	HostDisplayEnv []string
+	// ViewOnly disables the inputs channel, so that the viewer shows the display without controlling
+	// the guest (remote-viewer and virt-viewer only, over TCP)
+	ViewOnly bool
+	// ConnectionFile hands the connection to the viewer in a connection file (.vv) instead of a SPICE URI,
+	// so that the password is not in the arguments of the process (remote-viewer and virt-viewer only, over TCP).
+	// View-only connections always use a connection file.
+	ConnectionFile bool
+	// PIDFile records the PID of the viewer while it runs; LaunchViewer does not start a second viewer
+	// while the recorded one is running, and returns a *ViewerRunningError instead. The PID file of a detached
+	// viewer is left behind when it exits; a recorded PID reused by another program does not count as running.
+	// Required by a detached viewer with a MonitorMapping, whose settings are kept in the ViewerConfigDir of the PID file.
+	PIDFile string
+}
+
+// KnownChannels lists the SPICE channel names accepted in Connection.Channels.
+var KnownChannels = []string{
+	"main",
+	"display",
+	"inputs",
+	"cursor",
+	"playback",
+	"record",
+	"smartcard",
+	"usbredir",
+	"port",
+	"webdav",
+}
+
+// ValidateChannels returns an error if any of the given channel names is not a known SPICE channel.
+func ValidateChannels(channels []string) error {
+	for _, ch := range channels {
+		if !slices.Contains(KnownChannels, ch) {
+			return fmt.Errorf("unknown SPICE channel %q (known channels: %s)", ch, strings.Join(KnownChannels, ", "))
+		}
+	}
+	return nil
+}
+
+// ParseWindowSize parses a "WIDTHxHEIGHT" window size, e.g., "1920x1080".
+func ParseWindowSize(s string) (width, height int, err error) {
+	w, h, ok := strings.Cut(s, "x")
+	width, wErr := strconv.Atoi(w)
+	height, hErr := strconv.Atoi(h)
+	if !ok || wErr != nil || hErr != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid window size %q, expected WIDTHxHEIGHT, e.g. 1920x1080", s)
+	}
+	return width, height, nil
+}
+
+// hostDisplayVars are the variables accepted in Connection.HostDisplayEnv
+var hostDisplayVars = []string{"DISPLAY", "WAYLAND_DISPLAY", "XAUTHORITY", "XDG_RUNTIME_DIR"}
+
+// viewerEnv returns the environment of the viewer with the extra variables, or nil to inherit
+// the environment of the caller
+func viewerEnv(conn *Connection, extra []string) ([]string, error) {
+	if len(conn.HostDisplayEnv) == 0 {
+		if len(extra) == 0 {
+			return nil, nil
+		}
+		return append(os.Environ(), extra...), nil
+	}
+	for _, kv := range conn.HostDisplayEnv {
+		if name, _, ok := strings.Cut(kv, "="); !ok || !slices.Contains(hostDisplayVars, name) {
+			return nil, fmt.Errorf("invalid host display variable %q, expected NAME=VALUE with NAME one of %s",
+				kv, strings.Join(hostDisplayVars, ", "))
+		}
+	}
+	// An inherited WAYLAND_DISPLAY would take precedence over the DISPLAY of the override in GTK
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		name, _, _ := strings.Cut(kv, "=")
+		return name == "DISPLAY" || name == "WAYLAND_DISPLAY"
+	})
+	env = append(env, conn.HostDisplayEnv...)
+	return append(env, extra...), nil
 }
 
 // LaunchViewer launches an external SPICE viewer application with the given connection details.
 // It attempts to find and use available SPICE client applications on the system.
 func LaunchViewer(ctx context.Context, conn *Connection) error {
-	viewer, err := FindViewer()
+	viewer, viewerType, err := findViewer()
 	if err != nil {
 		return fmt.Errorf("failed to find SPICE viewer: %w", err)
 	}
 
-	args, err := buildViewerArgs(viewer, conn)
+	if conn.UnixPath != "" {
+		if err := checkUnixSocket(conn.UnixPath); err != nil {
+			return err
+		}
+	}
+
+	detach := conn.Detach
+	var bridge *unixBridge
+	if needsUnixBridge(viewerType, conn) {
+		if bridge, err = startUnixBridge(conn); err != nil {
+			return fmt.Errorf("failed to bridge the SPICE socket for spicy: %w", err)
+		}
+		bridged := *conn
+		bridged.UnixBridge = bridge.Addr()
+		conn = &bridged
+		// The bridge runs in this process, so the viewer cannot outlive it
+		detach = false
+		logrus.Infof("spicy cannot connect to a Unix socket, bridging %s to %s until the viewer is closed", conn.UnixPath, conn.UnixBridge)
+	}
+
+	args, err := buildViewerArgs(viewerType, conn)
+	if err == nil {
+		args, err = filterViewerArgs(viewer, args)
+	}
 	if err != nil {
+		if bridge != nil {
+			_ = bridge.Close()
+		}
 		return fmt.Errorf("failed to build viewer arguments: %w", err)
 	}
 
+	if detach {
+		// A detached viewer must not be killed when the caller's context is cancelled
+		ctx = context.WithoutCancel(ctx)
+	}
+	mappingEnv, cleanup, err := monitorMappingEnv(conn)
+	if err != nil {
+		if bridge != nil {
+			_ = bridge.Close()
+		}
+		return fmt.Errorf("failed to set up the monitor mapping: %w", err)
+	}
+	if bridge != nil {
+		mappingCleanup := cleanup
+		cleanup = func() {
+			mappingCleanup()
+			_ = bridge.Close()
+		}
+	}
+	audioEnv, err := audioDeviceEnv(conn, runtime.GOOS)
+	if err != nil {
+		cleanup()
+		return err
+	}
+	env, err := viewerEnv(conn, append(mappingEnv, audioEnv...))
+	if err != nil {
+		cleanup()
+		return err
+	}
+	if conn.usesConnectionFile() {
+		// buildViewerArgs only accepts connection files for remote-viewer, whose first argument is the URI
+		vvFile, vvCleanup, err := connectionFile(conn)
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("failed to write the connection file: %w", err)
+		}
+		args[0] = vvFile
+		mappingCleanup := cleanup
+		cleanup = func() {
+			mappingCleanup()
+			vvCleanup()
+		}
+	}
 	cmd := exec.CommandContext(ctx, viewer, args...)
+	cmd.Env = env
+	if detach {
+		cmd.SysProcAttr = executil.DetachedSysProcAttr
+	}
 
 	logrus.Debugf("Launching SPICE viewer: %s %v", viewer, args)
 
-	if err := cmd.Start(); err != nil {
+	if err := startViewer(cmd, conn.PIDFile); err != nil {
+		cleanup()
+		var runningErr *ViewerRunningError
+		if errors.As(err, &runningErr) {
+			return err
+		}
 		return fmt.Errorf("failed to start SPICE viewer: %w", err)
 	}
+	if conn.PIDFile != "" {
+		mappingCleanup := cleanup
+		cleanup = func() {
+			mappingCleanup()
+			if err := removeViewerPIDFile(conn.PIDFile); err != nil {
+				logrus.WithError(err).Debugf("Failed to remove %s", conn.PIDFile)
+			}
+		}
+	}
+
+	if !detach {
+		// Stay in the caller's process group and wait for the viewer to be closed
+		defer cleanup()
+		if err := cmd.Wait(); err != nil {
+			if ctx.Err() != nil {
+				// Killed by exec.CommandContext, e.g., when the caller was interrupted
+				logrus.Debugf("SPICE viewer was stopped: %v", context.Cause(ctx))
+				return nil
+			}
+			return fmt.Errorf("SPICE viewer exited with error: %w", err)
+		}
+		return nil
+	}
 
 	// Don't wait for the viewer to exit, let it run independently
 	go func() {
+		defer cleanup()
 		if err := cmd.Wait(); err != nil {
 			logrus.Debugf("SPICE viewer exited with error: %v", err)
 		}
@@ -60,7 +286,15 @@ func LaunchViewer(ctx context.Context, conn *Connection) error {
 
 // FindViewer attempts to locate an available SPICE viewer on the system.
 // It searches for common SPICE client applications in order of preference.
+// The candidates can be extended or replaced per host OS in $LIMA_HOME/_config/gui.yaml.
 func FindViewer() (string, error) {
+	viewer, _, err := findViewer()
+	return viewer, err
+}
+
+// findViewer returns the path of the first available SPICE viewer,
+// along with the name used to select its command-line style.
+func findViewer() (path, viewerType string, err error) {
 	var candidates []string
 
 	switch runtime.GOOS {
@@ -83,24 +317,63 @@ func FindViewer() (string, error) {
 			"virt-viewer.exe",
 		}
 	default:
-		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		return "", "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
-	for _, viewer := range candidates {
-		path, err := exec.LookPath(viewer)
+	cfg, err := LoadGUIConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, viewer := range viewerCandidates(runtime.GOOS, candidates, cfg) {
+		path, err := exec.LookPath(viewer.Path)
 		if err == nil {
 			logrus.Debugf("Found SPICE viewer: %s", path)
-			return path, nil
+			viewerType = viewer.Type
+			if viewerType == "" {
+				viewerType = path
+			}
+			return path, viewerType, nil
 		}
 	}
 
-	return "", fmt.Errorf("no SPICE viewer found, install remote-viewer or spicy")
+	return "", "", fmt.Errorf("no SPICE viewer found, install remote-viewer or spicy")
 }
 
 // buildViewerArgs constructs command-line arguments for the SPICE viewer based on the connection details.
+// The viewer is either the executable path or the configured viewer type.
 func buildViewerArgs(viewer string, conn *Connection) ([]string, error) {
 	var args []string
 
+	if err := ValidateChannels(conn.Channels); err != nil {
+		return nil, err
+	}
+	if err := validateSharedDir(conn.SharedDir); err != nil {
+		return nil, err
+	}
+	if len(conn.MonitorMapping) > 0 {
+		if err := validateMonitorMapping(conn.MonitorMapping, hostMonitorCount()); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateHotkeys(conn.Hotkeys); err != nil {
+		return nil, err
+	}
+	if conn.WindowSize != "" {
+		if _, _, err := ParseWindowSize(conn.WindowSize); err != nil {
+			return nil, err
+		}
+		if len(conn.MonitorMapping) > 0 {
+			return nil, errors.New("monitor mapping only applies in full-screen mode, cannot be used with a window size")
+		}
+	}
+	if err := validateZoomLevel(conn.ZoomLevel); err != nil {
+		return nil, err
+	}
+	if conn.ZoomLevel != 0 && len(conn.MonitorMapping) > 0 {
+		return nil, errors.New("monitor mapping only applies in full-screen mode, cannot be used with a zoom level")
+	}
+
 	// Determine viewer type from the executable name
 	viewerName := strings.ToLower(viewer)
 
@@ -110,30 +383,111 @@ func buildViewerArgs(viewer string, conn *Connection) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+		if conn.UnixTLS {
+			// spice-gtk only opens TLS channels on a TCP port
+			return nil, errors.New("remote-viewer does not support TLS over a Unix socket, use a TLS port")
+		}
+		if conn.ViewOnly && conn.UnixPath != "" {
+			return nil, errors.New("view-only connections are not supported over a Unix socket, use a TCP port")
+		}
+		if conn.ConnectionFile && conn.UnixPath != "" {
+			return nil, errors.New("connection files cannot name a Unix socket, use a TCP port")
+		}
+		// LaunchViewer replaces the URI with a connection file, which also holds the full-screen and TLS settings
 		args = []string{uri}
 
-		// Add fullscreen option
-		args = append(args, "--full-screen")
+		switch {
+		case conn.ZoomLevel != 0:
+			zoom, err := viewerZoomLevel(conn.ZoomLevel, hostScaleFactor())
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "--zoom="+strconv.Itoa(zoom))
+		case conn.WindowSize != "":
+			// Unscaled, so that the window opens at the size of the guest display
+			args = append(args, "--zoom=100")
+		case !conn.usesConnectionFile():
+			args = append(args, "--full-screen")
+		}
 
 		// Disable audio if not enabled
-		if !conn.Audio {
+		if !conn.Audio || !conn.hasAudioChannel() {
 			args = append(args, "--spice-disable-audio")
 		}
 
+		args = append(args, channelArgs(conn)...)
+		args = append(args, sharedDirArgs(conn)...)
+		if !conn.usesConnectionFile() {
+			args = append(args, tlsArgs(conn)...)
+		}
+		args = append(args, hotkeysArgs(conn)...)
+
 	} else if strings.Contains(viewerName, "spicy") {
-		// spicy uses separate host/port arguments
-		if conn.UnixPath != "" {
-			return nil, fmt.Errorf("spicy does not support Unix socket connections")
+		if len(conn.MonitorMapping) > 0 {
+			return nil, errors.New("spicy does not support monitor mapping, use remote-viewer")
+		}
+		if len(conn.Hotkeys) > 0 {
+			return nil, errors.New("spicy does not support hotkeys, use remote-viewer")
+		}
+		if conn.ViewOnly {
+			return nil, errors.New("spicy does not support view-only connections, use remote-viewer")
+		}
+		if conn.ConnectionFile {
+			return nil, errors.New("spicy does not support connection files, use remote-viewer")
+		}
+		if conn.ZoomLevel != 0 {
+			return nil, errors.New("spicy does not support a zoom level, use remote-viewer")
 		}
 
-		args = []string{
-			"-h", conn.Host,
-			"-p", conn.Port,
+		// Only a found executable is probed; a configured viewer type, e.g., for a wrapper script,
+		// keeps the options that every spicy accepts
+		if filepath.IsAbs(viewer) && spicySupportsURI(viewer) {
+			// Newer spicy takes the whole URI, which can also express a Unix socket
+			if conn.UnixTLS {
+				return nil, errors.New("spicy does not support TLS over a Unix socket, use a TLS port")
+			}
+			uri, err := buildSpiceURI(conn)
+			if err != nil {
+				return nil, err
+			}
+			args = []string{"--uri=" + uri}
+		} else {
+			// Older spicy uses separate host/port arguments
+			host, port := conn.Host, conn.Port
+			if conn.UnixPath != "" {
+				if conn.UnixBridge == "" {
+					return nil, fmt.Errorf("spicy does not support Unix socket connections")
+				}
+				// LaunchViewer relays the socket to a TCP port of 127.0.0.1
+				var err error
+				if host, port, err = net.SplitHostPort(conn.UnixBridge); err != nil {
+					return nil, fmt.Errorf("invalid Unix socket bridge address %q: %w", conn.UnixBridge, err)
+				}
+			}
+			if conn.Username != "" {
+				return nil, errors.New("this spicy does not support a username, use remote-viewer or a newer spicy")
+			}
+
+			args = []string{"-h", host}
+			if port != "" {
+				args = append(args, "-p", port)
+			}
+			if conn.TLSPort != "" {
+				args = append(args, "-s", conn.TLSPort)
+			}
+
+			if conn.Password != "" {
+				args = append(args, "-w", conn.Password)
+			}
 		}
 
-		if conn.Password != "" {
-			args = append(args, "-w", conn.Password)
+		// spicy is built on spice-gtk and accepts the same channel options
+		if !conn.Audio || !conn.hasAudioChannel() {
+			args = append(args, "--spice-disable-audio")
 		}
+		args = append(args, channelArgs(conn)...)
+		args = append(args, sharedDirArgs(conn)...)
+		args = append(args, tlsArgs(conn)...)
 	} else {
 		return nil, fmt.Errorf("unknown SPICE viewer type: %s", viewer)
 	}
@@ -141,34 +495,149 @@ func buildViewerArgs(viewer string, conn *Connection) ([]string, error) {
 	return args, nil
 }
 
+// usesConnectionFile reports whether LaunchViewer hands the connection to the viewer in a connection file
+func (conn *Connection) usesConnectionFile() bool {
+	return conn.ConnectionFile || conn.ViewOnly
+}
+
+// hasChannel reports whether the given channel is enabled for the connection.
+// All channels are enabled when no explicit channel list is set.
+func (conn *Connection) hasChannel(name string) bool {
+	return len(conn.Channels) == 0 || slices.Contains(conn.Channels, name)
+}
+
+// hasAudioChannel reports whether either of the audio channels is enabled.
+func (conn *Connection) hasAudioChannel() bool {
+	return conn.hasChannel("playback") || conn.hasChannel("record")
+}
+
+// channelArgs returns the spice-gtk options that restrict the session to the selected channels.
+// The main, display, inputs and cursor channels cannot be disabled from the command line,
+// so leaving them out of the list only produces a warning.
+func channelArgs(conn *Connection) []string {
+	if len(conn.Channels) == 0 {
+		return nil
+	}
+
+	var args []string
+	if !conn.hasChannel("usbredir") {
+		args = append(args, "--spice-disable-usbredir")
+	}
+	if conn.hasChannel("smartcard") {
+		args = append(args, "--spice-smartcard")
+	}
+	for _, ch := range []string{"display", "inputs", "cursor"} {
+		if !conn.hasChannel(ch) {
+			logrus.Warnf("SPICE channel %q cannot be disabled from the viewer command line, ignoring", ch)
+		}
+	}
+	return args
+}
+
+// validateSharedDir checks that the directory to share exists on the host
+func validateSharedDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("invalid shared directory: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("invalid shared directory: %q is not a directory", dir)
+	}
+	return nil
+}
+
+// sharedDirArgs returns the spice-gtk options that share a host directory over SPICE WebDAV
+func sharedDirArgs(conn *Connection) []string {
+	if conn.SharedDir == "" {
+		return nil
+	}
+	args := []string{"--spice-shared-dir=" + conn.SharedDir}
+	if conn.SharedDirReadOnly {
+		args = append(args, "--spice-shared-dir-ro")
+	}
+	return args
+}
+
+// Schemes of SPICE servers listening on a Unix socket
+const (
+	unixScheme    = "spice+unix://"
+	tlsUnixScheme = "spice+tls-unix://"
+)
+
 // buildSpiceURI constructs a SPICE connection URI from the connection details.
 // Supports both TCP and Unix socket connections.
+// The username and the socket path are percent-encoded, e.g., a space becomes "%20"; abstract sockets keep their "@" prefix.
 func buildSpiceURI(conn *Connection) (string, error) {
 	if conn.UnixPath != "" {
-		return fmt.Sprintf("spice+unix://%s", conn.UnixPath), nil
+		if conn.Username != "" {
+			return "", errors.New("a username is not supported over a Unix socket, use a TCP port")
+		}
+		scheme := unixScheme
+		if conn.UnixTLS {
+			scheme = tlsUnixScheme
+		}
+		return scheme + (&url.URL{Path: conn.UnixPath}).EscapedPath(), nil
 	}
 
-	if conn.Host == "" || conn.Port == "" {
+	if conn.Host == "" || (conn.Port == "" && conn.TLSPort == "") {
 		return "", fmt.Errorf("host and port required for TCP connection")
 	}
 
-	uri := fmt.Sprintf("spice://%s:%s", conn.Host, conn.Port)
+	uri := "spice://"
+	if conn.Username != "" {
+		// Percent-encoded, e.g., "@" becomes "%40"
+		uri += url.User(conn.Username).String() + "@"
+	}
+	uri += conn.Host
+	if conn.Port != "" {
+		uri += ":" + conn.Port
+	}
 
+	var query []string
+	if conn.TLSPort != "" {
+		query = append(query, "tls-port="+conn.TLSPort)
+	}
 	if conn.Password != "" {
-		uri += fmt.Sprintf("?password=%s", conn.Password)
+		// Percent-encoded, e.g., "&" becomes "%26"; spice-gtk does not decode "+" as a space
+		query = append(query, "password="+strings.ReplaceAll(url.QueryEscape(conn.Password), "+", "%20"))
+	}
+	if len(query) > 0 {
+		uri += "?" + strings.Join(query, "&")
 	}
 
 	return uri, nil
 }
 
+// ErrNoPort is returned when the SPICE server of a running VM has no port to connect to,
+// e.g., with port=0, which disables the plain TCP port of QEMU
+var ErrNoPort = errors.New("SPICE server has no port")
+
 // GetConnectionInfo extracts SPICE connection information from a QEMU SPICE display string.
-// Example inputs: "spice,port=5900,disable-ticketing=on" or "spice+unix:///path/to/socket"
+// With port=0, QEMU opens no plain TCP port, and the port is left empty.
+// Example inputs: "spice,port=5900,disable-ticketing=on", "spice+unix:///path/to/socket",
+// "spice+unix://@abstract-name" or "spice+tls-unix:///path/to/socket"
 func GetConnectionInfo(displayString string) (*Connection, error) {
-	conn := &Connection{}
+	conn := &Connection{Detach: true}
 
 	// Check for Unix socket format
-	if strings.HasPrefix(displayString, "spice+unix://") {
-		conn.UnixPath = strings.TrimPrefix(displayString, "spice+unix://")
+	for _, scheme := range []string{unixScheme, tlsUnixScheme} {
+		path, ok := strings.CutPrefix(displayString, scheme)
+		if !ok {
+			continue
+		}
+		// Accept both percent-encoded and raw paths, e.g., with spaces
+		unescaped, err := url.PathUnescape(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPICE socket path %q: %w", path, err)
+		}
+		if unescaped == "" || unescaped == "@" {
+			return nil, fmt.Errorf("invalid SPICE display string: %s", displayString)
+		}
+		conn.UnixPath = unescaped
+		conn.UnixTLS = scheme == tlsUnixScheme
 		return conn, nil
 	}
 
@@ -199,6 +668,9 @@ func GetConnectionInfo(displayString string) (*Connection, error) {
 		switch key {
 		case "port":
 			conn.Port = value
+			if value == "0" {
+				conn.Port = ""
+			}
 		case "addr":
 			conn.Host = value
 		case "password":
@@ -209,22 +681,195 @@ func GetConnectionInfo(displayString string) (*Connection, error) {
 	return conn, nil
 }
 
+// spiceInfo is the result of the QMP query-spice command
+type spiceInfo struct {
+	Enabled   bool           `json:"enabled"`
+	Host      string         `json:"host"`
+	Port      *int           `json:"port"`
+	TLSPort   *int           `json:"tls-port"`
+	MouseMode string         `json:"mouse-mode"`
+	Channels  []spiceChannel `json:"channels"`
+	// CompiledVersion is the version of the spice-server library QEMU is built with, e.g., "0.15.1"
+	CompiledVersion string `json:"compiled-version"`
+}
+
+// spiceChannel is a channel opened by a SPICE client, as listed by query-spice
+type spiceChannel struct {
+	Host         string `json:"host"`
+	Port         string `json:"port"`
+	Family       string `json:"family"`
+	ConnectionID int64  `json:"connection-id"`
+	ChannelType  int    `json:"channel-type"`
+	TLS          bool   `json:"tls"`
+}
+
+// spiceChannelTypes maps the SPICE protocol channel type numbers to the names in KnownChannels
+var spiceChannelTypes = map[int]string{
+	1:  "main",
+	2:  "display",
+	3:  "inputs",
+	4:  "cursor",
+	5:  "playback",
+	6:  "record",
+	8:  "smartcard",
+	9:  "usbredir",
+	10: "port",
+	11: "webdav",
+}
+
+// ClientInfo describes a SPICE client connected to QEMU
+type ClientInfo struct {
+	ConnectionID int64
+	Host         string // Address of the client, from its main channel
+	Port         string
+	Family       string // "ipv4", "ipv6" or "unix"
+	TLS          bool
+	Channels     []string // Names of the opened channels, e.g., "main", "display"
+}
+
+// querySPICE runs query-spice over the QMP socket.
+func querySPICE(ctx context.Context, qmpSocketPath string) (*spiceInfo, error) {
+	var qmp qmpClient
+	if deadline, ok := ctx.Deadline(); ok {
+		qmp.Timeout = time.Until(deadline)
+	}
+	if err := qmp.Connect(ctx, qmpSocketPath); err != nil {
+		return nil, err
+	}
+	defer qmp.Close()
+
+	ret, err := qmp.Execute("query-spice", nil)
+	if err != nil {
+		return nil, err
+	}
+	var info spiceInfo
+	if err := json.Unmarshal(ret, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse query-spice result: %w", err)
+	}
+	if !info.Enabled {
+		return nil, errors.New("SPICE is not enabled for this VM")
+	}
+	return &info, nil
+}
+
 // QuerySPICEPort queries QEMU via QMP to get the SPICE port information.
 // Returns the SPICE service string (e.g., "127.0.0.1:5900").
 func QuerySPICEPort(qmpSocketPath string) (string, error) {
-	// Connect to QMP socket
-	conn, err := net.Dial("unix", qmpSocketPath)
+	info, err := querySPICE(context.Background(), qmpSocketPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to QMP socket: %w", err)
+		return "", err
+	}
+	switch {
+	case info.Port != nil && *info.Port != 0:
+		return net.JoinHostPort(info.Host, strconv.Itoa(*info.Port)), nil
+	case (info.TLSPort != nil && *info.TLSPort != 0) || isSocketHost(info.Host):
+		return "", errors.New("SPICE server has no TCP port (Unix socket or TLS only)")
+	default:
+		return "", ErrNoPort
 	}
-	defer conn.Close()
+}
+
+// isSocketHost reports whether the host reported by query-spice is the path of a Unix socket
+func isSocketHost(host string) bool {
+	return strings.HasPrefix(host, "/") || strings.HasPrefix(host, "@")
+}
+
+// ServerStatus is the live state of the SPICE server of a running VM
+type ServerStatus struct {
+	// MouseMode is "client" when the guest agent negotiated absolute pointer positions with the clients,
+	// and "server" when the pointer is moved relatively by QEMU, or "unknown"
+	MouseMode string
+	Clients   []ClientInfo
+	// ServerVersion is the version of spice-server, empty if QEMU does not report it
+	ServerVersion string
+}
 
-	// This is a simplified implementation
-	// In a full implementation, you would:
-	// 1. Perform QMP handshake
-	// 2. Send query-spice command
-	// 3. Parse the JSON response
-	// For now, return an error indicating this needs QMP integration
+// QueryServerStatus asks QEMU over the QMP socket for the mouse mode and the connected clients.
+func QueryServerStatus(ctx context.Context, qmpSocketPath string) (*ServerStatus, error) {
+	info, err := querySPICE(ctx, qmpSocketPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerStatus{MouseMode: info.MouseMode, Clients: groupClients(info.Channels), ServerVersion: info.CompiledVersion}, nil
+}
 
-	return "", fmt.Errorf("QMP SPICE query not yet implemented, use display configuration")
+// QueryConnectedClients asks QEMU over the QMP socket which SPICE clients are connected.
+func QueryConnectedClients(ctx context.Context, qmpSocketPath string) (int, []ClientInfo, error) {
+	status, err := QueryServerStatus(ctx, qmpSocketPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(status.Clients), status.Clients, nil
+}
+
+// groupClients groups the channels reported by query-spice into clients by their connection ID.
+func groupClients(channels []spiceChannel) []ClientInfo {
+	var clients []ClientInfo
+	byID := make(map[int64]int)
+	for _, ch := range channels {
+		i, ok := byID[ch.ConnectionID]
+		if !ok {
+			i = len(clients)
+			byID[ch.ConnectionID] = i
+			clients = append(clients, ClientInfo{ConnectionID: ch.ConnectionID})
+		}
+		c := &clients[i]
+		name, ok := spiceChannelTypes[ch.ChannelType]
+		if !ok {
+			name = strconv.Itoa(ch.ChannelType)
+		}
+		c.Channels = append(c.Channels, name)
+		// Every channel has its own socket, describe the client by its main channel
+		if c.Host == "" || name == "main" {
+			c.Host, c.Port, c.Family, c.TLS = ch.Host, ch.Port, ch.Family, ch.TLS
+		}
+	}
+	return clients
+}
+
+// DiscoverFromInstance finds the SPICE server of a running QEMU instance.
+// It uses the SPICE Unix socket in the instance directory if there is one,
+// and otherwise asks QEMU for the live SPICE address over qmpSocketPath (see store.QMPSocketPath),
+// which takes precedence over the port of the configuration.
+// ErrNoPort is returned when the server has neither a port nor a socket.
+// When the server has a TLS port, the connection is verified with the certificates
+// of the SPICE TLS directory of the instance (the x509-dir of the server).
+func DiscoverFromInstance(instanceDir, qmpSocketPath string) (*Connection, error) {
+	spiceSock := filepath.Join(instanceDir, filenames.SPICESock)
+	if fi, err := os.Stat(spiceSock); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		return &Connection{UnixPath: spiceSock, Detach: true}, nil
+	}
+
+	info, err := querySPICE(context.Background(), qmpSocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the SPICE server of %q: %w", instanceDir, err)
+	}
+	// A port of 0 is disabled
+	if info.Port != nil && *info.Port == 0 {
+		info.Port = nil
+	}
+	if info.TLSPort != nil && *info.TLSPort == 0 {
+		info.TLSPort = nil
+	}
+	conn := &Connection{Detach: true}
+	switch {
+	case info.Port != nil || info.TLSPort != nil:
+		conn.Host = info.Host
+		if ip := net.ParseIP(conn.Host); conn.Host == "" || (ip != nil && ip.IsUnspecified()) {
+			conn.Host = "127.0.0.1"
+		}
+		if info.Port != nil {
+			conn.Port = strconv.Itoa(*info.Port)
+		}
+		if info.TLSPort != nil {
+			conn.TLSPort = strconv.Itoa(*info.TLSPort)
+			configureTLS(conn, instanceDir)
+		}
+	case isSocketHost(info.Host):
+		// QEMU reports the socket path as the host of a Unix socket server
+		conn.UnixPath = info.Host
+	default:
+		return nil, fmt.Errorf("SPICE server of %q has no TCP port or Unix socket: %w", instanceDir, ErrNoPort)
+	}
+	return conn, nil
 }
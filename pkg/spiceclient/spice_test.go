@@ -4,6 +4,7 @@ package spiceclient
 // SPDX-License-Identifier: Apache-2.0
 
 import (
+	"slices"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -16,6 +17,7 @@ func TestGetConnectionInfo(t *testing.T) {
 		wantHost   string
 		wantPort   string
 		wantUnix   string
+		wantTLS    bool
 		wantErr    bool
 	}{
 		{
@@ -30,6 +32,12 @@ func TestGetConnectionInfo(t *testing.T) {
 			wantHost:   "127.0.0.1",
 			wantPort:   "5930",
 		},
+		{
+			name:       "SPICE with the TCP port disabled",
+			displayStr: "spice,port=0",
+			wantHost:   "127.0.0.1",
+			wantPort:   "",
+		},
 		{
 			name:       "SPICE with custom host and port",
 			displayStr: "spice,addr=0.0.0.0,port=5931",
@@ -47,6 +55,32 @@ func TestGetConnectionInfo(t *testing.T) {
 			displayStr: "spice+unix:///tmp/spice.sock",
 			wantUnix:   "/tmp/spice.sock",
 		},
+		{
+			name:       "SPICE abstract Unix socket",
+			displayStr: "spice+unix://@lima-spice",
+			wantUnix:   "@lima-spice",
+		},
+		{
+			name:       "SPICE Unix socket path with spaces",
+			displayStr: "spice+unix:///Users/me/Application Support/spice.sock",
+			wantUnix:   "/Users/me/Application Support/spice.sock",
+		},
+		{
+			name:       "SPICE Unix socket percent-encoded path",
+			displayStr: "spice+unix:///Users/me/Application%20Support/spice.sock",
+			wantUnix:   "/Users/me/Application Support/spice.sock",
+		},
+		{
+			name:       "SPICE TLS over Unix socket",
+			displayStr: "spice+tls-unix:///tmp/spice.sock",
+			wantUnix:   "/tmp/spice.sock",
+			wantTLS:    true,
+		},
+		{
+			name:       "SPICE Unix socket without path",
+			displayStr: "spice+unix://@",
+			wantErr:    true,
+		},
 		{
 			name:       "Invalid display string",
 			displayStr: "vnc",
@@ -65,6 +99,7 @@ func TestGetConnectionInfo(t *testing.T) {
 
 			if tt.wantUnix != "" {
 				assert.Equal(t, tt.wantUnix, conn.UnixPath)
+				assert.Equal(t, tt.wantTLS, conn.UnixTLS)
 			} else {
 				assert.Equal(t, tt.wantHost, conn.Host)
 				assert.Equal(t, tt.wantPort, conn.Port)
@@ -97,6 +132,42 @@ func TestBuildSpiceURI(t *testing.T) {
 			},
 			want: "spice://192.168.1.100:5930?password=secret",
 		},
+		{
+			name: "TCP connection with a password to escape",
+			conn: &Connection{
+				Host:     "192.168.1.100",
+				Port:     "5930",
+				Password: "a&b#c%d e+f",
+			},
+			want: "spice://192.168.1.100:5930?password=a%26b%23c%25d%20e%2Bf",
+		},
+		{
+			name: "TCP connection with username and password",
+			conn: &Connection{
+				Host:     "gateway.example.com",
+				Port:     "5930",
+				Username: "alice",
+				Password: "secret",
+			},
+			want: "spice://alice@gateway.example.com:5930?password=secret",
+		},
+		{
+			name: "TCP connection with username only",
+			conn: &Connection{
+				Host:     "gateway.example.com",
+				TLSPort:  "5931",
+				Username: "alice@corp:ops",
+			},
+			want: "spice://alice%40corp%3Aops@gateway.example.com?tls-port=5931",
+		},
+		{
+			name: "Unix socket connection with username",
+			conn: &Connection{
+				UnixPath: "/var/run/spice.sock",
+				Username: "alice",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Unix socket connection",
 			conn: &Connection{
@@ -104,6 +175,28 @@ func TestBuildSpiceURI(t *testing.T) {
 			},
 			want: "spice+unix:///var/run/spice.sock",
 		},
+		{
+			name: "Abstract Unix socket connection",
+			conn: &Connection{
+				UnixPath: "@lima-spice",
+			},
+			want: "spice+unix://@lima-spice",
+		},
+		{
+			name: "Unix socket path with spaces",
+			conn: &Connection{
+				UnixPath: "/Users/me/Application Support/spice.sock",
+			},
+			want: "spice+unix:///Users/me/Application%20Support/spice.sock",
+		},
+		{
+			name: "TLS over Unix socket",
+			conn: &Connection{
+				UnixPath: "/var/run/spice.sock",
+				UnixTLS:  true,
+			},
+			want: "spice+tls-unix:///var/run/spice.sock",
+		},
 		{
 			name: "Missing host",
 			conn: &Connection{
@@ -132,3 +225,160 @@ func TestBuildSpiceURI(t *testing.T) {
 		})
 	}
 }
+
+func TestSpiceURIRoundTrip(t *testing.T) {
+	for _, uri := range []string{
+		"spice+unix:///tmp/spice.sock",
+		"spice+unix://@lima-spice",
+		"spice+unix:///tmp/with%20space/spice.sock",
+		"spice+tls-unix://@lima-spice",
+	} {
+		conn, err := GetConnectionInfo(uri)
+		assert.NilError(t, err)
+		got, err := buildSpiceURI(conn)
+		assert.NilError(t, err)
+		assert.Equal(t, got, uri)
+	}
+}
+
+func TestBuildViewerArgsChannels(t *testing.T) {
+	fakeSpicyHelp(t, "")
+	tests := []struct {
+		name    string
+		viewer  string
+		conn    *Connection
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "All channels by default",
+			viewer: "/usr/bin/remote-viewer",
+			conn:   &Connection{Host: "127.0.0.1", Port: "5900", Audio: true},
+			want:   []string{"spice://127.0.0.1:5900", "--full-screen"},
+		},
+		{
+			name:   "Display only",
+			viewer: "/usr/bin/remote-viewer",
+			conn:   &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, Channels: []string{"display", "inputs", "cursor"}},
+			want:   []string{"spice://127.0.0.1:5900", "--full-screen", "--spice-disable-audio", "--spice-disable-usbredir"},
+		},
+		{
+			name:   "Smartcard enabled",
+			viewer: "/usr/bin/remote-viewer",
+			conn:   &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, Channels: []string{"display", "inputs", "cursor", "playback", "usbredir", "smartcard"}},
+			want:   []string{"spice://127.0.0.1:5900", "--full-screen", "--spice-smartcard"},
+		},
+		{
+			name:   "spicy display only",
+			viewer: "/usr/bin/spicy",
+			conn:   &Connection{Host: "127.0.0.1", Port: "5900", Channels: []string{"display", "inputs", "cursor"}},
+			want:   []string{"-h", "127.0.0.1", "-p", "5900", "--spice-disable-audio", "--spice-disable-usbredir"},
+		},
+		{
+			name:   "spicy without audio",
+			viewer: "/usr/bin/spicy",
+			conn:   &Connection{Host: "127.0.0.1", Port: "5900"},
+			want:   []string{"-h", "127.0.0.1", "-p", "5900", "--spice-disable-audio"},
+		},
+		{
+			name:    "Unknown channel",
+			viewer:  "/usr/bin/remote-viewer",
+			conn:    &Connection{Host: "127.0.0.1", Port: "5900", Channels: []string{"display", "bogus"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildViewerArgs(tt.viewer, tt.conn)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildViewerArgsSharedDir(t *testing.T) {
+	fakeSpicyHelp(t, "")
+	dir := t.TempDir()
+
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, SharedDir: dir})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://127.0.0.1:5900", "--full-screen", "--spice-shared-dir=" + dir})
+
+	args, err = buildViewerArgs("/usr/bin/spicy", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, SharedDir: dir, SharedDirReadOnly: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"-h", "127.0.0.1", "-p", "5900", "--spice-shared-dir=" + dir, "--spice-shared-dir-ro"})
+
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", SharedDir: dir + "/missing"})
+	assert.ErrorContains(t, err, "invalid shared directory")
+}
+
+func TestBuildViewerArgsWindowSize(t *testing.T) {
+	fakeSpicyHelp(t, "")
+
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, WindowSize: "1920x1080"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://127.0.0.1:5900", "--zoom=100"})
+
+	args, err = buildViewerArgs("/usr/bin/spicy", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, WindowSize: "1920x1080"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"-h", "127.0.0.1", "-p", "5900"})
+
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", WindowSize: "1920"})
+	assert.ErrorContains(t, err, "invalid window size")
+
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", WindowSize: "1920x1080", MonitorMapping: map[int]int{1: 1}})
+	assert.ErrorContains(t, err, "only applies in full-screen mode")
+}
+
+func TestBuildViewerArgsUsername(t *testing.T) {
+	conn := &Connection{Host: "gateway.example.com", Port: "5900", Username: "alice", Password: "secret", Audio: true}
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://alice@gateway.example.com:5900?password=secret", "--full-screen"})
+
+	fakeSpicyHelp(t, "Application Options:\n  --uri=URI    SPICE server URI\n")
+	args, err = buildViewerArgs("/usr/bin/spicy", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--uri=spice://alice@gateway.example.com:5900?password=secret"})
+
+	// Older spicy has no option for the username
+	fakeSpicyHelp(t, "")
+	_, err = buildViewerArgs("/usr/bin/spicy", conn)
+	assert.ErrorContains(t, err, "does not support a username")
+}
+
+func TestParseWindowSize(t *testing.T) {
+	w, h, err := ParseWindowSize("1280x800")
+	assert.NilError(t, err)
+	assert.Equal(t, w, 1280)
+	assert.Equal(t, h, 800)
+	for _, s := range []string{"", "1280", "1280x", "x800", "0x800", "-1x800", "1280X800"} {
+		_, _, err := ParseWindowSize(s)
+		assert.ErrorContains(t, err, "invalid window size", s)
+	}
+}
+
+func TestViewerEnv(t *testing.T) {
+	t.Setenv("DISPLAY", ":1")
+	t.Setenv("WAYLAND_DISPLAY", "wayland-1")
+
+	env, err := viewerEnv(&Connection{}, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, env == nil)
+
+	env, err = viewerEnv(&Connection{HostDisplayEnv: []string{"DISPLAY=:0", "XAUTHORITY=/home/kiosk/.Xauthority"}}, []string{"XDG_CONFIG_HOME=/tmp/x"})
+	assert.NilError(t, err)
+	assert.Assert(t, slices.Contains(env, "DISPLAY=:0"))
+	assert.Assert(t, slices.Contains(env, "XAUTHORITY=/home/kiosk/.Xauthority"))
+	assert.Assert(t, slices.Contains(env, "XDG_CONFIG_HOME=/tmp/x"))
+	assert.Assert(t, !slices.Contains(env, "DISPLAY=:1"))
+	assert.Assert(t, !slices.Contains(env, "WAYLAND_DISPLAY=wayland-1"))
+
+	_, err = viewerEnv(&Connection{HostDisplayEnv: []string{"LD_PRELOAD=/tmp/x.so"}}, nil)
+	assert.ErrorContains(t, err, "invalid host display variable")
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// superviseMinBackoff and superviseMaxBackoff bound the delay before relaunching a crashed viewer
+	superviseMinBackoff = time.Second
+	superviseMaxBackoff = 30 * time.Second
+	// superviseStableRuntime is how long a viewer must run for its crash not to count as a startup failure
+	superviseStableRuntime = time.Minute
+	// superviseMaxFailures is the number of consecutive startup failures after which the setup is considered broken
+	superviseMaxFailures = 5
+)
+
+// SuperviseViewer keeps a SPICE viewer running until ctx is cancelled.
+// A viewer that crashes is relaunched with an exponential backoff.
+// A viewer that exits with status 0 was closed by the user and is not relaunched.
+// It gives up when the viewer keeps crashing shortly after being launched.
+func SuperviseViewer(ctx context.Context, conn *Connection) error {
+	// The viewer must stay attached so that its exit can be observed
+	c := *conn
+	c.Detach = false
+
+	backoff := superviseMinBackoff
+	failures := 0
+	for {
+		started := time.Now()
+		err := LaunchViewer(ctx, &c)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			logrus.Info("SPICE viewer was closed, not relaunching it")
+			return nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// The viewer could not be found or started, relaunching would not help
+			return err
+		}
+
+		if time.Since(started) >= superviseStableRuntime {
+			backoff = superviseMinBackoff
+			failures = 0
+		}
+		failures++
+		if failures >= superviseMaxFailures {
+			return fmt.Errorf("SPICE viewer crashed %d times in a row shortly after launch, giving up: %w", failures, err)
+		}
+
+		logrus.WithError(err).Warnf("SPICE viewer exited unexpectedly, relaunching in %v", backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, superviseMaxBackoff)
+	}
+}
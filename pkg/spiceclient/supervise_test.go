@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package spiceclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeViewer installs a shell script as the only SPICE viewer candidate.
+// The script records each launch in a counter file, whose path is returned.
+func fakeViewer(t *testing.T, script string) string {
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "launches")
+	viewer := filepath.Join(dir, "fake-viewer")
+	content := fmt.Sprintf("#!/bin/sh\necho x >> %q\nn=$(wc -l < %q)\n%s\n", counter, counter, script)
+	assert.NilError(t, os.WriteFile(viewer, []byte(content), 0o755))
+
+	writeGUIConfig(t, fmt.Sprintf(`
+viewers:
+  linux: &viewers
+    mode: replace
+    candidates:
+      - path: %s
+        type: spicy
+  darwin: *viewers
+  freebsd: *viewers
+`, viewer))
+	return counter
+}
+
+func launches(t *testing.T, counter string) int {
+	b, err := os.ReadFile(counter)
+	assert.NilError(t, err)
+	return strings.Count(string(b), "\n")
+}
+
+func fastSupervise(t *testing.T) {
+	origMin, origMax, origStable, origFailures := superviseMinBackoff, superviseMaxBackoff, superviseStableRuntime, superviseMaxFailures
+	t.Cleanup(func() {
+		superviseMinBackoff, superviseMaxBackoff, superviseStableRuntime, superviseMaxFailures = origMin, origMax, origStable, origFailures
+	})
+	superviseMinBackoff = time.Millisecond
+	superviseMaxBackoff = 10 * time.Millisecond
+	superviseStableRuntime = time.Minute
+	superviseMaxFailures = 3
+}
+
+var testConn = &Connection{Host: "127.0.0.1", Port: "5930"}
+
+func TestSuperviseViewerRelaunchesAfterCrash(t *testing.T) {
+	fastSupervise(t)
+	// Crash on the first launch, then get closed by the user
+	counter := fakeViewer(t, `[ "$n" -ge 2 ] && exit 0; exit 1`)
+	assert.NilError(t, SuperviseViewer(t.Context(), testConn))
+	assert.Equal(t, launches(t, counter), 2)
+}
+
+func TestSuperviseViewerGivesUp(t *testing.T) {
+	fastSupervise(t)
+	counter := fakeViewer(t, `exit 1`)
+	err := SuperviseViewer(t.Context(), testConn)
+	assert.ErrorContains(t, err, "giving up")
+	assert.Equal(t, launches(t, counter), superviseMaxFailures)
+}
+
+func TestSuperviseViewerStopsOnCancel(t *testing.T) {
+	fastSupervise(t)
+	fakeViewer(t, `exec sleep 10`)
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.NilError(t, SuperviseViewer(ctx, testConn))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+}
+
+func TestLaunchViewerStopsOnCancel(t *testing.T) {
+	fakeViewer(t, `exec sleep 10`)
+	pidFile := filepath.Join(t.TempDir(), "spice-viewer.pid")
+	conn := *testConn
+	conn.PIDFile = pidFile
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- LaunchViewer(ctx, &conn) }()
+
+	var pid int
+	for i := 0; pid == 0; i++ {
+		assert.Assert(t, i < 500, "the viewer did not start")
+		time.Sleep(10 * time.Millisecond)
+		pid = runningViewerPID(pidFile)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("LaunchViewer did not return after the context was cancelled")
+	}
+
+	// Reaped, not only killed: a zombie would still accept signal 0
+	assert.ErrorIs(t, syscall.Kill(pid, 0), syscall.ESRCH)
+	_, err := os.Stat(pidFile)
+	assert.Assert(t, os.IsNotExist(err), "the PID file was not removed")
+}
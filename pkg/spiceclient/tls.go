@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lima-vm/lima/v2/pkg/limatype/filenames"
+)
+
+// Files of a QEMU SPICE x509-dir, see the "x509-dir" option of `-spice`
+const (
+	spiceCACertFile     = "ca-cert.pem"
+	spiceServerCertFile = "server-cert.pem"
+)
+
+// configureTLS sets the CA file and the expected host subject of a TLS connection
+// from the certificates in the SPICE TLS directory of the instance, if any.
+func configureTLS(conn *Connection, instanceDir string) {
+	tlsDir := filepath.Join(instanceDir, filenames.SPICETLSDir)
+	caFile := filepath.Join(tlsDir, spiceCACertFile)
+	if _, err := os.Stat(caFile); err != nil {
+		logrus.Debugf("No SPICE CA certificate in %s, the viewer will use the system trust store", tlsDir)
+		return
+	}
+	conn.CACertFile = caFile
+	subject, err := certHostSubject(filepath.Join(tlsDir, spiceServerCertFile))
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to read the SPICE server certificate, the viewer will verify the host name instead")
+		return
+	}
+	conn.HostSubject = subject
+}
+
+// subjectAttributeNames are the short names used by spice-gtk for the host subject attributes
+var subjectAttributeNames = map[string]string{
+	"2.5.4.6":  "C",
+	"2.5.4.8":  "ST",
+	"2.5.4.7":  "L",
+	"2.5.4.10": "O",
+	"2.5.4.11": "OU",
+	"2.5.4.3":  "CN",
+}
+
+// certHostSubject formats the subject of a PEM certificate as expected by --spice-host-subject,
+// e.g., "C=US,O=Lima,CN=lima-default". The attributes are kept in the certificate order, as spice-gtk
+// compares the subjects attribute by attribute.
+func certHostSubject(certFile string) (string, error) {
+	b, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return "", fmt.Errorf("no PEM data in %s", certFile)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	var attrs []string
+	for _, attr := range cert.Subject.Names {
+		name, ok := subjectAttributeNames[attr.Type.String()]
+		if !ok {
+			return "", fmt.Errorf("unsupported subject attribute %v in %s", attr.Type, certFile)
+		}
+		value, ok := attr.Value.(string)
+		if !ok || strings.Contains(value, ",") {
+			return "", fmt.Errorf("unsupported subject value %v in %s", attr.Value, certFile)
+		}
+		attrs = append(attrs, name+"="+value)
+	}
+	if len(attrs) == 0 {
+		return "", errors.New("empty certificate subject")
+	}
+	return strings.Join(attrs, ","), nil
+}
+
+// tlsArgs returns the spice-gtk options for a TLS connection
+func tlsArgs(conn *Connection) []string {
+	if conn.TLSPort == "" {
+		return nil
+	}
+	// Never fall back to the plain text port
+	args := []string{"--spice-secure-channels=all"}
+	if conn.CACertFile != "" {
+		args = append(args, "--spice-ca-file="+conn.CACertFile)
+	}
+	if conn.HostSubject != "" {
+		args = append(args, "--spice-host-subject="+conn.HostSubject)
+	}
+	return args
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// writeTestCert writes a self-signed certificate with the given subject to path
+func writeTestCert(t *testing.T, path string, subject pkix.Name) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+}
+
+func TestCertHostSubject(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "server-cert.pem")
+	writeTestCert(t, certFile, pkix.Name{Country: []string{"US"}, Organization: []string{"Lima"}, CommonName: "lima-default"})
+	subject, err := certHostSubject(certFile)
+	assert.NilError(t, err)
+	assert.Equal(t, subject, "C=US,O=Lima,CN=lima-default")
+}
+
+func TestDiscoverFromInstanceTLS(t *testing.T) {
+	sock := startFakeQMP(t, func(fakeQMPCommand) []any {
+		return []any{map[string]any{"return": map[string]any{
+			"enabled": true, "host": "127.0.0.1", "tls-port": 5931, "compiled-version": "0.15.1",
+		}}}
+	})
+	instDir := filepath.Dir(sock)
+	tlsDir := filepath.Join(instDir, "spice-tls")
+	assert.NilError(t, os.Mkdir(tlsDir, 0o755))
+	writeTestCert(t, filepath.Join(tlsDir, "ca-cert.pem"), pkix.Name{CommonName: "Lima CA"})
+	writeTestCert(t, filepath.Join(tlsDir, "server-cert.pem"), pkix.Name{Organization: []string{"Lima"}, CommonName: "lima-default"})
+
+	conn, err := DiscoverFromInstance(instDir, sock)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conn, &Connection{
+		Host:        "127.0.0.1",
+		TLSPort:     "5931",
+		CACertFile:  filepath.Join(tlsDir, "ca-cert.pem"),
+		HostSubject: "O=Lima,CN=lima-default",
+		Detach:      true,
+	})
+
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"spice://127.0.0.1?tls-port=5931", "--full-screen", "--spice-disable-audio",
+		"--spice-secure-channels=all", "--spice-ca-file=" + conn.CACertFile, "--spice-host-subject=O=Lima,CN=lima-default",
+	})
+}
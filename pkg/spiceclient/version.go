@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// viewerVersion is a "major.minor" version, e.g., of spice-server
+type viewerVersion struct {
+	Major, Minor int
+}
+
+func (v viewerVersion) less(o viewerVersion) bool {
+	return v.Major < o.Major || (v.Major == o.Major && v.Minor < o.Minor)
+}
+
+func (v viewerVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+var (
+	viewerHelpMu sync.Mutex
+	// viewerHelp caches the help of each viewer binary, empty when it could not be read
+	viewerHelp = map[string]string{}
+
+	// viewerHelpOutput runs "VIEWER --help-all", which also lists the spice-gtk options
+	viewerHelpOutput = func(path string) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return exec.CommandContext(ctx, path, "--help-all").CombinedOutput()
+	}
+)
+
+// getViewerHelp returns the cached help of the viewer binary at path, or an empty string if unknown
+func getViewerHelp(path string) string {
+	viewerHelpMu.Lock()
+	defer viewerHelpMu.Unlock()
+	if help, ok := viewerHelp[path]; ok {
+		return help
+	}
+	out, err := viewerHelpOutput(path)
+	if err != nil && len(out) == 0 {
+		logrus.WithError(err).Debugf("Could not read the options of %s", path)
+	}
+	viewerHelp[path] = string(out)
+	return string(out)
+}
+
+// helpListsOption checks if the help of a viewer lists the long option, e.g., "--spice-shared-dir",
+// possibly followed by "=VALUE"; an option is not matched by the longer options it prefixes
+func helpListsOption(help, option string) bool {
+	for line := range strings.Lines(help) {
+		for _, field := range strings.Fields(line) {
+			name, _, _ := strings.Cut(strings.TrimSuffix(field, ","), "=")
+			if name == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spicySupportsURI checks if the spicy binary at path lists the --uri option in its help.
+// Older spicy only has the -h/-p/-s/-w options, and is assumed when the help cannot be read.
+func spicySupportsURI(path string) bool {
+	return helpListsOption(getViewerHelp(path), "--uri")
+}
+
+// filterViewerArgs drops the spice-gtk options that the installed virt-viewer does not list in its help.
+// Arguments are returned unchanged for other viewers, or when the help cannot be read.
+// A directory shared read-only is never shared writable: without --spice-shared-dir-ro, an error is returned,
+// unless --spice-shared-dir is missing too, and nothing is shared.
+func filterViewerArgs(path string, args []string) ([]string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	if !strings.Contains(name, "remote-viewer") && !strings.Contains(name, "virt-viewer") {
+		return args, nil
+	}
+	help := getViewerHelp(path)
+	if !helpListsOption(help, "--spice-debug") {
+		// Not the help of a spice-gtk client, e.g., it could not be read
+		return args, nil
+	}
+	var res []string
+	for _, arg := range args {
+		flag, _, _ := strings.Cut(arg, "=")
+		switch {
+		case !strings.HasPrefix(flag, "--spice-") || helpListsOption(help, flag):
+			res = append(res, arg)
+		case flag == "--spice-shared-dir-ro" && helpListsOption(help, "--spice-shared-dir"):
+			return nil, fmt.Errorf("%s does not support read-only shared directories (%s), refusing to share the directory writable",
+				filepath.Base(path), flag)
+		case flag == "--spice-shared-dir-ro":
+			// Nothing is shared, --spice-shared-dir was omitted
+		default:
+			logrus.Warnf("%s does not support %s, omitting it", filepath.Base(path), flag)
+		}
+	}
+	return res, nil
+}
+
+// spiceServerMinVersion is the oldest spice-server release that current viewers work well with;
+// older releases lack the monitors config and the agent file transfer that spice-gtk relies on
+var spiceServerMinVersion = viewerVersion{0, 12}
+
+// spiceServerVersionRegexp matches the "major.minor.micro" compiled-version of query-spice
+var spiceServerVersionRegexp = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// ServerVersionWarning returns a warning when the spice-server version reported by QEMU is known
+// to cause issues with SPICE viewers, or an empty string
+func ServerVersionWarning(version string) string {
+	m := spiceServerVersionRegexp.FindStringSubmatch(version)
+	if m == nil {
+		return ""
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	v := viewerVersion{Major: major, Minor: minor}
+	switch {
+	case v.Major != spiceServerMinVersion.Major:
+		// spice-server is still at 0.x, another major release may break the compatibility with spice-gtk
+		return fmt.Sprintf("QEMU is built with spice-server %s, a major version that SPICE viewers may not support yet", version)
+	case v.less(spiceServerMinVersion):
+		return fmt.Sprintf("QEMU is built with spice-server %s, older than %v; multiple monitors and file transfer may not work with current SPICE viewers",
+			version, spiceServerMinVersion)
+	}
+	return ""
+}
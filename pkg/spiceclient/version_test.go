@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func fakeViewerHelp(t *testing.T, output string) *int {
+	t.Helper()
+	origOutput, origCache := viewerHelpOutput, viewerHelp
+	t.Cleanup(func() { viewerHelpOutput, viewerHelp = origOutput, origCache })
+	viewerHelp = map[string]string{}
+	calls := new(int)
+	viewerHelpOutput = func(string) ([]byte, error) {
+		*calls++
+		return []byte(output), nil
+	}
+	return calls
+}
+
+func fakeSpicyHelp(t *testing.T, output string) {
+	t.Helper()
+	fakeViewerHelp(t, output)
+}
+
+// remoteViewerHelp is the help of remote-viewer, with the spice-gtk options given
+func remoteViewerHelp(options ...string) string {
+	help := "Usage:\n  remote-viewer [OPTION...] -- [URI]\n\nSpice connection options:\n  --spice-debug    Enable Spice-GTK debugging\n"
+	for _, option := range options {
+		help += "  " + option + "    Description\n"
+	}
+	return help
+}
+
+func TestHelpListsOption(t *testing.T) {
+	help := remoteViewerHelp("--spice-shared-dir=<dir>", "--spice-disable-audio")
+	assert.Assert(t, helpListsOption(help, "--spice-shared-dir"))
+	assert.Assert(t, !helpListsOption(help, "--spice-shared-dir-ro"))
+	assert.Assert(t, helpListsOption("  -h, --host   Remote host\n", "--host"))
+	assert.Assert(t, !helpListsOption(help, "--spice"))
+}
+
+func TestFilterViewerArgs(t *testing.T) {
+	args := []string{"--spice-disable-audio", "--spice-shared-dir=/tmp", "--spice-shared-dir-ro", "spice://127.0.0.1:5930"}
+	tests := []struct {
+		name    string
+		viewer  string
+		help    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "remote-viewer without shared directories",
+			viewer: "/usr/bin/remote-viewer",
+			help:   remoteViewerHelp("--spice-disable-audio"),
+			want:   []string{"--spice-disable-audio", "spice://127.0.0.1:5930"},
+		},
+		{
+			name:    "remote-viewer without read-only shared directories",
+			viewer:  "/usr/bin/remote-viewer",
+			help:    remoteViewerHelp("--spice-disable-audio", "--spice-shared-dir=<dir>"),
+			wantErr: "refusing to share the directory writable",
+		},
+		{
+			name:   "current remote-viewer",
+			viewer: "/usr/bin/remote-viewer",
+			help:   remoteViewerHelp("--spice-disable-audio", "--spice-shared-dir=<dir>", "--spice-shared-dir-ro"),
+			want:   args,
+		},
+		{
+			name:   "unknown help",
+			viewer: "/usr/bin/remote-viewer",
+			help:   "garbage",
+			want:   args,
+		},
+		{
+			name:   "spicy is not filtered",
+			viewer: "/usr/bin/spicy",
+			help:   remoteViewerHelp(),
+			want:   args,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeViewerHelp(t, tt.help)
+			got, err := filterViewerArgs(tt.viewer, args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, tt.want, got)
+		})
+	}
+	// A writable share does not need --spice-shared-dir-ro
+	fakeViewerHelp(t, remoteViewerHelp("--spice-shared-dir=<dir>"))
+	got, err := filterViewerArgs("/usr/bin/remote-viewer", []string{"--spice-shared-dir=/tmp", "spice://127.0.0.1:5930"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, []string{"--spice-shared-dir=/tmp", "spice://127.0.0.1:5930"})
+}
+
+func TestViewerHelpIsCached(t *testing.T) {
+	calls := fakeViewerHelp(t, remoteViewerHelp())
+	getViewerHelp("/usr/bin/remote-viewer")
+	getViewerHelp("/usr/bin/remote-viewer")
+	assert.Equal(t, 1, *calls)
+	getViewerHelp("/opt/bin/remote-viewer")
+	assert.Equal(t, 2, *calls)
+}
+
+func TestBuildViewerArgsSpicyURI(t *testing.T) {
+	fakeSpicyHelp(t, "Application Options:\n  --uri=URI    SPICE server URI\n  -h, --host   Remote host\n")
+
+	args, err := buildViewerArgs("/usr/bin/spicy", &Connection{Host: "127.0.0.1", Port: "5900", Password: "secret", Audio: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--uri=spice://127.0.0.1:5900?password=secret"})
+
+	// The URI can express a Unix socket, which the -h/-p options cannot
+	args, err = buildViewerArgs("/usr/bin/spicy", &Connection{UnixPath: "/tmp/spice.sock", Channels: []string{"display", "inputs"}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--uri=spice+unix:///tmp/spice.sock", "--spice-disable-audio", "--spice-disable-usbredir"})
+
+	// A configured viewer type has no binary to probe
+	args, err = buildViewerArgs("spicy", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"-h", "127.0.0.1", "-p", "5900"})
+}
+
+func TestBuildViewerArgsSpicyWithoutURI(t *testing.T) {
+	fakeSpicyHelp(t, "Application Options:\n  -h, --host   Remote host\n")
+
+	_, err := buildViewerArgs("/usr/bin/spicy", &Connection{UnixPath: "/tmp/spice.sock"})
+	assert.ErrorContains(t, err, "spicy does not support Unix socket connections")
+}
+
+func TestServerVersionWarning(t *testing.T) {
+	assert.Equal(t, ServerVersionWarning("0.15.1"), "")
+	assert.Equal(t, ServerVersionWarning("0.12.4"), "")
+	assert.Equal(t, ServerVersionWarning(""), "")
+	assert.Assert(t, strings.Contains(ServerVersionWarning("0.10.1"), "older than 0.12"))
+	assert.Assert(t, strings.Contains(ServerVersionWarning("1.0.0"), "major version"))
+}
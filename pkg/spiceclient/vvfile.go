@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Connection files (.vv) are read by remote-viewer and virt-viewer, see
+// https://gitlab.com/virt-viewer/virt-viewer/-/blob/master/man/remote-viewer.pod#connection-file
+
+// WriteConnectionFile writes a remote-viewer connection file (.vv) with the connection and TLS settings of conn,
+// e.g., to hand the connection off to another viewer as virt-manager does. The file holds the password,
+// so it is only readable by the user. Connection files cannot name a Unix socket.
+func WriteConnectionFile(conn *Connection, path string) error {
+	content, err := formatConnectionFile(conn, false)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	// The mode of OpenFile only applies to a new file
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// connectionFile writes a temporary connection file for LaunchViewer, replacing the SPICE URI argument.
+// remote-viewer deletes the file once read, as it holds the password; cleanup removes it if it was not read.
+func connectionFile(conn *Connection) (path string, cleanup func(), err error) {
+	content, err := formatConnectionFile(conn, true)
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := os.CreateTemp("", "lima-spice-*.vv")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() {
+		if err := os.Remove(f.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.WithError(err).Debugf("Failed to remove %s", f.Name())
+		}
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+// formatConnectionFile returns the content of a connection file for conn.
+// The inputs channel of a view-only connection is disabled, as spice-gtk has no command line option for it.
+func formatConnectionFile(conn *Connection, deleteAfterRead bool) (string, error) {
+	if conn.UnixPath != "" {
+		return "", errors.New("connection files cannot name a Unix socket, use a TCP port")
+	}
+	if conn.Host == "" || (conn.Port == "" && conn.TLSPort == "") {
+		return "", errors.New("host and port required for TCP connection")
+	}
+	var b strings.Builder
+	b.WriteString("[virt-viewer]\ntype=spice\n")
+	fmt.Fprintf(&b, "host=%s\n", escapeKeyFileValue(conn.Host))
+	if conn.Port != "" {
+		fmt.Fprintf(&b, "port=%s\n", conn.Port)
+	}
+	if conn.TLSPort != "" {
+		fmt.Fprintf(&b, "tls-port=%s\n", conn.TLSPort)
+		// Never fall back to the plain text port, as with --spice-secure-channels
+		b.WriteString("secure-channels=all;\n")
+		if conn.CACertFile != "" {
+			// The file holds the certificates themselves, with the newlines escaped
+			ca, err := os.ReadFile(conn.CACertFile)
+			if err != nil {
+				return "", fmt.Errorf("failed to read the CA certificate: %w", err)
+			}
+			fmt.Fprintf(&b, "ca=%s\n", escapeKeyFileValue(strings.TrimSpace(string(ca))))
+		}
+		if conn.HostSubject != "" {
+			fmt.Fprintf(&b, "host-subject=%s\n", escapeKeyFileValue(conn.HostSubject))
+		}
+	}
+	if conn.Username != "" {
+		fmt.Fprintf(&b, "username=%s\n", escapeKeyFileValue(conn.Username))
+	}
+	if conn.Password != "" {
+		fmt.Fprintf(&b, "password=%s\n", escapeKeyFileValue(conn.Password))
+	}
+	if conn.WindowSize == "" && conn.ZoomLevel == 0 {
+		b.WriteString("fullscreen=1\n")
+	}
+	if conn.ViewOnly {
+		b.WriteString("disable-channels=inputs;\n")
+	}
+	if deleteAfterRead {
+		b.WriteString("delete-this-file=1\n")
+	}
+	return b.String(), nil
+}
+
+// keyFileEscaper escapes the characters that end or alter a value of a GKeyFile, as g_key_file_set_string does
+var keyFileEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// escapeKeyFileValue escapes a string value of a connection file, so that a newline cannot add a key.
+// A leading space would be trimmed by the parser, it is escaped as "\s".
+func escapeKeyFileValue(s string) string {
+	s = keyFileEscaper.Replace(s)
+	if strings.HasPrefix(s, " ") {
+		s = `\s` + s[1:]
+	}
+	return s
+}
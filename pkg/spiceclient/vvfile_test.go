@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestConnectionFile(t *testing.T) {
+	conn := &Connection{Host: "127.0.0.1", Port: "5930", Password: "secret", ViewOnly: true}
+	path, cleanup, err := connectionFile(conn)
+	assert.NilError(t, err)
+	b, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `[virt-viewer]
+type=spice
+host=127.0.0.1
+port=5930
+password=secret
+fullscreen=1
+disable-channels=inputs;
+delete-this-file=1
+`)
+	cleanup()
+	_, err = os.Stat(path)
+	assert.Assert(t, os.IsNotExist(err))
+
+	_, _, err = connectionFile(&Connection{UnixPath: "/run/spice.sock", ViewOnly: true})
+	assert.ErrorContains(t, err, "Unix socket")
+}
+
+func TestWriteConnectionFile(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca-cert.pem")
+	assert.NilError(t, os.WriteFile(caFile, []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"), 0o644))
+	conn := &Connection{
+		Host: "gateway.example.com", TLSPort: "5931", Username: "alice", Password: "secret",
+		CACertFile: caFile, HostSubject: "C=US,O=Lima,CN=lima-default", WindowSize: "1920x1080",
+	}
+	path := filepath.Join(dir, "lima.vv")
+	assert.NilError(t, WriteConnectionFile(conn, path))
+	b, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `[virt-viewer]
+type=spice
+host=gateway.example.com
+tls-port=5931
+secure-channels=all;
+ca=-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----
+host-subject=C=US,O=Lima,CN=lima-default
+username=alice
+password=secret
+`)
+	fi, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Equal(t, fi.Mode().Perm(), os.FileMode(0o600))
+
+	// An existing file is made private too
+	if runtime.GOOS != "windows" {
+		assert.NilError(t, os.Chmod(path, 0o644))
+		assert.NilError(t, WriteConnectionFile(conn, path))
+		fi, err = os.Stat(path)
+		assert.NilError(t, err)
+		assert.Equal(t, fi.Mode().Perm(), os.FileMode(0o600))
+	}
+
+	conn.CACertFile = filepath.Join(dir, "missing.pem")
+	assert.ErrorContains(t, WriteConnectionFile(conn, path), "CA certificate")
+	assert.ErrorContains(t, WriteConnectionFile(&Connection{Host: "127.0.0.1"}, path), "port required")
+}
+
+func TestFormatConnectionFileEscaping(t *testing.T) {
+	conn := &Connection{Host: "127.0.0.1", Port: "5930", Username: " alice", Password: "a\\b\nhost=evil.example.com", WindowSize: "auto"}
+	content, err := formatConnectionFile(conn, false)
+	assert.NilError(t, err)
+	assert.Equal(t, content, `[virt-viewer]
+type=spice
+host=127.0.0.1
+port=5930
+username=\salice
+password=a\\b\nhost=evil.example.com
+`)
+}
+
+func TestBuildViewerArgsConnectionFile(t *testing.T) {
+	conn := &Connection{Host: "127.0.0.1", Port: "5930", ViewOnly: true}
+	args, err := buildViewerArgs("remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.Equal(t, args[0], "spice://127.0.0.1:5930")
+
+	_, err = buildViewerArgs("spicy", conn)
+	assert.ErrorContains(t, err, "spicy does not support view-only connections")
+
+	_, err = buildViewerArgs("remote-viewer", &Connection{UnixPath: "/run/spice.sock", ViewOnly: true})
+	assert.ErrorContains(t, err, "Unix socket")
+
+	// The connection file holds the full-screen and TLS settings
+	conn = &Connection{Host: "127.0.0.1", TLSPort: "5931", Password: "secret", HostSubject: "CN=lima", ConnectionFile: true}
+	args, err = buildViewerArgs("remote-viewer", conn)
+	assert.NilError(t, err)
+	assert.Assert(t, !slices.Contains(args, "--full-screen"))
+	assert.Assert(t, !slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "--spice-") && arg != "--spice-disable-audio" }), "%v", args)
+
+	_, err = buildViewerArgs("spicy", conn)
+	assert.ErrorContains(t, err, "spicy does not support connection files")
+
+	_, err = buildViewerArgs("remote-viewer", &Connection{UnixPath: "/run/spice.sock", ConnectionFile: true})
+	assert.ErrorContains(t, err, "Unix socket")
+}
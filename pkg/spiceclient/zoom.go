@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Zoom levels accepted by remote-viewer and virt-viewer, in percent
+const (
+	minZoomLevel = 10
+	maxZoomLevel = 400
+)
+
+// hostScaleFactor returns the number of host pixels per point of the main host monitor, as applied by GTK
+// to the viewer window, e.g., 2 on a Retina display; 1 if unknown
+var hostScaleFactor = func() float64 {
+	switch runtime.GOOS {
+	case "darwin":
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "system_profiler", "SPDisplaysDataType").Output()
+		if err != nil {
+			logrus.WithError(err).Debug("Failed to list the host displays")
+			return 1
+		}
+		return parseDisplaysScaleFactor(string(out))
+	case "linux", "freebsd":
+		// The viewer inherits GDK_SCALE, the integer scale factor of GTK
+		if scale, err := strconv.Atoi(os.Getenv("GDK_SCALE")); err == nil && scale > 0 {
+			return float64(scale)
+		}
+	}
+	return 1
+}
+
+var (
+	displayResolutionRE = regexp.MustCompile(`Resolution: (\d+) x (\d+)`)
+	displayLooksLikeRE  = regexp.MustCompile(`UI Looks like: (\d+) x (\d+)`)
+)
+
+// parseDisplaysScaleFactor returns the scale factor of the first display listed by `system_profiler SPDisplaysDataType`,
+// the ratio of its resolution in pixels to its resolution in points ("UI Looks like")
+func parseDisplaysScaleFactor(out string) float64 {
+	res := displayResolutionRE.FindStringSubmatch(out)
+	looks := displayLooksLikeRE.FindStringSubmatch(out)
+	if res == nil || looks == nil {
+		return 1
+	}
+	pixels, _ := strconv.Atoi(res[1])
+	points, _ := strconv.Atoi(looks[1])
+	if pixels <= 0 || points <= 0 {
+		return 1
+	}
+	return float64(pixels) / float64(points)
+}
+
+// validateZoomLevel checks that the zoom level is within the range of the viewers, 0 being the default of the viewer
+func validateZoomLevel(zoom int) error {
+	if zoom != 0 && (zoom < minZoomLevel || zoom > maxZoomLevel) {
+		return fmt.Errorf("invalid zoom level %d%%, expected %d to %d", zoom, minZoomLevel, maxZoomLevel)
+	}
+	return nil
+}
+
+// viewerZoomLevel returns the zoom level passed to the viewer for conn.ZoomLevel.
+// GTK scales the window by the scale factor of the host monitor, so the zoom level is divided by it
+// for a guest pixel to map to ZoomLevel percent of a host pixel.
+func viewerZoomLevel(zoom int, scale float64) (int, error) {
+	if scale <= 0 {
+		scale = 1
+	}
+	level := int(math.Round(float64(zoom) / scale))
+	if level < minZoomLevel || level > maxZoomLevel {
+		return 0, fmt.Errorf("zoom level %d%% needs a viewer zoom of %d%% at the host scale factor %g, out of the range of the viewer", zoom, level, scale)
+	}
+	return level, nil
+}
+
+// ZoomedWindowSize returns the size in host pixels of a viewer window showing a guest display of the given
+// resolution at the zoom level, e.g., "1920x1080" at 100%. A zoom level of 0 is 100%.
+func ZoomedWindowSize(resolution string, zoom int) (string, error) {
+	width, height, err := ParseWindowSize(resolution)
+	if err != nil {
+		return "", err
+	}
+	if zoom == 0 {
+		zoom = 100
+	}
+	if err := validateZoomLevel(zoom); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%dx%d", width*zoom/100, height*zoom/100), nil
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spiceclient
+
+import (
+	"slices"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func fakeHostScaleFactor(t *testing.T, scale float64) {
+	t.Helper()
+	orig := hostScaleFactor
+	hostScaleFactor = func() float64 { return scale }
+	t.Cleanup(func() { hostScaleFactor = orig })
+}
+
+func TestBuildViewerArgsZoomLevel(t *testing.T) {
+	fakeSpicyHelp(t, "")
+	fakeHostScaleFactor(t, 1)
+
+	args, err := buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, ZoomLevel: 100})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://127.0.0.1:5900", "--zoom=100"})
+
+	// The zoom level takes precedence over the unscaled window of a window size
+	args, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, ZoomLevel: 50, WindowSize: "1920x1080"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"spice://127.0.0.1:5900", "--zoom=50"})
+
+	// GTK doubles the window on a Retina display
+	fakeHostScaleFactor(t, 2)
+	args, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", Audio: true, ZoomLevel: 100})
+	assert.NilError(t, err)
+	assert.Assert(t, slices.Contains(args, "--zoom=50"), "%v", args)
+	assert.Assert(t, !slices.Contains(args, "--full-screen"), "%v", args)
+
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", ZoomLevel: 5})
+	assert.ErrorContains(t, err, "invalid zoom level")
+	_, err = buildViewerArgs("/usr/bin/remote-viewer", &Connection{Host: "127.0.0.1", Port: "5900", ZoomLevel: 100, MonitorMapping: map[int]int{1: 1}})
+	assert.ErrorContains(t, err, "only applies in full-screen mode")
+	_, err = buildViewerArgs("/usr/bin/spicy", &Connection{Host: "127.0.0.1", Port: "5900", ZoomLevel: 100})
+	assert.ErrorContains(t, err, "spicy does not support a zoom level")
+}
+
+func TestViewerZoomLevel(t *testing.T) {
+	level, err := viewerZoomLevel(100, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, level, 100)
+	level, err = viewerZoomLevel(150, 1.5)
+	assert.NilError(t, err)
+	assert.Equal(t, level, 100)
+	_, err = viewerZoomLevel(15, 2)
+	assert.ErrorContains(t, err, "out of the range of the viewer")
+}
+
+func TestParseDisplaysScaleFactor(t *testing.T) {
+	assert.Equal(t, parseDisplaysScaleFactor(`Graphics/Displays:
+
+    Apple M1 Pro:
+
+      Displays:
+        Color LCD:
+          Display Type: Built-in Liquid Retina XDR Display
+          Resolution: 3024 x 1964 Retina
+          Main Display: Yes
+          UI Looks like: 1512 x 982 @ 120.00Hz
+`), 2.0)
+	assert.Equal(t, parseDisplaysScaleFactor("Resolution: 1920 x 1080 (1080p FHD - Full High Definition)\n"), 1.0)
+}
+
+func TestZoomedWindowSize(t *testing.T) {
+	size, err := ZoomedWindowSize("1920x1080", 100)
+	assert.NilError(t, err)
+	assert.Equal(t, size, "1920x1080")
+	size, err = ZoomedWindowSize("1920x1080", 50)
+	assert.NilError(t, err)
+	assert.Equal(t, size, "960x540")
+	_, err = ZoomedWindowSize("1920x1080", 1000)
+	assert.ErrorContains(t, err, "invalid zoom level")
+}
@@ -4,14 +4,25 @@
 package store
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/lima-vm/lima/v2/pkg/driverutil"
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	hostagentapi "github.com/lima-vm/lima/v2/pkg/hostagent/api"
 	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/limayaml"
+	"github.com/lima-vm/lima/v2/pkg/ptr"
+	"github.com/lima-vm/lima/v2/pkg/spiceclient"
 )
 
-// populateGUIInfo populates GUI-related information in the instance
-func populateGUIInfo(inst *limatype.Instance) {
+// populateGUIInfo populates GUI-related information in the instance.
+// haInfo is the host agent info of a running instance, or nil.
+func populateGUIInfo(inst *limatype.Instance, haInfo *hostagentapi.Info) {
 	if inst.Config == nil || inst.Config.Video.Display == nil {
 		return
 	}
@@ -22,18 +33,25 @@ func populateGUIInfo(inst *limatype.Instance) {
 	}
 
 	// Determine if GUI can be run based on driver capabilities
+	canShareClipboard := true // unless the driver says otherwise
 	if inst.Status == limatype.StatusRunning || inst.Status == limatype.StatusStopped {
 		if configuredDriver, err := driverutil.CreateConfiguredDriver(inst, inst.SSHLocalPort); err == nil {
 			info := configuredDriver.Info()
 			gui.CanRunGUI = info.Features.CanRunGUI
+			canShareClipboard = info.Features.CanShareClipboard
 		}
 	}
 
-	// Get resolution if configured
-	if inst.Config.Video.VZ.Width != nil && inst.Config.Video.VZ.Height != nil {
+	// The resolution chosen by the driver at start, which may come from the environment of the host agent,
+	// e.g., $LIMA_VZ_DEFAULT_RESOLUTION; otherwise the configured one, or the default of VZ for a stopped instance
+	switch {
+	case haInfo != nil && haInfo.DisplayResolution != "":
+		gui.Resolution = haInfo.DisplayResolution
+	case inst.Config.Video.VZ.Width != nil && inst.Config.Video.VZ.Height != nil:
 		gui.Resolution = fmt.Sprintf("%dx%d", *inst.Config.Video.VZ.Width, *inst.Config.Video.VZ.Height)
-	} else if gui.Display == "vz" || gui.Display == "default" {
-		gui.Resolution = "1920x1200" // default
+	case haInfo == nil && (gui.Display == "vz" || gui.Display == "default"):
+		width, height := limayaml.VZDisplaySize(inst.Config)
+		gui.Resolution = fmt.Sprintf("%dx%d", width, height)
 	}
 
 	// Check clipboard sharing
@@ -43,11 +61,90 @@ func populateGUIInfo(inst *limatype.Instance) {
 		// Default is enabled for VZ with display
 		gui.ClipboardShared = gui.Enabled && (gui.Display == "vz" || gui.Display == "default")
 	}
+	// The configuration cannot enable clipboard sharing on a driver that lacks it
+	gui.ClipboardShared = gui.ClipboardShared && canShareClipboard
 
 	// Check audio
 	if inst.Config.Audio.Device != nil {
 		gui.AudioEnabled = *inst.Config.Audio.Device != "none"
 	}
 
+	// Report whether the driver could attach a graphics device at start time
+	if haInfo != nil {
+		gui.GraphicsDeviceActive = haInfo.GraphicsDeviceActive
+		gui.GraphicsDeviceError = haInfo.GraphicsDeviceError
+		gui.InputDevices = haInfo.InputDevices
+	}
+
 	inst.GUI = gui
 }
+
+// AddSpiceServerInfo completes inst.GUI with the status of the SPICE server of a running instance: the connected clients,
+// the mouse mode, and the server version. Asking QEMU takes a round-trip over QMP, so Inspect leaves it to the GUI commands.
+func AddSpiceServerInfo(ctx context.Context, inst *limatype.Instance) {
+	gui := inst.GUI
+	if gui == nil || inst.Status != limatype.StatusRunning || !strings.HasPrefix(gui.Display, "spice") {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	status, err := spiceServerStatus(ctx, inst)
+	if err != nil {
+		logrus.WithError(err).Debugf("failed to query the SPICE clients of instance %q", inst.Name)
+		return
+	}
+	gui.ConnectedClients = len(status.Clients)
+	gui.MouseMode = status.MouseMode
+	gui.ServerVersion = status.ServerVersion
+}
+
+// AddGuestGUIInfo completes inst.GUI with the GUI information reported by the guest agent, or does nothing if guest is nil:
+// QEMU does not know the guest resolution of a SPICE display, and VZ only requests a resolution, so whether the guest
+// picked up the display mode is checked. Asking the guest takes a round-trip through the host agent, so Inspect leaves it
+// to the GUI commands, which already have the information of the guest.
+func AddGuestGUIInfo(inst *limatype.Instance, guest *guestagentapi.GUIInfo) {
+	gui := inst.GUI
+	if gui == nil || guest == nil || inst.Status != limatype.StatusRunning {
+		return
+	}
+	switch {
+	case strings.HasPrefix(gui.Display, "spice") && gui.Resolution == "":
+		gui.Resolution = guest.GetResolution()
+		setDynamicResolution(gui, guest)
+	case (gui.Display == "vz" || gui.Display == "default") && resolutionMismatch(gui.Resolution, guest.GetResolution()):
+		gui.ResolutionMismatch = true
+		gui.RequestedResolution = gui.Resolution
+		gui.GuestResolution = guest.GetResolution()
+		setDynamicResolution(gui, guest)
+	}
+}
+
+// spiceServerStatus asks QEMU over the QMP socket of the instance for the status of its SPICE server
+func spiceServerStatus(ctx context.Context, inst *limatype.Instance) (*spiceclient.ServerStatus, error) {
+	qmpSock, err := QMPSocketPath(inst)
+	if err != nil {
+		return nil, err
+	}
+	return spiceclient.QueryServerStatus(ctx, qmpSock)
+}
+
+// setDynamicResolution reports whether the guest resizes its display to the viewer window.
+// Older guest agents do not report it, and a guest without a session cannot tell.
+func setDynamicResolution(gui *limatype.GUIInfo, guest *guestagentapi.GUIInfo) {
+	if guest.HasSchema(guestagentapi.GUISchemaDynamicResolution) && guest.GetSessionActive() {
+		gui.DynamicResolution = ptr.Of(guest.GetDynamicResolutionSupported())
+	}
+}
+
+// resolutionMismatch checks if the guest resolution differs from the requested one.
+// An unknown guest resolution is not a mismatch. xrandr may suffix the mode, e.g., "1920x1080i".
+func resolutionMismatch(requested, guest string) bool {
+	var rw, rh, gw, gh int
+	if _, err := fmt.Sscanf(requested, "%dx%d", &rw, &rh); err != nil {
+		return false
+	}
+	if _, err := fmt.Sscanf(guest, "%dx%d", &gw, &gh); err != nil {
+		return false
+	}
+	return rw != gw || rh != gh
+}
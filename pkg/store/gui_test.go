@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright The Lima Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	guestagentapi "github.com/lima-vm/lima/v2/pkg/guestagent/api"
+	hostagentapi "github.com/lima-vm/lima/v2/pkg/hostagent/api"
+	"github.com/lima-vm/lima/v2/pkg/limatype"
+	"github.com/lima-vm/lima/v2/pkg/ptr"
+)
+
+func TestPopulateGUIInfoResolution(t *testing.T) {
+	t.Setenv("LIMA_VZ_DEFAULT_RESOLUTION", "")
+	inst := &limatype.Instance{Config: &limatype.LimaYAML{Video: limatype.Video{Display: ptr.Of("vz")}}}
+	populateGUIInfo(inst, nil)
+	assert.Equal(t, inst.GUI.Resolution, "1920x1200")
+
+	populateGUIInfo(inst, &hostagentapi.Info{DisplayResolution: "2560x1600"})
+	assert.Equal(t, inst.GUI.Resolution, "2560x1600")
+
+	inst.Config.Video.VZ.Width, inst.Config.Video.VZ.Height = ptr.Of(1280), ptr.Of(800)
+	populateGUIInfo(inst, nil)
+	assert.Equal(t, inst.GUI.Resolution, "1280x800")
+
+	// QEMU does not know the resolution of the guest
+	inst.Config.Video = limatype.Video{Display: ptr.Of("spice")}
+	populateGUIInfo(inst, nil)
+	assert.Equal(t, inst.GUI.Resolution, "")
+}
+
+func TestResolutionMismatch(t *testing.T) {
+	tests := []struct {
+		requested, guest string
+		expected         bool
+	}{
+		{"1920x1200", "1920x1200", false},
+		{"1920x1200", "1024x768", true},
+		{"1920x1080", "1920x1080i", false},
+		{"1920x1200", "", false},
+		{"1920x1200", "unknown", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, resolutionMismatch(tt.requested, tt.guest), tt.expected, "requested %q, guest %q", tt.requested, tt.guest)
+	}
+}
+
+func TestSetDynamicResolution(t *testing.T) {
+	var gui limatype.GUIInfo
+	// Older guest agents do not report it
+	setDynamicResolution(&gui, &guestagentapi.GUIInfo{SchemaVersion: guestagentapi.GUISchemaModesetting, SessionActive: true})
+	assert.Assert(t, gui.DynamicResolution == nil)
+	setDynamicResolution(&gui, &guestagentapi.GUIInfo{SchemaVersion: guestagentapi.GUISchemaDynamicResolution})
+	assert.Assert(t, gui.DynamicResolution == nil)
+
+	setDynamicResolution(&gui, &guestagentapi.GUIInfo{SchemaVersion: guestagentapi.GUISchemaDynamicResolution, SessionActive: true})
+	assert.Equal(t, *gui.DynamicResolution, false)
+	setDynamicResolution(&gui, &guestagentapi.GUIInfo{
+		SchemaVersion: guestagentapi.GUISchemaDynamicResolution, SessionActive: true, DynamicResolutionSupported: true,
+	})
+	assert.Equal(t, *gui.DynamicResolution, true)
+}
+
+func TestAddGuestGUIInfo(t *testing.T) {
+	guest := &guestagentapi.GUIInfo{SchemaVersion: guestagentapi.GUISchemaDynamicResolution, SessionActive: true, Resolution: "1024x768"}
+
+	spice := &limatype.Instance{Status: limatype.StatusRunning, GUI: &limatype.GUIInfo{Display: "spice,port=5930"}}
+	AddGuestGUIInfo(spice, guest)
+	assert.Equal(t, spice.GUI.Resolution, "1024x768")
+	assert.Equal(t, *spice.GUI.DynamicResolution, false)
+
+	vz := &limatype.Instance{Status: limatype.StatusRunning, GUI: &limatype.GUIInfo{Display: "vz", Resolution: "1920x1200"}}
+	AddGuestGUIInfo(vz, guest)
+	assert.Assert(t, vz.GUI.ResolutionMismatch)
+	assert.Equal(t, vz.GUI.RequestedResolution, "1920x1200")
+	assert.Equal(t, vz.GUI.GuestResolution, "1024x768")
+
+	// Without the information of the guest, nothing is known
+	stopped := &limatype.Instance{Status: limatype.StatusStopped, GUI: &limatype.GUIInfo{Display: "vz", Resolution: "1920x1200"}}
+	AddGuestGUIInfo(stopped, guest)
+	AddGuestGUIInfo(vz, nil)
+	assert.Assert(t, !stopped.GUI.ResolutionMismatch)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/lima-vm/lima/v2/pkg/driverutil"
+	hostagentapi "github.com/lima-vm/lima/v2/pkg/hostagent/api"
 	hostagentclient "github.com/lima-vm/lima/v2/pkg/hostagent/api/client"
 	"github.com/lima-vm/lima/v2/pkg/instance/hostname"
 	"github.com/lima-vm/lima/v2/pkg/limatype"
@@ -68,6 +69,7 @@ func Inspect(ctx context.Context, instName string) (*limatype.Instance, error) {
 		inst.Errors = append(inst.Errors, err)
 	}
 
+	var haInfo *hostagentapi.Info
 	if inst.HostAgentPID != 0 {
 		haSock := filepath.Join(instDir, filenames.HostAgentSock)
 		haClient, err := hostagentclient.NewHostAgentClient(haSock)
@@ -84,6 +86,7 @@ func Inspect(ctx context.Context, instName string) (*limatype.Instance, error) {
 			} else {
 				inst.SSHLocalPort = info.SSHLocalPort
 				inst.AutoStartedIdentifier = info.AutoStartedIdentifier
+				haInfo = info
 			}
 		}
 	}
@@ -147,7 +150,7 @@ func Inspect(ctx context.Context, instName string) (*limatype.Instance, error) {
 	inst.Param = y.Param
 
 	// Populate GUI information
-	populateGUIInfo(inst)
+	populateGUIInfo(inst, haInfo)
 
 	return inst, nil
 }
@@ -229,6 +232,24 @@ func ReadPIDFile(path string) (int, error) {
 	return pid, nil
 }
 
+// NoQMPSocketError is returned by QMPSocketPath for an instance whose VM type has no QMP socket
+type NoQMPSocketError struct {
+	Instance string
+	VMType   limatype.VMType
+}
+
+func (e *NoQMPSocketError) Error() string {
+	return fmt.Sprintf("instance %q has no QMP socket, its VM type is %q, not %q", e.Instance, e.VMType, limatype.QEMU)
+}
+
+// QMPSocketPath returns the path of the QMP socket of a QEMU instance, or a *NoQMPSocketError for other VM types.
+func QMPSocketPath(inst *limatype.Instance) (string, error) {
+	if inst.VMType != limatype.QEMU {
+		return "", &NoQMPSocketError{Instance: inst.Name, VMType: inst.VMType}
+	}
+	return filepath.Join(inst.Dir, filenames.QMPSock), nil
+}
+
 type FormatData struct {
 	limatype.Instance `yaml:",inline"`
 
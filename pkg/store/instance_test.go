@@ -5,6 +5,7 @@ package store
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -159,3 +160,16 @@ func TestPrintInstanceTableTwo(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, tableTwo, buf.String())
 }
+
+func TestQMPSocketPath(t *testing.T) {
+	inst := limatype.Instance{Name: "foo", VMType: limatype.QEMU, Dir: filepath.Join("lima", "foo")}
+	qmpSock, err := QMPSocketPath(&inst)
+	assert.NilError(t, err)
+	assert.Equal(t, qmpSock, filepath.Join("lima", "foo", "qmp.sock"))
+
+	inst.VMType = limatype.VZ
+	_, err = QMPSocketPath(&inst)
+	var noQMPErr *NoQMPSocketError
+	assert.Assert(t, errors.As(err, &noQMPErr))
+	assert.Equal(t, noQMPErr.VMType, limatype.VZ)
+}